@@ -3,14 +3,18 @@ package adapter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/Dreamacro/clash/common/queue"
+	"github.com/Dreamacro/clash/common/utils"
 	"github.com/Dreamacro/clash/component/dialer"
 	C "github.com/Dreamacro/clash/constant"
+	"io"
 	"net"
 	"net/http"
 	"net/netip"
 	"net/url"
+	"sync"
 	"time"
 
 	"go.uber.org/atomic"
@@ -18,10 +22,32 @@ import (
 
 var UnifiedDelay = atomic.NewBool(false)
 
+const (
+	// defaultHistoriesNum is how many records DelayHistory and the proxy JSON show
+	defaultHistoriesNum = 10
+	// maxHistoriesNum is how many records are kept for FullDelayHistory
+	maxHistoriesNum = 100
+)
+
 type Proxy struct {
 	C.ProxyAdapter
 	history *queue.Queue[C.DelayHistory]
 	alive   *atomic.Bool
+
+	// lifetime dial results of the connections routed to this proxy, health checks excluded
+	dialSuccess *atomic.Uint64
+	dialFailed  *atomic.Uint64
+
+	// remark is a free-form note set through the API
+	remark *atomic.String
+
+	// country and city are the location given in the proxy config, used by the group filters
+	country string
+	city    string
+
+	// geoIPCountry is the GeoIP country of the server, resolved once on the first geoip: or country: filter match
+	geoIPCountry *atomic.String
+	geoIPOnce    *sync.Once
 }
 
 // Alive implements C.Proxy
@@ -39,6 +65,7 @@ func (p *Proxy) Dial(metadata *C.Metadata) (C.Conn, error) {
 // DialContext implements C.ProxyAdapter
 func (p *Proxy) DialContext(ctx context.Context, metadata *C.Metadata, opts ...dialer.Option) (C.Conn, error) {
 	conn, err := p.ProxyAdapter.DialContext(ctx, metadata, opts...)
+	p.countDial(err)
 	return conn, err
 }
 
@@ -52,11 +79,69 @@ func (p *Proxy) DialUDP(metadata *C.Metadata) (C.PacketConn, error) {
 // ListenPacketContext implements C.ProxyAdapter
 func (p *Proxy) ListenPacketContext(ctx context.Context, metadata *C.Metadata, opts ...dialer.Option) (C.PacketConn, error) {
 	pc, err := p.ProxyAdapter.ListenPacketContext(ctx, metadata, opts...)
+	p.countDial(err)
 	return pc, err
 }
 
+func (p *Proxy) countDial(err error) {
+	if err != nil {
+		p.dialFailed.Inc()
+	} else {
+		p.dialSuccess.Inc()
+	}
+}
+
+// Remark returns the note of the proxy
+func (p *Proxy) Remark() string {
+	return p.remark.Load()
+}
+
+func (p *Proxy) SetRemark(remark string) {
+	p.remark.Store(remark)
+}
+
+// Country returns the upper-case country code given in the proxy config
+func (p *Proxy) Country() string {
+	return p.country
+}
+
+// City returns the city given in the proxy config
+func (p *Proxy) City() string {
+	return p.city
+}
+
+// GeoIPCountry returns the GeoIP country cached by ResolveGeoIPCountry
+func (p *Proxy) GeoIPCountry() string {
+	return p.geoIPCountry.Load()
+}
+
+// ResolveGeoIPCountry caches lookup(server) as the GeoIP country, only the first call looks it up
+func (p *Proxy) ResolveGeoIPCountry(lookup func(server string) string) {
+	p.geoIPOnce.Do(func() {
+		host, _, err := net.SplitHostPort(p.Addr())
+		if err != nil {
+			return
+		}
+		p.geoIPCountry.Store(lookup(host))
+	})
+}
+
+// DialStatistic returns how many dials through the proxy succeeded and failed
+func (p *Proxy) DialStatistic() (success, failed uint64) {
+	return p.dialSuccess.Load(), p.dialFailed.Load()
+}
+
 // DelayHistory implements C.Proxy
 func (p *Proxy) DelayHistory() []C.DelayHistory {
+	histories := p.FullDelayHistory()
+	if len(histories) > defaultHistoriesNum {
+		histories = histories[len(histories)-defaultHistoriesNum:]
+	}
+	return histories
+}
+
+// FullDelayHistory returns every kept record, the oldest first
+func (p *Proxy) FullDelayHistory() []C.DelayHistory {
 	queueM := p.history.Copy()
 	histories := []C.DelayHistory{}
 	for _, item := range queueM {
@@ -92,24 +177,96 @@ func (p *Proxy) MarshalJSON() ([]byte, error) {
 	mapping["history"] = p.DelayHistory()
 	mapping["name"] = p.Name()
 	mapping["udp"] = p.SupportUDP()
+	mapping["dialSuccess"] = p.dialSuccess.Load()
+	mapping["dialFailed"] = p.dialFailed.Load()
+	mapping["remark"] = p.remark.Load()
+	if p.country != "" {
+		mapping["country"] = p.country
+	}
+	if p.city != "" {
+		mapping["city"] = p.city
+	}
 	return json.Marshal(mapping)
 }
 
-// URLTest get the delay for the specified URL
+// URLTest get the delay for the specified URL, a response status outside
+// expectedStatus counts as a failure. The request runs on a conn from
+// DialContext, so it goes through the transport of the proxy (ws, grpc, ...)
 // implements C.Proxy
-func (p *Proxy) URLTest(ctx context.Context, url string) (t uint16, err error) {
-	defer func() {
-		p.alive.Store(err == nil)
-		record := C.DelayHistory{Time: time.Now()}
-		if err == nil {
-			record.Delay = t
-		}
-		p.history.Put(record)
-		if p.history.Len() > 10 {
-			p.history.Pop()
+func (p *Proxy) URLTest(ctx context.Context, url string, expectedStatus utils.IntRanges[uint16]) (uint16, error) {
+	return p.urlTest(ctx, url, expectedStatus, false)
+}
+
+// TTFBTest implements C.Proxy
+func (p *Proxy) TTFBTest(ctx context.Context, url string, expectedStatus utils.IntRanges[uint16]) (uint16, error) {
+	return p.urlTest(ctx, url, expectedStatus, true)
+}
+
+// BurstTest sends count probes at once, the delay is the average of the ones
+// that succeed and loss the percentage that failed. It fails if all of them do
+// implements C.Proxy
+func (p *Proxy) BurstTest(ctx context.Context, url string, expectedStatus utils.IntRanges[uint16], count int, ttfb bool) (t uint16, loss uint8, err error) {
+	if count < 1 {
+		count = 1
+	}
+
+	type result struct {
+		delay uint16
+		err   error
+	}
+	results := make(chan result, count)
+	for i := 0; i < count; i++ {
+		go func() {
+			delay, err := p.probe(ctx, url, expectedStatus, ttfb)
+			results <- result{delay, err}
+		}()
+	}
+
+	var sum, succeeded int
+	for i := 0; i < count; i++ {
+		r := <-results
+		if r.err != nil {
+			err = r.err
+			continue
 		}
-	}()
+		sum += int(r.delay)
+		succeeded++
+	}
+
+	loss = uint8((count - succeeded) * 100 / count)
+	if succeeded > 0 {
+		t, err = uint16(sum/succeeded), nil
+	}
+	p.record(t, loss, err)
+	return
+}
+
+// LastLoss returns the loss of the latest test in percent, only a BurstTest records one
+// implements C.Proxy
+func (p *Proxy) LastLoss() uint8 {
+	return p.history.Last().Loss
+}
+
+func (p *Proxy) record(t uint16, loss uint8, err error) {
+	p.alive.Store(err == nil)
+	record := C.DelayHistory{Time: time.Now(), Loss: loss}
+	if err == nil {
+		record.Delay = t
+	}
+	p.history.Put(record)
+	if p.history.Len() > maxHistoriesNum {
+		p.history.Pop()
+	}
+}
+
+func (p *Proxy) urlTest(ctx context.Context, url string, expectedStatus utils.IntRanges[uint16], ttfb bool) (t uint16, err error) {
+	t, err = p.probe(ctx, url, expectedStatus, ttfb)
+	p.record(t, 0, err)
+	return
+}
 
+// probe measures the delay of url without recording it
+func (p *Proxy) probe(ctx context.Context, url string, expectedStatus utils.IntRanges[uint16], ttfb bool) (t uint16, err error) {
 	unifiedDelay := UnifiedDelay.Load()
 
 	addr, err := urlToMetadata(url)
@@ -118,7 +275,7 @@ func (p *Proxy) URLTest(ctx context.Context, url string) (t uint16, err error) {
 	}
 
 	start := time.Now()
-	instance, err := p.DialContext(ctx, &addr)
+	instance, err := p.ProxyAdapter.DialContext(ctx, &addr)
 	if err != nil {
 		return
 	}
@@ -126,7 +283,11 @@ func (p *Proxy) URLTest(ctx context.Context, url string) (t uint16, err error) {
 		_ = instance.Close()
 	}()
 
-	req, err := http.NewRequest(http.MethodHead, url, nil)
+	method := http.MethodHead
+	if ttfb {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequest(method, url, nil)
 	if err != nil {
 		return
 	}
@@ -153,29 +314,63 @@ func (p *Proxy) URLTest(ctx context.Context, url string) (t uint16, err error) {
 
 	defer client.CloseIdleConnections()
 
-	resp, err := client.Do(req)
+	do := func() (status int, end time.Time, err error) {
+		resp, err := client.Do(req)
+		if err != nil {
+			return
+		}
+		defer resp.Body.Close()
+
+		status, end = resp.StatusCode, time.Now()
+		if ttfb {
+			end, err = firstByte(resp.Body)
+		}
+		return
+	}
 
+	status, end, err := do()
 	if err != nil {
 		return
 	}
 
-	_ = resp.Body.Close()
+	if !expectedStatus.Check(uint16(status)) {
+		err = fmt.Errorf("unexpected status %d", status)
+		return
+	}
 
 	if unifiedDelay {
 		second := time.Now()
-		resp, err = client.Do(req)
-		if err == nil {
-			_ = resp.Body.Close()
-			start = second
+		if _, secondEnd, err := do(); err == nil {
+			start, end = second, secondEnd
 		}
 	}
 
-	t = uint16(time.Since(start) / time.Millisecond)
+	t = uint16(end.Sub(start) / time.Millisecond)
 	return
 }
 
+// firstByte waits for the first byte of body, then drains the rest so the connection can be reused
+func firstByte(body io.Reader) (time.Time, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(body, b[:]); err != nil && !errors.Is(err, io.EOF) {
+		return time.Time{}, err
+	}
+	end := time.Now()
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, 1<<20))
+	return end, nil
+}
+
 func NewProxy(adapter C.ProxyAdapter) *Proxy {
-	return &Proxy{adapter, queue.New[C.DelayHistory](10), atomic.NewBool(true)}
+	return &Proxy{
+		ProxyAdapter: adapter,
+		history:      queue.New[C.DelayHistory](maxHistoriesNum),
+		alive:        atomic.NewBool(true),
+		dialSuccess:  atomic.NewUint64(0),
+		dialFailed:   atomic.NewUint64(0),
+		remark:       atomic.NewString(""),
+		geoIPCountry: atomic.NewString(""),
+		geoIPOnce:    &sync.Once{},
+	}
 }
 
 func urlToMetadata(rawURL string) (addr C.Metadata, err error) {
@@ -1,6 +1,8 @@
 package inbound
 
 import (
+	"errors"
+
 	C "github.com/Dreamacro/clash/constant"
 	"github.com/Dreamacro/clash/transport/socks5"
 )
@@ -16,6 +18,14 @@ func (s *PacketAdapter) Metadata() *C.Metadata {
 	return s.metadata
 }
 
+// Unreachable implements C.UDPPacketUnreachable
+func (s *PacketAdapter) Unreachable() error {
+	if u, ok := s.UDPPacket.(C.UDPPacketUnreachable); ok {
+		return u.Unreachable()
+	}
+	return errors.New("unreachable is not supported by this inbound")
+}
+
 // NewPacket is PacketAdapter generator
 func NewPacket(target socks5.Addr, packet C.UDPPacket, source C.Type) *PacketAdapter {
 	metadata := parseSocksAddr(target)
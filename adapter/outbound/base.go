@@ -21,6 +21,9 @@ type Base struct {
 	rmark  int
 	id     string
 	prefer C.DNSPrefer
+	mptcp  bool
+	sndbuf int
+	rcvbuf int
 }
 
 // Name implements C.ProxyAdapter
@@ -104,6 +107,14 @@ func (b *Base) DialOptions(opts ...dialer.Option) []dialer.Option {
 		opts = append(opts, dialer.WithRoutingMark(b.rmark))
 	}
 
+	if b.mptcp {
+		opts = append(opts, dialer.WithMPTCP(true))
+	}
+
+	if b.sndbuf > 0 || b.rcvbuf > 0 {
+		opts = append(opts, dialer.WithSocketBuffer(b.sndbuf, b.rcvbuf))
+	}
+
 	switch b.prefer {
 	case C.IPv4Only:
 		opts = append(opts, dialer.WithOnlySingleStack(true))
@@ -123,16 +134,23 @@ type BasicOption struct {
 	Interface   string `proxy:"interface-name,omitempty" group:"interface-name,omitempty"`
 	RoutingMark int    `proxy:"routing-mark,omitempty" group:"routing-mark,omitempty"`
 	IPVersion   string `proxy:"ip-version,omitempty" group:"ip-version,omitempty"`
+	MPTCP       bool   `proxy:"mptcp,omitempty" group:"mptcp,omitempty"`
+	// SendBuffer and ReceiveBuffer set SO_SNDBUF and SO_RCVBUF in bytes
+	SendBuffer    int `proxy:"send-buffer-size,omitempty" group:"send-buffer-size,omitempty"`
+	ReceiveBuffer int `proxy:"receive-buffer-size,omitempty" group:"receive-buffer-size,omitempty"`
 }
 
 type BaseOption struct {
-	Name        string
-	Addr        string
-	Type        C.AdapterType
-	UDP         bool
-	Interface   string
-	RoutingMark int
-	Prefer      C.DNSPrefer
+	Name          string
+	Addr          string
+	Type          C.AdapterType
+	UDP           bool
+	Interface     string
+	RoutingMark   int
+	Prefer        C.DNSPrefer
+	MPTCP         bool
+	SendBuffer    int
+	ReceiveBuffer int
 }
 
 func NewBase(opt BaseOption) *Base {
@@ -144,6 +162,9 @@ func NewBase(opt BaseOption) *Base {
 		iface:  opt.Interface,
 		rmark:  opt.RoutingMark,
 		prefer: opt.Prefer,
+		mptcp:  opt.MPTCP,
+		sndbuf: opt.SendBuffer,
+		rcvbuf: opt.ReceiveBuffer,
 	}
 }
 
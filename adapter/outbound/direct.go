@@ -2,14 +2,31 @@ package outbound
 
 import (
 	"context"
+	"fmt"
 	"net"
 
 	"github.com/Dreamacro/clash/component/dialer"
 	C "github.com/Dreamacro/clash/constant"
+	"github.com/Dreamacro/clash/transport/fragment"
 )
 
 type Direct struct {
 	*Base
+	fragment *fragment.Config
+}
+
+type DirectOption struct {
+	BasicOption
+	Name     string         `proxy:"name"`
+	Fragment FragmentOption `proxy:"fragment,omitempty"`
+}
+
+// FragmentOption splits the first packet into segments of length bytes sent interval ms apart,
+// packets is tlshello or first, see transport/fragment
+type FragmentOption struct {
+	Packets  string `proxy:"packets,omitempty"`
+	Length   string `proxy:"length,omitempty"`
+	Interval string `proxy:"interval,omitempty"`
 }
 
 // DialContext implements C.ProxyAdapter
@@ -20,6 +37,9 @@ func (d *Direct) DialContext(ctx context.Context, metadata *C.Metadata, opts ...
 		return nil, err
 	}
 	tcpKeepAlive(c)
+	if d.fragment != nil {
+		c = fragment.NewConn(c, d.fragment)
+	}
 	return NewConn(c, d), nil
 }
 
@@ -37,6 +57,32 @@ type directPacketConn struct {
 	net.PacketConn
 }
 
+// NewDirectWithOption returns a named DIRECT, it may fragment the first packet of its TCP connections
+func NewDirectWithOption(option DirectOption) (*Direct, error) {
+	d := &Direct{
+		Base: &Base{
+			name:   option.Name,
+			tp:     C.Direct,
+			udp:    true,
+			iface:  option.Interface,
+			rmark:  option.RoutingMark,
+			prefer: C.NewDNSPrefer(option.IPVersion),
+			mptcp:  option.MPTCP,
+			sndbuf: option.SendBuffer,
+			rcvbuf: option.ReceiveBuffer,
+		},
+	}
+
+	if fo := option.Fragment; fo.Packets != "" || fo.Length != "" || fo.Interval != "" {
+		cfg, err := fragment.ParseConfig(fo.Packets, fo.Length, fo.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("direct %s %w", option.Name, err)
+		}
+		d.fragment = cfg
+	}
+	return d, nil
+}
+
 func NewDirect() *Direct {
 	return &Direct{
 		Base: &Base{
@@ -48,6 +94,19 @@ func NewDirect() *Direct {
 	}
 }
 
+// NewDirectWithInterface returns a DIRECT bound to ifaceName, used by the DIRECT@ifaceName rule target
+func NewDirectWithInterface(ifaceName string) *Direct {
+	return &Direct{
+		Base: &Base{
+			name:   "DIRECT@" + ifaceName,
+			tp:     C.Direct,
+			udp:    true,
+			iface:  ifaceName,
+			prefer: C.DualStack,
+		},
+	}
+}
+
 func NewCompatible() *Direct {
 	return &Direct{
 		Base: &Base{
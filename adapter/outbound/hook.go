@@ -0,0 +1,33 @@
+package outbound
+
+import (
+	"fmt"
+	"net/http"
+
+	C "github.com/Dreamacro/clash/constant"
+)
+
+// PreConnectCallback is called right before an outbound writes its handshake
+// request (HTTP CONNECT or websocket upgrade). The returned headers replace
+// the configured ones with the same key, so short-lived credentials such as
+// signed auth headers can be regenerated for every dial.
+var PreConnectCallback func(proxy string, metadata *C.Metadata) (http.Header, error)
+
+func applyPreConnectHeaders(proxy string, metadata *C.Metadata, header http.Header) error {
+	if PreConnectCallback == nil {
+		return nil
+	}
+
+	extra, err := PreConnectCallback(proxy, metadata)
+	if err != nil {
+		return fmt.Errorf("%s pre-connect hook error: %w", proxy, err)
+	}
+
+	for key, values := range extra {
+		header.Del(key)
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+	return nil
+}
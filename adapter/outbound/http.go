@@ -38,6 +38,8 @@ type HttpOption struct {
 	SkipCertVerify bool              `proxy:"skip-cert-verify,omitempty"`
 	Fingerprint    string            `proxy:"fingerprint,omitempty"`
 	Headers        map[string]string `proxy:"headers,omitempty"`
+	TLSMinVersion  string            `proxy:"tls-min-version,omitempty"`
+	TLSMaxVersion  string            `proxy:"tls-max-version,omitempty"`
 }
 
 // StreamConn implements C.ProxyAdapter
@@ -100,6 +102,10 @@ func (h *Http) shakeHand(metadata *C.Metadata, rw io.ReadWriter) error {
 		req.Header.Add("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(auth)))
 	}
 
+	if err := applyPreConnectHeaders(h.name, metadata, req.Header); err != nil {
+		return err
+	}
+
 	if err := req.Write(rw); err != nil {
 		return err
 	}
@@ -135,20 +141,29 @@ func NewHttp(option HttpOption) (*Http, error) {
 		if option.SNI != "" {
 			sni = option.SNI
 		}
+		// a CONNECT tunnel can't be returned for reuse, resume the TLS session to the upstream instead
 		if len(option.Fingerprint) == 0 {
 			tlsConfig = tlsC.GetGlobalFingerprintTLCConfig(&tls.Config{
 				InsecureSkipVerify: option.SkipCertVerify,
 				ServerName:         sni,
+				ClientSessionCache: getClientSessionCache(),
 			})
 		} else {
 			var err error
 			if tlsConfig, err = tlsC.GetSpecifiedFingerprintTLSConfig(&tls.Config{
 				InsecureSkipVerify: option.SkipCertVerify,
 				ServerName:         sni,
+				ClientSessionCache: getClientSessionCache(),
 			}, option.Fingerprint); err != nil {
 				return nil, err
 			}
 		}
+
+		tv, err := newTLSVersion(option.TLSMinVersion, option.TLSMaxVersion)
+		if err != nil {
+			return nil, err
+		}
+		tv.apply(tlsConfig)
 	}
 
 	return &Http{
@@ -159,6 +174,9 @@ func NewHttp(option HttpOption) (*Http, error) {
 			iface:  option.Interface,
 			rmark:  option.RoutingMark,
 			prefer: C.NewDNSPrefer(option.IPVersion),
+			mptcp:  option.MPTCP,
+			sndbuf: option.SendBuffer,
+			rcvbuf: option.ReceiveBuffer,
 		},
 		user:      option.UserName,
 		pass:      option.Password,
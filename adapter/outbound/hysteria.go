@@ -6,18 +6,21 @@ import (
 	"crypto/tls"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"net"
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/lucas-clemente/quic-go"
 	"github.com/lucas-clemente/quic-go/congestion"
 	M "github.com/sagernet/sing/common/metadata"
 
+	"github.com/Dreamacro/clash/common/utils"
 	"github.com/Dreamacro/clash/component/dialer"
 	tlsC "github.com/Dreamacro/clash/component/tls"
 	C "github.com/Dreamacro/clash/constant"
@@ -68,6 +71,16 @@ func (h *Hysteria) DialContext(ctx context.Context, metadata *C.Metadata, opts .
 	return NewConn(tcpConn, h), nil
 }
 
+// MarshalJSON implements C.ProxyAdapter
+func (h *Hysteria) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"type":     h.Type().String(),
+		"id":       h.Id(),
+		"streams":  h.client.Streams(),
+		"sessions": h.client.Sessions(),
+	})
+}
+
 func (h *Hysteria) ListenPacketContext(ctx context.Context, metadata *C.Metadata, opts ...dialer.Option) (C.PacketConn, error) {
 	hdc := hyDialerWithContext{
 		ctx: context.Background(),
@@ -89,7 +102,9 @@ type HysteriaOption struct {
 	BasicOption
 	Name                string   `proxy:"name"`
 	Server              string   `proxy:"server"`
-	Port                int      `proxy:"port"`
+	Port                int      `proxy:"port,omitempty"`
+	Ports               string   `proxy:"ports,omitempty"`
+	HopInterval         string   `proxy:"hop-interval,omitempty"`
 	Protocol            string   `proxy:"protocol,omitempty"`
 	ObfsProtocol        string   `proxy:"obfs-protocol,omitempty"` // compatible with Stash
 	Up                  string   `proxy:"up"`
@@ -111,13 +126,25 @@ type HysteriaOption struct {
 }
 
 func (c *HysteriaOption) Speed() (uint64, uint64, error) {
+	if c.UpSpeed < 0 || c.DownSpeed < 0 {
+		return 0, 0, fmt.Errorf("invaild speed: up-speed %d, down-speed %d", c.UpSpeed, c.DownSpeed)
+	}
+
 	var up, down uint64
-	up = stringToBps(c.Up)
+	if c.UpSpeed != 0 {
+		up = uint64(c.UpSpeed * mbpsToBps)
+	} else {
+		up = stringToBps(c.Up)
+	}
 	if up == 0 {
 		return 0, 0, fmt.Errorf("invaild upload speed: %s", c.Up)
 	}
 
-	down = stringToBps(c.Down)
+	if c.DownSpeed != 0 {
+		down = uint64(c.DownSpeed * mbpsToBps)
+	} else {
+		down = stringToBps(c.Down)
+	}
 	if down == 0 {
 		return 0, 0, fmt.Errorf("invaild download speed: %s", c.Down)
 	}
@@ -125,11 +152,83 @@ func (c *HysteriaOption) Speed() (uint64, uint64, error) {
 	return up, down, nil
 }
 
+// checkReceiveWindow validates the flow control windows shared by all streams of the session
+func (c *HysteriaOption) checkReceiveWindow() error {
+	if c.ReceiveWindowConn < 0 || c.ReceiveWindow < 0 {
+		return fmt.Errorf("invaild receive window: recv_window_conn %d, recv_window %d", c.ReceiveWindowConn, c.ReceiveWindow)
+	}
+
+	if c.ReceiveWindowConn != 0 && c.ReceiveWindow != 0 && c.ReceiveWindowConn > c.ReceiveWindow {
+		return fmt.Errorf("recv_window_conn %d is larger than recv_window %d", c.ReceiveWindowConn, c.ReceiveWindow)
+	}
+
+	return nil
+}
+
+// parseHopPorts parses the server ports like "443,20000-50000"
+func parseHopPorts(ports string) ([]uint16, error) {
+	ranges, err := utils.NewIntRanges[uint16](strings.ReplaceAll(ports, ",", "/"))
+	if err != nil || len(ranges) == 0 {
+		return nil, fmt.Errorf("invaild ports: %s", ports)
+	}
+
+	var result []uint16
+	for _, r := range ranges {
+		if r.Start() == 0 {
+			return nil, fmt.Errorf("invaild ports: %s", ports)
+		}
+		for port := int(r.Start()); port <= int(r.End()); port++ {
+			result = append(result, uint16(port))
+		}
+	}
+
+	return result, nil
+}
+
+// parseHopInterval parses the seconds between hops like "10", or "10-30" for a random interval in the range,
+// empty or "0" keeps the default
+func parseHopInterval(interval string) (min, max time.Duration, err error) {
+	ranges, err := utils.NewIntRanges[uint32](interval)
+	if err != nil || len(ranges) > 1 {
+		return 0, 0, fmt.Errorf("invaild hop-interval: %s", interval)
+	}
+	if len(ranges) == 0 || ranges[0].End() == 0 {
+		return 0, 0, nil
+	}
+	if ranges[0].Start() == 0 {
+		return 0, 0, fmt.Errorf("invaild hop-interval: %s", interval)
+	}
+
+	return time.Duration(ranges[0].Start()) * time.Second, time.Duration(ranges[0].End()) * time.Second, nil
+}
+
 func NewHysteria(option HysteriaOption) (*Hysteria, error) {
+	hopInterval, hopIntervalMax, err := parseHopInterval(option.HopInterval)
+	if err != nil {
+		return nil, fmt.Errorf("hysteria %s option error: %w", option.Server, err)
+	}
+
 	clientTransport := &transport.ClientTransport{
 		Dialer: &net.Dialer{
 			Timeout: 8 * time.Second,
 		},
+		HopInterval:    hopInterval,
+		HopIntervalMax: hopIntervalMax,
+	}
+
+	if option.Ports != "" {
+		ports, err := parseHopPorts(option.Ports)
+		if err != nil {
+			return nil, fmt.Errorf("hysteria %s option error: %w", option.Server, err)
+		}
+		clientTransport.HopPorts = ports
+
+		if option.Port == 0 {
+			option.Port = int(ports[0])
+		}
+	}
+	if option.Port == 0 {
+		return nil, fmt.Errorf("hysteria %s option error: port or ports is required", option.Server)
 	}
 
 	addr := net.JoinHostPort(option.Server, strconv.Itoa(option.Port))
@@ -145,7 +244,6 @@ func NewHysteria(option HysteriaOption) (*Hysteria, error) {
 	}
 
 	var bs []byte
-	var err error
 	if len(option.CustomCA) > 0 {
 		bs, err = os.ReadFile(option.CustomCA)
 		if err != nil {
@@ -183,6 +281,10 @@ func NewHysteria(option HysteriaOption) (*Hysteria, error) {
 		tlsConfig.NextProtos = []string{DefaultALPN}
 	}
 
+	if err := option.checkReceiveWindow(); err != nil {
+		return nil, fmt.Errorf("hysteria %s option error: %w", addr, err)
+	}
+
 	quicConfig := &quic.Config{
 		InitialStreamReceiveWindow:     uint64(option.ReceiveWindowConn),
 		MaxStreamReceiveWindow:         uint64(option.ReceiveWindowConn),
@@ -198,6 +300,9 @@ func NewHysteria(option HysteriaOption) (*Hysteria, error) {
 	if option.Protocol == "" {
 		option.Protocol = DefaultProtocol
 	}
+	if option.Protocol == "faketcp" && len(clientTransport.HopPorts) > 0 {
+		return nil, fmt.Errorf("hysteria %s option error: ports is not supported by faketcp", addr)
+	}
 	if option.ReceiveWindowConn == 0 {
 		quicConfig.InitialStreamReceiveWindow = DefaultStreamReceiveWindow / 10
 		quicConfig.MaxStreamReceiveWindow = DefaultStreamReceiveWindow
@@ -224,13 +329,7 @@ func NewHysteria(option HysteriaOption) (*Hysteria, error) {
 
 	up, down, err := option.Speed()
 	if err != nil {
-		return nil, err
-	}
-	if option.UpSpeed != 0 {
-		up = uint64(option.UpSpeed * mbpsToBps)
-	}
-	if option.DownSpeed != 0 {
-		down = uint64(option.DownSpeed * mbpsToBps)
+		return nil, fmt.Errorf("hysteria %s option error: %w", addr, err)
 	}
 	client, err := core.NewClient(
 		addr, option.Protocol, auth, tlsConfig, quicConfig, clientTransport, up, down, func(refBPS uint64) congestion.CongestionControl {
@@ -249,6 +348,8 @@ func NewHysteria(option HysteriaOption) (*Hysteria, error) {
 			iface:  option.Interface,
 			rmark:  option.RoutingMark,
 			prefer: C.NewDNSPrefer(option.IPVersion),
+			sndbuf: option.SendBuffer,
+			rcvbuf: option.ReceiveBuffer,
 		},
 		client: client,
 	}, nil
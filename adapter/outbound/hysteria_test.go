@@ -0,0 +1,44 @@
+package outbound
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHopPorts(t *testing.T) {
+	tests := []struct {
+		ports   string
+		want    []uint16
+		wantErr bool
+	}{
+		{ports: "443", want: []uint16{443}},
+		{ports: "443,8443", want: []uint16{443, 8443}},
+		{ports: "20000-20003", want: []uint16{20000, 20001, 20002, 20003}},
+		{ports: "443,20000-20001", want: []uint16{443, 20000, 20001}},
+		{ports: "65535", want: []uint16{65535}},
+		{ports: "0", wantErr: true},
+		{ports: "0-10", wantErr: true},
+		{ports: "65536", wantErr: true},
+		{ports: "abc", wantErr: true},
+		{ports: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ports, func(t *testing.T) {
+			ports, err := parseHopPorts(tt.ports)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, ports)
+			}
+		})
+	}
+}
+
+func TestNewHysteria_PortRequired(t *testing.T) {
+	_, err := NewHysteria(HysteriaOption{Name: "hy", Server: "example.com"})
+	assert.ErrorContains(t, err, "port or ports is required")
+}
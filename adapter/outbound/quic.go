@@ -0,0 +1,126 @@
+package outbound
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+
+	"github.com/Dreamacro/clash/component/dialer"
+	tlsC "github.com/Dreamacro/clash/component/tls"
+	C "github.com/Dreamacro/clash/constant"
+	"github.com/Dreamacro/clash/transport/rawquic"
+)
+
+type Quic struct {
+	*Base
+
+	client *rawquic.Client
+}
+
+type QuicOption struct {
+	BasicOption
+	Name           string   `proxy:"name"`
+	Server         string   `proxy:"server"`
+	Port           int      `proxy:"port"`
+	Secret         string   `proxy:"secret"`
+	UDP            bool     `proxy:"udp,omitempty"`
+	SNI            string   `proxy:"sni,omitempty"`
+	SkipCertVerify bool     `proxy:"skip-cert-verify,omitempty"`
+	Fingerprint    string   `proxy:"fingerprint,omitempty"`
+	ALPN           []string `proxy:"alpn,omitempty"`
+}
+
+// dialFunc binds the udp socket of the shared QUIC connection with opts, as in hysteria the options
+// of the call dialing the connection apply to the later calls sharing it
+func (q *Quic) dialFunc(opts ...dialer.Option) rawquic.DialFunc {
+	return func(ctx context.Context) (net.PacketConn, net.Addr, error) {
+		addr, err := resolveUDPAddrWithPrefer("udp", q.addr, q.prefer)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		pc, err := dialer.ListenPacket(ctx, "udp", "", q.Base.DialOptions(opts...)...)
+		if err != nil {
+			return nil, nil, err
+		}
+		return pc, addr, nil
+	}
+}
+
+// DialContext implements C.ProxyAdapter
+func (q *Quic) DialContext(ctx context.Context, metadata *C.Metadata, opts ...dialer.Option) (C.Conn, error) {
+	c, err := q.client.DialTCP(ctx, q.dialFunc(opts...), serializesSocksAddr(metadata))
+	if err != nil {
+		return nil, fmt.Errorf("%s connect error: %w", q.addr, err)
+	}
+	return NewConn(c, q), nil
+}
+
+// ListenPacketContext implements C.ProxyAdapter
+func (q *Quic) ListenPacketContext(ctx context.Context, metadata *C.Metadata, opts ...dialer.Option) (C.PacketConn, error) {
+	pc, err := q.client.DialUDP(ctx, q.dialFunc(opts...), serializesSocksAddr(metadata))
+	if err != nil {
+		return nil, fmt.Errorf("%s connect error: %w", q.addr, err)
+	}
+	return newPacketConn(pc, q), nil
+}
+
+func NewQuic(option QuicOption) (*Quic, error) {
+	addr := net.JoinHostPort(option.Server, strconv.Itoa(option.Port))
+	if option.Secret == "" {
+		return nil, fmt.Errorf("quic %s option error: secret is required", addr)
+	}
+
+	serverName := option.Server
+	if option.SNI != "" {
+		serverName = option.SNI
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: option.SkipCertVerify,
+		MinVersion:         tls.VersionTLS13,
+	}
+
+	if len(option.Fingerprint) != 0 {
+		var err error
+		tlsConfig, err = tlsC.GetSpecifiedFingerprintTLSConfig(tlsConfig, option.Fingerprint)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		tlsConfig = tlsC.GetGlobalFingerprintTLCConfig(tlsConfig)
+	}
+
+	if len(option.ALPN) > 0 {
+		tlsConfig.NextProtos = option.ALPN
+	} else {
+		tlsConfig.NextProtos = []string{rawquic.DefaultALPN}
+	}
+
+	quicConfig := &quic.Config{
+		HandshakeIdleTimeout: C.DefaultTLSTimeout,
+		KeepAlivePeriod:      10 * time.Second,
+	}
+
+	q := &Quic{
+		Base: &Base{
+			name:   option.Name,
+			addr:   addr,
+			tp:     C.Quic,
+			udp:    option.UDP,
+			iface:  option.Interface,
+			rmark:  option.RoutingMark,
+			prefer: C.NewDNSPrefer(option.IPVersion),
+			sndbuf: option.SendBuffer,
+			rcvbuf: option.ReceiveBuffer,
+		},
+	}
+	q.client = rawquic.NewClient(option.Secret, tlsConfig, quicConfig)
+	return q, nil
+}
@@ -1,9 +1,15 @@
 package outbound
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"net"
+	"net/http"
+	"net/url"
+	"sync"
 	"time"
 
 	"github.com/Dreamacro/clash/component/dialer"
@@ -12,15 +18,28 @@ import (
 
 type Reject struct {
 	*Base
+	icmp     bool
+	redirect string
+}
+
+type RejectOption struct {
+	Name     string `proxy:"name"`
+	Redirect string `proxy:"redirect,omitempty"`
 }
 
 // DialContext implements C.ProxyAdapter
 func (r *Reject) DialContext(ctx context.Context, metadata *C.Metadata, opts ...dialer.Option) (C.Conn, error) {
+	if r.redirect != "" {
+		return NewConn(newRedirectConn(r.redirect), r), nil
+	}
 	return NewConn(&nopConn{}, r), nil
 }
 
 // ListenPacketContext implements C.ProxyAdapter
 func (r *Reject) ListenPacketContext(ctx context.Context, metadata *C.Metadata, opts ...dialer.Option) (C.PacketConn, error) {
+	if r.icmp {
+		return newPacketConn(&unreachablePacketConn{}, r), nil
+	}
 	return newPacketConn(&nopPacketConn{}, r), nil
 }
 
@@ -35,6 +54,39 @@ func NewReject() *Reject {
 	}
 }
 
+// NewRejectICMP rejects like REJECT, but UDP senders get ICMP port unreachable if the inbound supports it
+func NewRejectICMP() *Reject {
+	return &Reject{
+		Base: &Base{
+			name:   "REJECT-ICMP",
+			tp:     C.Reject,
+			udp:    true,
+			prefer: C.DualStack,
+		},
+		icmp: true,
+	}
+}
+
+// NewRejectWithOption returns a named REJECT, plain HTTP requests get a 302 to redirect if it is set
+func NewRejectWithOption(option RejectOption) (*Reject, error) {
+	if option.Redirect != "" {
+		u, err := url.Parse(option.Redirect)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("reject %s invalid redirect: %s", option.Name, option.Redirect)
+		}
+	}
+
+	return &Reject{
+		Base: &Base{
+			name:   option.Name,
+			tp:     C.Reject,
+			udp:    true,
+			prefer: C.DualStack,
+		},
+		redirect: option.Redirect,
+	}, nil
+}
+
 func NewPass() *Reject {
 	return &Reject{
 		Base: &Base{
@@ -72,3 +124,94 @@ func (npc *nopPacketConn) LocalAddr() net.Addr                                {
 func (npc *nopPacketConn) SetDeadline(time.Time) error                        { return nil }
 func (npc *nopPacketConn) SetReadDeadline(time.Time) error                    { return nil }
 func (npc *nopPacketConn) SetWriteDeadline(time.Time) error                   { return nil }
+
+type unreachablePacketConn struct {
+	nopPacketConn
+}
+
+func (upc *unreachablePacketConn) WriteTo(b []byte, addr net.Addr) (n int, err error) {
+	return 0, C.ErrPortUnreachable
+}
+
+// maxRedirectHeader bounds the request header read before answering, larger requests are just closed
+const maxRedirectHeader = 8 * 1024
+
+// redirectConn answers the HTTP request written to it with a 302 to location,
+// anything else (e.g. a TLS ClientHello) reads EOF like nopConn
+type redirectConn struct {
+	nopConn
+	location string
+
+	mux     sync.Mutex
+	request bytes.Buffer
+	resp    bytes.Reader
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newRedirectConn(location string) *redirectConn {
+	return &redirectConn{location: location, done: make(chan struct{})}
+}
+
+func (rc *redirectConn) finish(resp []byte) {
+	rc.once.Do(func() {
+		rc.resp.Reset(resp)
+		close(rc.done)
+	})
+}
+
+func (rc *redirectConn) Write(b []byte) (int, error) {
+	rc.mux.Lock()
+	defer rc.mux.Unlock()
+
+	// the rest of an answered request is dropped
+	select {
+	case <-rc.done:
+		return len(b), nil
+	default:
+	}
+
+	rc.request.Write(b)
+	if !looksLikeHTTP(rc.request.Bytes()) {
+		rc.finish(nil)
+		return len(b), nil
+	}
+	if !bytes.Contains(rc.request.Bytes(), []byte("\r\n\r\n")) {
+		if rc.request.Len() > maxRedirectHeader {
+			rc.finish(nil)
+		}
+		return len(b), nil
+	}
+
+	if _, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(rc.request.Bytes()))); err != nil {
+		rc.finish(nil)
+		return len(b), nil
+	}
+	rc.finish([]byte(fmt.Sprintf("HTTP/1.1 302 Found\r\nLocation: %s\r\nContent-Length: 0\r\nConnection: close\r\n\r\n", rc.location)))
+	return len(b), nil
+}
+
+func (rc *redirectConn) Read(b []byte) (int, error) {
+	<-rc.done
+	rc.mux.Lock()
+	defer rc.mux.Unlock()
+	return rc.resp.Read(b)
+}
+
+func (rc *redirectConn) Close() error {
+	rc.finish(nil)
+	return nil
+}
+
+// looksLikeHTTP reports whether b may still be the start of an HTTP request line
+func looksLikeHTTP(b []byte) bool {
+	for _, c := range b {
+		if c == ' ' {
+			return true
+		}
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
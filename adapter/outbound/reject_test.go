@@ -0,0 +1,89 @@
+package outbound
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedirectConn(t *testing.T) {
+	redirect := "HTTP/1.1 302 Found\r\nLocation: https://example.com/blocked\r\nContent-Length: 0\r\nConnection: close\r\n\r\n"
+
+	tests := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{name: "request", writes: []string{"GET / HTTP/1.1\r\nHost: example.org\r\n\r\n"}, want: redirect},
+		{name: "request in pieces", writes: []string{"GE", "T /a HTTP/1.1\r\nHo", "st: example.org\r\n", "\r\n"}, want: redirect},
+		{name: "request with body", writes: []string{"POST / HTTP/1.1\r\nHost: example.org\r\nContent-Length: 4\r\n\r\nbody", "more"}, want: redirect},
+		{name: "tls client hello", writes: []string{"\x16\x03\x01\x00\xa5"}, want: ""},
+		{name: "lowercase method", writes: []string{"get / HTTP/1.1\r\n\r\n"}, want: ""},
+		{name: "malformed request", writes: []string{"GET\r\n\r\n"}, want: ""},
+		{name: "header too large", writes: []string{"GET / HTTP/1.1\r\n", "X-Pad: " + strings.Repeat("a", maxRedirectHeader) + "\r\n"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newRedirectConn("https://example.com/blocked")
+			for _, w := range tt.writes {
+				n, err := conn.Write([]byte(w))
+				assert.NoError(t, err)
+				assert.Equal(t, len(w), n)
+			}
+
+			resp, err := io.ReadAll(conn)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(resp))
+		})
+	}
+}
+
+func TestRedirectConn_CloseUnblocksRead(t *testing.T) {
+	conn := newRedirectConn("https://example.com/blocked")
+	_, _ = conn.Write([]byte("GET / HTTP/1.1\r\n"))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := conn.Read(make([]byte, 1))
+		done <- err
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("read returned before the request was complete")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	_ = conn.Close()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, io.EOF)
+	case <-time.After(time.Second):
+		t.Fatal("close didn't unblock read")
+	}
+}
+
+func TestLooksLikeHTTP(t *testing.T) {
+	tests := []struct {
+		buf  string
+		want bool
+	}{
+		{buf: "", want: true},
+		{buf: "GE", want: true},
+		{buf: "GET /", want: true},
+		{buf: "CONNECT example.org:443 HTTP/1.1", want: true},
+		{buf: "get /", want: false},
+		{buf: "\x16\x03", want: false},
+		{buf: "GET1", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.buf, func(t *testing.T) {
+			assert.Equal(t, tt.want, looksLikeHTTP([]byte(tt.buf)))
+		})
+	}
+}
@@ -186,6 +186,9 @@ func NewShadowSocks(option ShadowSocksOption) (*ShadowSocks, error) {
 			iface:  option.Interface,
 			rmark:  option.RoutingMark,
 			prefer: C.NewDNSPrefer(option.IPVersion),
+			mptcp:  option.MPTCP,
+			sndbuf: option.SendBuffer,
+			rcvbuf: option.ReceiveBuffer,
 		},
 		method: method,
 
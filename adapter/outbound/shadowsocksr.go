@@ -150,6 +150,9 @@ func NewShadowSocksR(option ShadowSocksROption) (*ShadowSocksR, error) {
 			iface:  option.Interface,
 			rmark:  option.RoutingMark,
 			prefer: C.NewDNSPrefer(option.IPVersion),
+			mptcp:  option.MPTCP,
+			sndbuf: option.SendBuffer,
+			rcvbuf: option.ReceiveBuffer,
 		},
 		cipher:   coreCiph,
 		obfs:     obfs,
@@ -159,6 +159,9 @@ func NewSnell(option SnellOption) (*Snell, error) {
 			iface:  option.Interface,
 			rmark:  option.RoutingMark,
 			prefer: C.NewDNSPrefer(option.IPVersion),
+			mptcp:  option.MPTCP,
+			sndbuf: option.SendBuffer,
+			rcvbuf: option.ReceiveBuffer,
 		},
 		psk:        psk,
 		obfsOption: obfsOption,
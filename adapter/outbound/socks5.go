@@ -19,6 +19,7 @@ type Socks5 struct {
 	*Base
 	user           string
 	pass           string
+	passUser       bool
 	tls            bool
 	skipCertVerify bool
 	tlsConfig      *tls.Config
@@ -31,10 +32,14 @@ type Socks5Option struct {
 	Port           int    `proxy:"port"`
 	UserName       string `proxy:"username,omitempty"`
 	Password       string `proxy:"password,omitempty"`
+	PassUser       bool   `proxy:"pass-user,omitempty"`
 	TLS            bool   `proxy:"tls,omitempty"`
+	SNI            string `proxy:"sni,omitempty"`
 	UDP            bool   `proxy:"udp,omitempty"`
 	SkipCertVerify bool   `proxy:"skip-cert-verify,omitempty"`
 	Fingerprint    string `proxy:"fingerprint,omitempty"`
+	TLSMinVersion  string `proxy:"tls-min-version,omitempty"`
+	TLSMaxVersion  string `proxy:"tls-max-version,omitempty"`
 }
 
 // StreamConn implements C.ProxyAdapter
@@ -48,14 +53,7 @@ func (ss *Socks5) StreamConn(c net.Conn, metadata *C.Metadata) (net.Conn, error)
 		}
 	}
 
-	var user *socks5.User
-	if ss.user != "" {
-		user = &socks5.User{
-			Username: ss.user,
-			Password: ss.pass,
-		}
-	}
-	if _, err := socks5.ClientHandshake(c, serializesSocksAddr(metadata), socks5.CmdConnect, user); err != nil {
+	if _, err := socks5.ClientHandshake(c, serializesSocksAddr(metadata), socks5.CmdConnect, ss.authUser(metadata)); err != nil {
 		return nil, err
 	}
 	return c, nil
@@ -96,15 +94,7 @@ func (ss *Socks5) ListenPacketContext(ctx context.Context, metadata *C.Metadata,
 	defer safeConnClose(c, err)
 
 	tcpKeepAlive(c)
-	var user *socks5.User
-	if ss.user != "" {
-		user = &socks5.User{
-			Username: ss.user,
-			Password: ss.pass,
-		}
-	}
-
-	bindAddr, err := socks5.ClientHandshake(c, serializesSocksAddr(metadata), socks5.CmdUDPAssociate, user)
+	bindAddr, err := socks5.ClientHandshake(c, serializesSocksAddr(metadata), socks5.CmdUDPAssociate, ss.authUser(metadata))
 	if err != nil {
 		err = fmt.Errorf("client hanshake error: %w", err)
 		return
@@ -140,12 +130,33 @@ func (ss *Socks5) ListenPacketContext(ctx context.Context, metadata *C.Metadata,
 	return newPacketConn(&socksPacketConn{PacketConn: pc, rAddr: bindUDPAddr, tcpConn: c}, ss), nil
 }
 
+// authUser returns the credential for the upstream, with pass-user the inbound username replaces the configured one
+func (ss *Socks5) authUser(metadata *C.Metadata) *socks5.User {
+	username := ss.user
+	if ss.passUser && metadata.InUser != "" {
+		username = metadata.InUser
+	}
+
+	if username == "" {
+		return nil
+	}
+
+	return &socks5.User{
+		Username: username,
+		Password: ss.pass,
+	}
+}
+
 func NewSocks5(option Socks5Option) (*Socks5, error) {
 	var tlsConfig *tls.Config
 	if option.TLS {
+		sni := option.Server
+		if option.SNI != "" {
+			sni = option.SNI
+		}
 		tlsConfig = &tls.Config{
 			InsecureSkipVerify: option.SkipCertVerify,
-			ServerName:         option.Server,
+			ServerName:         sni,
 		}
 
 		if len(option.Fingerprint) == 0 {
@@ -156,6 +167,12 @@ func NewSocks5(option Socks5Option) (*Socks5, error) {
 				return nil, err
 			}
 		}
+
+		tv, err := newTLSVersion(option.TLSMinVersion, option.TLSMaxVersion)
+		if err != nil {
+			return nil, err
+		}
+		tv.apply(tlsConfig)
 	}
 
 	return &Socks5{
@@ -167,9 +184,13 @@ func NewSocks5(option Socks5Option) (*Socks5, error) {
 			iface:  option.Interface,
 			rmark:  option.RoutingMark,
 			prefer: C.NewDNSPrefer(option.IPVersion),
+			mptcp:  option.MPTCP,
+			sndbuf: option.SendBuffer,
+			rcvbuf: option.ReceiveBuffer,
 		},
 		user:           option.UserName,
 		pass:           option.Password,
+		passUser:       option.PassUser,
 		tls:            option.TLS,
 		skipCertVerify: option.SkipCertVerify,
 		tlsConfig:      tlsConfig,
@@ -43,9 +43,11 @@ type TrojanOption struct {
 	WSOpts         WSOptions   `proxy:"ws-opts,omitempty"`
 	Flow           string      `proxy:"flow,omitempty"`
 	FlowShow       bool        `proxy:"flow-show,omitempty"`
+	TLSMinVersion  string      `proxy:"tls-min-version,omitempty"`
+	TLSMaxVersion  string      `proxy:"tls-max-version,omitempty"`
 }
 
-func (t *Trojan) plainStream(c net.Conn) (net.Conn, error) {
+func (t *Trojan) plainStream(c net.Conn, metadata *C.Metadata) (net.Conn, error) {
 	if t.option.Network == "ws" {
 		host, port, _ := net.SplitHostPort(t.addr)
 		wsOpts := &trojan.WebsocketOption{
@@ -58,11 +60,14 @@ func (t *Trojan) plainStream(c net.Conn) (net.Conn, error) {
 			wsOpts.Host = t.option.SNI
 		}
 
-		if len(t.option.WSOpts.Headers) != 0 {
-			header := http.Header{}
-			for key, value := range t.option.WSOpts.Headers {
-				header.Add(key, value)
-			}
+		header := http.Header{}
+		for key, value := range t.option.WSOpts.Headers {
+			header.Add(key, value)
+		}
+		if err := applyPreConnectHeaders(t.name, metadata, header); err != nil {
+			return nil, err
+		}
+		if len(header) != 0 {
 			wsOpts.Headers = header
 		}
 
@@ -78,7 +83,7 @@ func (t *Trojan) StreamConn(c net.Conn, metadata *C.Metadata) (net.Conn, error)
 	if t.transport != nil {
 		c, err = gun.StreamGunWithConn(c, t.gunTLSConfig, t.gunConfig)
 	} else {
-		c, err = t.plainStream(c)
+		c, err = t.plainStream(c, metadata)
 	}
 
 	if err != nil {
@@ -155,7 +160,7 @@ func (t *Trojan) ListenPacketContext(ctx context.Context, metadata *C.Metadata,
 		}
 		defer safeConnClose(c, err)
 		tcpKeepAlive(c)
-		c, err = t.plainStream(c)
+		c, err = t.plainStream(c, metadata)
 		if err != nil {
 			return nil, fmt.Errorf("%s connect error: %w", t.addr, err)
 		}
@@ -184,6 +189,11 @@ func (t *Trojan) SupportUOT() bool {
 func NewTrojan(option TrojanOption) (*Trojan, error) {
 	addr := net.JoinHostPort(option.Server, strconv.Itoa(option.Port))
 
+	tv, err := newTLSVersion(option.TLSMinVersion, option.TLSMaxVersion)
+	if err != nil {
+		return nil, err
+	}
+
 	tOption := &trojan.Option{
 		Password:       option.Password,
 		ALPN:           option.ALPN,
@@ -191,6 +201,8 @@ func NewTrojan(option TrojanOption) (*Trojan, error) {
 		SkipCertVerify: option.SkipCertVerify,
 		FlowShow:       option.FlowShow,
 		Fingerprint:    option.Fingerprint,
+		MinVersion:     tv.min,
+		MaxVersion:     tv.max,
 	}
 
 	if option.Network != "ws" && len(option.Flow) >= 16 {
@@ -216,6 +228,9 @@ func NewTrojan(option TrojanOption) (*Trojan, error) {
 			iface:  option.Interface,
 			rmark:  option.RoutingMark,
 			prefer: C.NewDNSPrefer(option.IPVersion),
+			mptcp:  option.MPTCP,
+			sndbuf: option.SendBuffer,
+			rcvbuf: option.ReceiveBuffer,
 		},
 		instance: trojan.New(tOption),
 		option:   &option,
@@ -231,12 +246,12 @@ func NewTrojan(option TrojanOption) (*Trojan, error) {
 			return c, nil
 		}
 
-		tlsConfig := &tls.Config{
+		tlsConfig := tv.apply(&tls.Config{
 			NextProtos:         option.ALPN,
 			MinVersion:         tls.VersionTLS12,
 			InsecureSkipVerify: tOption.SkipCertVerify,
 			ServerName:         tOption.ServerName,
-		}
+		})
 
 		if len(option.Fingerprint) == 0 {
 			tlsConfig = tlsC.GetGlobalFingerprintTLCConfig(tlsConfig)
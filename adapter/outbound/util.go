@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/Dreamacro/clash/component/resolver"
+	tlsC "github.com/Dreamacro/clash/component/tls"
 	C "github.com/Dreamacro/clash/constant"
 	"github.com/Dreamacro/clash/transport/socks5"
 )
@@ -19,6 +20,7 @@ var (
 	globalClientSessionCache  tls.ClientSessionCache
 	globalClientXSessionCache xtls.ClientSessionCache
 	once                      sync.Once
+	xOnce                     sync.Once
 )
 
 func tcpKeepAlive(c net.Conn) {
@@ -36,12 +38,33 @@ func getClientSessionCache() tls.ClientSessionCache {
 }
 
 func getClientXSessionCache() xtls.ClientSessionCache {
-	once.Do(func() {
+	xOnce.Do(func() {
 		globalClientXSessionCache = xtls.NewLRUClientSessionCache(128)
 	})
 	return globalClientXSessionCache
 }
 
+// tlsVersion is the tls-min-version and tls-max-version of a proxy, 0 keeps the default
+type tlsVersion struct {
+	min uint16
+	max uint16
+}
+
+func newTLSVersion(minVersion, maxVersion string) (tlsVersion, error) {
+	min, max, err := tlsC.ParseVersionRange(minVersion, maxVersion)
+	return tlsVersion{min: min, max: max}, err
+}
+
+func (tv tlsVersion) apply(cfg *tls.Config) *tls.Config {
+	if tv.min != 0 {
+		cfg.MinVersion = tv.min
+	}
+	if tv.max != 0 {
+		cfg.MaxVersion = tv.max
+	}
+	return cfg
+}
+
 func serializesSocksAddr(metadata *C.Metadata) []byte {
 	var buf [][]byte
 	aType := uint8(metadata.AddrType)
@@ -32,6 +32,8 @@ type Vless struct {
 	client *vless.Client
 	option *VlessOption
 
+	tlsVersion tlsVersion
+
 	// for gun mux
 	gunTLSConfig *tls.Config
 	gunConfig    *gun.Config
@@ -58,6 +60,11 @@ type VlessOption struct {
 	SkipCertVerify bool              `proxy:"skip-cert-verify,omitempty"`
 	Fingerprint    string            `proxy:"fingerprint,omitempty"`
 	ServerName     string            `proxy:"servername,omitempty"`
+	TLSMinVersion  string            `proxy:"tls-min-version,omitempty"`
+	TLSMaxVersion  string            `proxy:"tls-max-version,omitempty"`
+
+	// HTTPUpgradeOpts are used by the httpupgrade network
+	HTTPUpgradeOpts HTTPUpgradeOptions `proxy:"httpupgrade-opts,omitempty"`
 }
 
 func (v *Vless) StreamConn(c net.Conn, metadata *C.Metadata) (net.Conn, error) {
@@ -80,14 +87,18 @@ func (v *Vless) StreamConn(c net.Conn, metadata *C.Metadata) (net.Conn, error) {
 				wsOpts.Headers.Add(key, value)
 			}
 		}
+
+		if err = applyPreConnectHeaders(v.name, metadata, wsOpts.Headers); err != nil {
+			return nil, err
+		}
 		if v.option.TLS {
 			wsOpts.TLS = true
-			tlsConfig := &tls.Config{
+			tlsConfig := v.tlsVersion.apply(&tls.Config{
 				MinVersion:         tls.VersionTLS12,
 				ServerName:         host,
 				InsecureSkipVerify: v.option.SkipCertVerify,
 				NextProtos:         []string{"http/1.1"},
-			}
+			})
 
 			if len(v.option.Fingerprint) == 0 {
 				wsOpts.TLSConfig = tlsC.GetGlobalFingerprintTLCConfig(tlsConfig)
@@ -107,6 +118,16 @@ func (v *Vless) StreamConn(c net.Conn, metadata *C.Metadata) (net.Conn, error) {
 			}
 		}
 		c, err = vmess.StreamWebsocketConn(c, wsOpts)
+	case "httpupgrade":
+		var upgradeOpts *vmess.HTTPUpgradeConfig
+		if upgradeOpts, err = httpUpgradeConfig(v.name, v.addr, v.option.HTTPUpgradeOpts, metadata); err != nil {
+			return nil, err
+		}
+
+		if c, err = v.streamTLSOrXTLSConn(c, false); err != nil {
+			return nil, err
+		}
+		c, err = vmess.StreamHTTPUpgradeConn(c, upgradeOpts)
 	case "http":
 		// readability first, so just copy default TLS logic
 		c, err = v.streamTLSOrXTLSConn(c, false)
@@ -179,6 +200,8 @@ func (v *Vless) streamTLSOrXTLSConn(conn net.Conn, isH2 bool) (net.Conn, error)
 			Host:           host,
 			SkipCertVerify: v.option.SkipCertVerify,
 			FingerPrint:    v.option.Fingerprint,
+			MinVersion:     v.tlsVersion.min,
+			MaxVersion:     v.tlsVersion.max,
 		}
 
 		if isH2 {
@@ -416,6 +439,11 @@ func NewVless(option VlessOption) (*Vless, error) {
 		return nil, err
 	}
 
+	tv, err := newTLSVersion(option.TLSMinVersion, option.TLSMaxVersion)
+	if err != nil {
+		return nil, err
+	}
+
 	v := &Vless{
 		Base: &Base{
 			name:   option.Name,
@@ -424,9 +452,13 @@ func NewVless(option VlessOption) (*Vless, error) {
 			udp:    option.UDP,
 			iface:  option.Interface,
 			prefer: C.NewDNSPrefer(option.IPVersion),
+			mptcp:  option.MPTCP,
+			sndbuf: option.SendBuffer,
+			rcvbuf: option.ReceiveBuffer,
 		},
-		client: client,
-		option: &option,
+		client:     client,
+		option:     &option,
+		tlsVersion: tv,
 	}
 
 	switch option.Network {
@@ -448,10 +480,10 @@ func NewVless(option VlessOption) (*Vless, error) {
 			ServiceName: v.option.GrpcOpts.GrpcServiceName,
 			Host:        v.option.ServerName,
 		}
-		tlsConfig := tlsC.GetGlobalFingerprintTLCConfig(&tls.Config{
+		tlsConfig := tlsC.GetGlobalFingerprintTLCConfig(v.tlsVersion.apply(&tls.Config{
 			InsecureSkipVerify: v.option.SkipCertVerify,
 			ServerName:         v.option.ServerName,
-		})
+		}))
 
 		if v.option.ServerName == "" {
 			host, _, _ := net.SplitHostPort(v.addr)
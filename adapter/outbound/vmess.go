@@ -27,6 +27,8 @@ type Vmess struct {
 	client *vmess.Client
 	option *VmessOption
 
+	tlsVersion tlsVersion
+
 	// for gun mux
 	gunTLSConfig *tls.Config
 	gunConfig    *gun.Config
@@ -56,6 +58,11 @@ type VmessOption struct {
 	PacketEncoding      string       `proxy:"packet-encoding,omitempty"`
 	GlobalPadding       bool         `proxy:"global-padding,omitempty"`
 	AuthenticatedLength bool         `proxy:"authenticated-length,omitempty"`
+	TLSMinVersion       string       `proxy:"tls-min-version,omitempty"`
+	TLSMaxVersion       string       `proxy:"tls-max-version,omitempty"`
+
+	// HTTPUpgradeOpts are used by the httpupgrade network
+	HTTPUpgradeOpts HTTPUpgradeOptions `proxy:"httpupgrade-opts,omitempty"`
 }
 
 type HTTPOptions struct {
@@ -80,6 +87,33 @@ type WSOptions struct {
 	EarlyDataHeaderName string            `proxy:"early-data-header-name,omitempty"`
 }
 
+type HTTPUpgradeOptions struct {
+	Path    string            `proxy:"path,omitempty"`
+	Host    string            `proxy:"host,omitempty"`
+	Headers map[string]string `proxy:"headers,omitempty"`
+}
+
+// httpUpgradeConfig builds the request of the httpupgrade network, the Host
+// defaults to the server address
+func httpUpgradeConfig(proxy string, addr string, opts HTTPUpgradeOptions, metadata *C.Metadata) (*clashVMess.HTTPUpgradeConfig, error) {
+	host, _, _ := net.SplitHostPort(addr)
+	cfg := &clashVMess.HTTPUpgradeConfig{
+		Host:    host,
+		Path:    opts.Path,
+		Headers: http.Header{},
+	}
+	if opts.Host != "" {
+		cfg.Host = opts.Host
+	}
+	for key, value := range opts.Headers {
+		cfg.Headers.Add(key, value)
+	}
+	if err := applyPreConnectHeaders(proxy, metadata, cfg.Headers); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
 // StreamConn implements C.ProxyAdapter
 func (v *Vmess) StreamConn(c net.Conn, metadata *C.Metadata) (net.Conn, error) {
 	var err error
@@ -102,13 +136,17 @@ func (v *Vmess) StreamConn(c net.Conn, metadata *C.Metadata) (net.Conn, error) {
 			}
 		}
 
+		if err = applyPreConnectHeaders(v.name, metadata, wsOpts.Headers); err != nil {
+			return nil, err
+		}
+
 		if v.option.TLS {
 			wsOpts.TLS = true
-			tlsConfig := &tls.Config{
+			tlsConfig := v.tlsVersion.apply(&tls.Config{
 				ServerName:         host,
 				InsecureSkipVerify: v.option.SkipCertVerify,
 				NextProtos:         []string{"http/1.1"},
-			}
+			})
 
 			if len(v.option.Fingerprint) == 0 {
 				wsOpts.TLSConfig = tlsC.GetGlobalFingerprintTLCConfig(tlsConfig)
@@ -126,6 +164,31 @@ func (v *Vmess) StreamConn(c net.Conn, metadata *C.Metadata) (net.Conn, error) {
 			}
 		}
 		c, err = clashVMess.StreamWebsocketConn(c, wsOpts)
+	case "httpupgrade":
+		var upgradeOpts *clashVMess.HTTPUpgradeConfig
+		if upgradeOpts, err = httpUpgradeConfig(v.name, v.addr, v.option.HTTPUpgradeOpts, metadata); err != nil {
+			return nil, err
+		}
+
+		if v.option.TLS {
+			tlsOpts := &clashVMess.TLSConfig{
+				Host:           upgradeOpts.Host,
+				SkipCertVerify: v.option.SkipCertVerify,
+				FingerPrint:    v.option.Fingerprint,
+				NextProtos:     []string{"http/1.1"},
+				MinVersion:     v.tlsVersion.min,
+				MaxVersion:     v.tlsVersion.max,
+			}
+
+			if v.option.ServerName != "" {
+				tlsOpts.Host = v.option.ServerName
+			}
+
+			if c, err = clashVMess.StreamTLSConn(c, tlsOpts); err != nil {
+				return nil, err
+			}
+		}
+		c, err = clashVMess.StreamHTTPUpgradeConn(c, upgradeOpts)
 	case "http":
 		// readability first, so just copy default TLS logic
 		if v.option.TLS {
@@ -133,6 +196,8 @@ func (v *Vmess) StreamConn(c net.Conn, metadata *C.Metadata) (net.Conn, error) {
 			tlsOpts := &clashVMess.TLSConfig{
 				Host:           host,
 				SkipCertVerify: v.option.SkipCertVerify,
+				MinVersion:     v.tlsVersion.min,
+				MaxVersion:     v.tlsVersion.max,
 			}
 
 			if v.option.ServerName != "" {
@@ -160,6 +225,8 @@ func (v *Vmess) StreamConn(c net.Conn, metadata *C.Metadata) (net.Conn, error) {
 			Host:           host,
 			SkipCertVerify: v.option.SkipCertVerify,
 			NextProtos:     []string{"h2"},
+			MinVersion:     v.tlsVersion.min,
+			MaxVersion:     v.tlsVersion.max,
 		}
 
 		if v.option.ServerName != "" {
@@ -186,6 +253,8 @@ func (v *Vmess) StreamConn(c net.Conn, metadata *C.Metadata) (net.Conn, error) {
 			tlsOpts := &clashVMess.TLSConfig{
 				Host:           host,
 				SkipCertVerify: v.option.SkipCertVerify,
+				MinVersion:     v.tlsVersion.min,
+				MaxVersion:     v.tlsVersion.max,
 			}
 
 			if v.option.ServerName != "" {
@@ -340,6 +409,11 @@ func NewVmess(option VmessOption) (*Vmess, error) {
 		}
 	}
 
+	tv, err := newTLSVersion(option.TLSMinVersion, option.TLSMaxVersion)
+	if err != nil {
+		return nil, err
+	}
+
 	v := &Vmess{
 		Base: &Base{
 			name:   option.Name,
@@ -349,9 +423,13 @@ func NewVmess(option VmessOption) (*Vmess, error) {
 			iface:  option.Interface,
 			rmark:  option.RoutingMark,
 			prefer: C.NewDNSPrefer(option.IPVersion),
+			mptcp:  option.MPTCP,
+			sndbuf: option.SendBuffer,
+			rcvbuf: option.ReceiveBuffer,
 		},
-		client: client,
-		option: &option,
+		client:     client,
+		option:     &option,
+		tlsVersion: tv,
 	}
 
 	switch option.Network {
@@ -373,10 +451,10 @@ func NewVmess(option VmessOption) (*Vmess, error) {
 			ServiceName: v.option.GrpcOpts.GrpcServiceName,
 			Host:        v.option.ServerName,
 		}
-		tlsConfig := &tls.Config{
+		tlsConfig := v.tlsVersion.apply(&tls.Config{
 			InsecureSkipVerify: v.option.SkipCertVerify,
 			ServerName:         v.option.ServerName,
-		}
+		})
 
 		if v.option.ServerName == "" {
 			host, _, _ := net.SplitHostPort(v.addr)
@@ -16,6 +16,7 @@ type Fallback struct {
 	disableUDP bool
 	testUrl    string
 	selected   string
+	fallbackTo C.Proxy
 }
 
 func (f *Fallback) Now() string {
@@ -60,14 +61,12 @@ func (f *Fallback) SupportUDP() bool {
 
 // MarshalJSON implements C.ProxyAdapter
 func (f *Fallback) MarshalJSON() ([]byte, error) {
-	all := []string{}
-	for _, proxy := range f.GetProxies(false) {
-		all = append(all, proxy.Name())
-	}
+	all, hidden := f.memberNames()
 	return json.Marshal(map[string]any{
-		"type": f.Type().String(),
-		"now":  f.Now(),
-		"all":  all,
+		"type":   f.Type().String(),
+		"now":    f.Now(),
+		"all":    all,
+		"hidden": hidden,
 	})
 }
 
@@ -95,6 +94,9 @@ func (f *Fallback) findAliveProxy(touch bool) C.Proxy {
 		}
 	}
 
+	if f.fallbackTo != nil {
+		return f.fallbackTo
+	}
 	return proxies[0]
 }
 
@@ -115,7 +117,11 @@ func (f *Fallback) Set(name string) error {
 	if !p.Alive() {
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(5000))
 		defer cancel()
-		_, _ = p.URLTest(ctx, f.testUrl)
+		url, expectedStatus, err := f.HealthCheckOption()
+		if err != nil {
+			url, expectedStatus = f.testUrl, f.expectedStatus
+		}
+		_, _ = p.URLTest(ctx, url, expectedStatus)
 	}
 
 	return nil
@@ -125,13 +131,18 @@ func NewFallback(option *GroupCommonOption, providers []provider.ProxyProvider)
 	return &Fallback{
 		GroupBase: NewGroupBase(GroupBaseOption{
 			outbound.BaseOption{
-				Name:        option.Name,
-				Type:        C.Fallback,
-				Interface:   option.Interface,
-				RoutingMark: option.RoutingMark,
+				Name:          option.Name,
+				Type:          C.Fallback,
+				Interface:     option.Interface,
+				RoutingMark:   option.RoutingMark,
+				MPTCP:         option.MPTCP,
+				SendBuffer:    option.SendBuffer,
+				ReceiveBuffer: option.ReceiveBuffer,
 			},
 			option.Filter,
 			providers,
+			option.Hidden,
+			option.ExpectedStatus,
 		}),
 		disableUDP: option.DisableUDP,
 		testUrl:    option.URL,
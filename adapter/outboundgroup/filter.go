@@ -0,0 +1,74 @@
+package outboundgroup
+
+import (
+	"strings"
+
+	"github.com/Dreamacro/clash/adapter/provider"
+	C "github.com/Dreamacro/clash/constant"
+	"github.com/Dreamacro/clash/log"
+
+	"github.com/dlclark/regexp2"
+)
+
+// proxyFilter matches a proxy by name regex, by the GeoIP country of its server with
+// the "geoip:" prefix, e.g. "geoip:JP", or by the location given in the proxy config
+// with "country:" and "city:", "country:" falls back to GeoIP if the proxy has none.
+// The GeoIP country of a proxy is looked up on its first match and cached on the proxy
+type proxyFilter struct {
+	reg     *regexp2.Regexp
+	country string
+	geoIP   bool
+	city    string
+}
+
+// located is a proxy carrying the country and city from its config
+type located interface {
+	Country() string
+	City() string
+}
+
+// geoLocated is a proxy caching the GeoIP country of its server
+type geoLocated interface {
+	ResolveGeoIPCountry(lookup func(server string) string)
+	GeoIPCountry() string
+}
+
+func parseProxyFilter(filter string) proxyFilter {
+	if prefix, value, ok := strings.Cut(filter, ":"); ok && value != "" {
+		switch strings.ToLower(prefix) {
+		case "geoip":
+			if C.GeodataMode {
+				log.Warnln("[Group] filter %s needs the MaxMind database, it matches nothing in geodata mode", filter)
+			}
+			return proxyFilter{country: strings.ToUpper(value), geoIP: true}
+		case "country":
+			return proxyFilter{country: strings.ToUpper(value)}
+		case "city":
+			return proxyFilter{city: value}
+		}
+	}
+
+	return proxyFilter{reg: regexp2.MustCompile(filter, 0)}
+}
+
+func (f proxyFilter) Match(proxy C.Proxy) bool {
+	if f.reg != nil {
+		mat, _ := f.reg.FindStringMatch(proxy.Name())
+		return mat != nil
+	}
+
+	l, _ := proxy.(located)
+	if f.city != "" {
+		return l != nil && strings.EqualFold(l.City(), f.city)
+	}
+	if !f.geoIP && l != nil && l.Country() != "" {
+		return l.Country() == f.country
+	}
+
+	g, ok := proxy.(geoLocated)
+	if !ok || C.GeodataMode {
+		return false
+	}
+	g.ResolveGeoIPCountry(provider.ServerCountry)
+	return g.GeoIPCountry() != "" && g.GeoIPCountry() == f.country
+}
@@ -2,23 +2,27 @@ package outboundgroup
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/Dreamacro/clash/adapter/outbound"
+	"github.com/Dreamacro/clash/common/utils"
 	C "github.com/Dreamacro/clash/constant"
 	"github.com/Dreamacro/clash/constant/provider"
 	types "github.com/Dreamacro/clash/constant/provider"
 	"github.com/Dreamacro/clash/log"
 	"github.com/Dreamacro/clash/tunnel"
-	"github.com/dlclark/regexp2"
 	"go.uber.org/atomic"
 	"strings"
 	"sync"
 	"time"
 )
 
+// FailClosed makes a group without any proxy resolve to REJECT instead of COMPATIBLE
+var FailClosed = atomic.NewBool(false)
+
 type GroupBase struct {
 	*outbound.Base
-	filterRegs    []*regexp2.Regexp
+	filters       []proxyFilter
 	providers     []provider.ProxyProvider
 	failedTestMux sync.Mutex
 	failedTimes   int
@@ -26,29 +30,40 @@ type GroupBase struct {
 	failedTesting *atomic.Bool
 	proxies       [][]C.Proxy
 	versions      []atomic.Uint32
+	hidden        map[string]struct{}
+	// expectedStatus is the group's expected-status, used when no inline provider holds the test options
+	expectedStatus utils.IntRanges[uint16]
 }
 
 type GroupBaseOption struct {
 	outbound.BaseOption
-	filter    string
-	providers []provider.ProxyProvider
+	filter         string
+	providers      []provider.ProxyProvider
+	hidden         []string
+	expectedStatus string
 }
 
 func NewGroupBase(opt GroupBaseOption) *GroupBase {
-	var filterRegs []*regexp2.Regexp
+	var filters []proxyFilter
 	if opt.filter != "" {
 		for _, filter := range strings.Split(opt.filter, "`") {
-			filterReg := regexp2.MustCompile(filter, 0)
-			filterRegs = append(filterRegs, filterReg)
+			filters = append(filters, parseProxyFilter(filter))
 		}
 	}
 
 	gb := &GroupBase{
 		Base:          outbound.NewBase(opt.BaseOption),
-		filterRegs:    filterRegs,
+		filters:       filters,
 		providers:     opt.providers,
 		failedTesting: atomic.NewBool(false),
 	}
+	// ParseProxyGroup already rejected a malformed expected-status
+	gb.expectedStatus, _ = utils.NewIntRanges[uint16](opt.expectedStatus)
+
+	gb.hidden = make(map[string]struct{}, len(opt.hidden))
+	for _, name := range opt.hidden {
+		gb.hidden[name] = struct{}{}
+	}
 
 	gb.proxies = make([][]C.Proxy, len(opt.providers))
 	gb.versions = make([]atomic.Uint32, len(opt.providers))
@@ -56,6 +71,39 @@ func NewGroupBase(opt GroupBaseOption) *GroupBase {
 	return gb
 }
 
+func emptyGroupProxy() C.Proxy {
+	if FailClosed.Load() {
+		return tunnel.Proxies()["REJECT"]
+	}
+	return tunnel.Proxies()["COMPATIBLE"]
+}
+
+// memberNames splits the names of the members into the shown and the hidden ones for the API
+func (gb *GroupBase) memberNames() (all []string, hidden []string) {
+	all, hidden = []string{}, []string{}
+	for _, proxy := range gb.GetProxies(false) {
+		if _, ok := gb.hidden[proxy.Name()]; ok {
+			hidden = append(hidden, proxy.Name())
+		} else {
+			all = append(all, proxy.Name())
+		}
+	}
+	return
+}
+
+// memberDelays maps every member to its last delay, 0 when it failed or was never tested
+func (gb *GroupBase) memberDelays() map[string]uint16 {
+	delays := map[string]uint16{}
+	for _, proxy := range gb.GetProxies(false) {
+		delay := proxy.LastDelay()
+		if delay == 0xffff {
+			delay = 0
+		}
+		delays[proxy.Name()] = delay
+	}
+	return delays
+}
+
 func (gb *GroupBase) Touch() {
 	for _, pd := range gb.providers {
 		pd.Touch()
@@ -63,7 +111,7 @@ func (gb *GroupBase) Touch() {
 }
 
 func (gb *GroupBase) GetProxies(touch bool) []C.Proxy {
-	if len(gb.filterRegs) == 0 {
+	if len(gb.filters) == 0 {
 		var proxies []C.Proxy
 		for _, pd := range gb.providers {
 			if touch {
@@ -72,7 +120,7 @@ func (gb *GroupBase) GetProxies(touch bool) []C.Proxy {
 			proxies = append(proxies, pd.Proxies()...)
 		}
 		if len(proxies) == 0 {
-			return append(proxies, tunnel.Proxies()["COMPATIBLE"])
+			return append(proxies, emptyGroupProxy())
 		}
 		return proxies
 	}
@@ -97,10 +145,10 @@ func (gb *GroupBase) GetProxies(touch bool) []C.Proxy {
 
 			proxies = pd.Proxies()
 			proxiesSet := map[string]struct{}{}
-			for _, filterReg := range gb.filterRegs {
+			for _, filter := range gb.filters {
 				for _, p := range proxies {
 					name := p.Name()
-					if mat, _ := filterReg.FindStringMatch(name); mat != nil {
+					if filter.Match(p) {
 						if _, ok := proxiesSet[name]; !ok {
 							proxiesSet[name] = struct{}{}
 							newProxies = append(newProxies, p)
@@ -119,16 +167,16 @@ func (gb *GroupBase) GetProxies(touch bool) []C.Proxy {
 	}
 
 	if len(proxies) == 0 {
-		return append(proxies, tunnel.Proxies()["COMPATIBLE"])
+		return append(proxies, emptyGroupProxy())
 	}
 
-	if len(gb.providers) > 1 && len(gb.filterRegs) > 1 {
+	if len(gb.providers) > 1 && len(gb.filters) > 1 {
 		var newProxies []C.Proxy
 		proxiesSet := map[string]struct{}{}
-		for _, filterReg := range gb.filterRegs {
+		for _, filter := range gb.filters {
 			for _, p := range proxies {
 				name := p.Name()
-				if mat, _ := filterReg.FindStringMatch(name); mat != nil {
+				if filter.Match(p) {
 					if _, ok := proxiesSet[name]; !ok {
 						proxiesSet[name] = struct{}{}
 						newProxies = append(newProxies, p)
@@ -149,32 +197,98 @@ func (gb *GroupBase) GetProxies(touch bool) []C.Proxy {
 	return proxies
 }
 
+// inlineProvider is the provider holding the proxies listed inline, it owns the group's test options
+func (gb *GroupBase) inlineProvider() (healthCheckOptioner, bool) {
+	for _, pd := range gb.providers {
+		if pd.Name() != gb.Name() {
+			continue
+		}
+		if hp, ok := pd.(healthCheckOptioner); ok {
+			return hp, true
+		}
+	}
+	return nil, false
+}
+
+func (gb *GroupBase) HealthCheckOption() (string, utils.IntRanges[uint16], error) {
+	hp, ok := gb.inlineProvider()
+	if !ok {
+		return "", nil, errors.New("group only uses proxy providers")
+	}
+	url, expectedStatus := hp.HealthCheckOption()
+	return url, expectedStatus, nil
+}
+
+// testExpectedStatus is the expected status of the manual tests, as the group's own health check uses
+func (gb *GroupBase) testExpectedStatus() utils.IntRanges[uint16] {
+	if _, expectedStatus, err := gb.HealthCheckOption(); err == nil {
+		return expectedStatus
+	}
+	return gb.expectedStatus
+}
+
+func (gb *GroupBase) SetHealthCheckOption(url string, expectedStatus utils.IntRanges[uint16]) error {
+	hp, ok := gb.inlineProvider()
+	if !ok {
+		return errors.New("group only uses proxy providers")
+	}
+	hp.SetHealthCheckOption(url, expectedStatus)
+	go gb.healthCheck()
+	return nil
+}
+
+func (gb *GroupBase) HealthCheckLazy() (bool, error) {
+	hp, ok := gb.inlineProvider()
+	if !ok {
+		return false, errors.New("group only uses proxy providers")
+	}
+	return hp.HealthCheckLazy(), nil
+}
+
+func (gb *GroupBase) SetHealthCheckLazy(lazy bool) error {
+	hp, ok := gb.inlineProvider()
+	if !ok {
+		return errors.New("group only uses proxy providers")
+	}
+	hp.SetHealthCheckLazy(lazy)
+	return nil
+}
+
 func (gb *GroupBase) URLTest(ctx context.Context, url string) (map[string]uint16, error) {
+	mp := map[string]uint16{}
+	gb.URLTestEach(ctx, url, func(proxy C.Proxy, delay uint16, err error) {
+		if err == nil {
+			mp[proxy.Name()] = delay
+		}
+	})
+
+	if len(mp) == 0 {
+		return mp, fmt.Errorf("get delay: all proxies timeout")
+	} else {
+		return mp, nil
+	}
+}
+
+// URLTestEach tests all the proxies at once and calls fn with each result as it arrives,
+// the calls never overlap. It returns when every test is done
+func (gb *GroupBase) URLTestEach(ctx context.Context, url string, fn func(proxy C.Proxy, delay uint16, err error)) {
 	var wg sync.WaitGroup
 	var lock sync.Mutex
-	mp := map[string]uint16{}
+	expectedStatus := gb.testExpectedStatus()
 	proxies := gb.GetProxies(false)
 	for _, proxy := range proxies {
 		proxy := proxy
 		wg.Add(1)
 		go func() {
-			delay, err := proxy.URLTest(ctx, url)
-			if err == nil {
-				lock.Lock()
-				mp[proxy.Name()] = delay
-				lock.Unlock()
-			}
+			delay, err := proxy.URLTest(ctx, url, expectedStatus)
+			lock.Lock()
+			fn(proxy, delay, err)
+			lock.Unlock()
 
 			wg.Done()
 		}()
 	}
 	wg.Wait()
-
-	if len(mp) == 0 {
-		return mp, fmt.Errorf("get delay: all proxies timeout")
-	} else {
-		return mp, nil
-	}
 }
 
 func (gb *GroupBase) onDialFailed(adapterType C.AdapterType, err error) {
@@ -187,6 +301,12 @@ func (gb *GroupBase) onDialFailed(adapterType C.AdapterType, err error) {
 		return
 	}
 
+	gb.ReportFailure(err)
+}
+
+// ReportFailure counts a failure of a connection that was dialed through the
+// group, enough of them in a short time trigger a health check
+func (gb *GroupBase) ReportFailure(err error) {
 	go func() {
 		gb.failedTestMux.Lock()
 		defer gb.failedTestMux.Unlock()
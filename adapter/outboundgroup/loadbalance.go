@@ -197,13 +197,11 @@ func (lb *LoadBalance) Unwrap(metadata *C.Metadata, touch bool) C.Proxy {
 
 // MarshalJSON implements C.ProxyAdapter
 func (lb *LoadBalance) MarshalJSON() ([]byte, error) {
-	var all []string
-	for _, proxy := range lb.GetProxies(false) {
-		all = append(all, proxy.Name())
-	}
+	all, hidden := lb.memberNames()
 	return json.Marshal(map[string]any{
-		"type": lb.Type().String(),
-		"all":  all,
+		"type":   lb.Type().String(),
+		"all":    all,
+		"hidden": hidden,
 	})
 }
 
@@ -222,13 +220,18 @@ func NewLoadBalance(option *GroupCommonOption, providers []provider.ProxyProvide
 	return &LoadBalance{
 		GroupBase: NewGroupBase(GroupBaseOption{
 			outbound.BaseOption{
-				Name:        option.Name,
-				Type:        C.LoadBalance,
-				Interface:   option.Interface,
-				RoutingMark: option.RoutingMark,
+				Name:          option.Name,
+				Type:          C.LoadBalance,
+				Interface:     option.Interface,
+				RoutingMark:   option.RoutingMark,
+				MPTCP:         option.MPTCP,
+				SendBuffer:    option.SendBuffer,
+				ReceiveBuffer: option.ReceiveBuffer,
 			},
 			option.Filter,
 			providers,
+			option.Hidden,
+			option.ExpectedStatus,
 		}),
 		strategyFn: strategyFn,
 		disableUDP: option.DisableUDP,
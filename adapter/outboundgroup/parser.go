@@ -7,8 +7,11 @@ import (
 	"github.com/Dreamacro/clash/adapter/outbound"
 	"github.com/Dreamacro/clash/adapter/provider"
 	"github.com/Dreamacro/clash/common/structure"
+	"github.com/Dreamacro/clash/common/utils"
 	C "github.com/Dreamacro/clash/constant"
 	types "github.com/Dreamacro/clash/constant/provider"
+
+	"golang.org/x/exp/slices"
 )
 
 var (
@@ -30,6 +33,20 @@ type GroupCommonOption struct {
 	Lazy       bool     `group:"lazy,omitempty"`
 	DisableUDP bool     `group:"disable-udp,omitempty"`
 	Filter     string   `group:"filter,omitempty"`
+	Hidden     []string `group:"hidden,omitempty"`
+	AutoSelect bool     `group:"auto-select,omitempty"`
+	// ExpectedStatus like "204" or "200-299", empty accepts any status
+	ExpectedStatus string `group:"expected-status,omitempty"`
+	// HealthCheckMode is connect or ttfb, see provider.ParseHealthCheckMode
+	HealthCheckMode string `group:"health-check-mode,omitempty"`
+	// HealthCheckBurst probes every member that many times at once to measure the loss
+	HealthCheckBurst int `group:"health-check-burst,omitempty"`
+	// URLs are used in turn by the health checks of Proxies, URL is ignored when set
+	URLs []string `group:"urls,omitempty"`
+	// FallbackTo is the group a fallback uses once all of its members are dead
+	FallbackTo string `group:"fallback-to,omitempty"`
+	// StrictUDP makes a relay whose chain can't carry UDP fail it with the hop at fault instead of skipping it
+	StrictUDP bool `group:"strict-udp,omitempty"`
 }
 
 func ParseProxyGroup(config map[string]any, proxyMap map[string]C.Proxy, providersMap map[string]types.ProxyProvider) (C.ProxyAdapter, error) {
@@ -48,12 +65,34 @@ func ParseProxyGroup(config map[string]any, proxyMap map[string]C.Proxy, provide
 
 	groupName := groupOption.Name
 
+	if groupOption.FallbackTo != "" && groupOption.Type != "fallback" {
+		return nil, fmt.Errorf("fallback-to is only for fallback groups, not %s", groupOption.Type)
+	}
+
+	expectedStatus, err := utils.NewIntRanges[uint16](groupOption.ExpectedStatus)
+	if err != nil {
+		return nil, fmt.Errorf("expected-status error: %w", err)
+	}
+	ttfb, err := provider.ParseHealthCheckMode(groupOption.HealthCheckMode)
+	if err != nil {
+		return nil, err
+	}
+
 	providers := []types.ProxyProvider{}
 
 	if len(groupOption.Proxies) == 0 && len(groupOption.Use) == 0 {
 		return nil, errMissProxy
 	}
 
+	// the members of the providers are only known once they are loaded, so only a group of inline proxies is checked
+	if len(groupOption.Use) == 0 {
+		for _, name := range groupOption.Hidden {
+			if !slices.Contains(groupOption.Proxies, name) {
+				return nil, fmt.Errorf("hidden member %s is not in proxies", name)
+			}
+		}
+	}
+
 	if len(groupOption.Proxies) != 0 {
 		ps, err := getProxies(proxyMap, groupOption.Proxies)
 		if err != nil {
@@ -64,9 +103,20 @@ func ParseProxyGroup(config map[string]any, proxyMap map[string]C.Proxy, provide
 			return nil, errDuplicateProvider
 		}
 
-		// select don't need health check
+		if groupOption.URL == "" {
+			groupOption.URL = "http://www.gstatic.com/generate_204"
+		}
+
+		// select don't need health check, unless it picks the fastest member on first use
 		if groupOption.Type == "select" || groupOption.Type == "relay" {
-			hc := provider.NewHealthCheck(ps, "", 0, true)
+			url := ""
+			if groupOption.AutoSelect {
+				url = groupOption.URL
+			}
+			hc := provider.NewHealthCheck(ps, url, expectedStatus, 0, 0, true, ttfb, uint(groupOption.HealthCheckBurst))
+			if groupOption.AutoSelect {
+				hc.SetURLs(groupOption.URLs)
+			}
 			pd, err := provider.NewCompatibleProvider(groupName, ps, hc)
 			if err != nil {
 				return nil, err
@@ -75,15 +125,12 @@ func ParseProxyGroup(config map[string]any, proxyMap map[string]C.Proxy, provide
 			providers = append(providers, pd)
 			providersMap[groupName] = pd
 		} else {
-			if groupOption.URL == "" {
-				groupOption.URL = "http://www.gstatic.com/generate_204"
-			}
-
 			if groupOption.Interval == 0 {
 				groupOption.Interval = 300
 			}
 
-			hc := provider.NewHealthCheck(ps, groupOption.URL, uint(groupOption.Interval), groupOption.Lazy)
+			hc := provider.NewHealthCheck(ps, groupOption.URL, expectedStatus, uint(groupOption.Interval), 0, groupOption.Lazy, ttfb, uint(groupOption.HealthCheckBurst))
+			hc.SetURLs(groupOption.URLs)
 			pd, err := provider.NewCompatibleProvider(groupName, ps, hc)
 			if err != nil {
 				return nil, err
@@ -112,7 +159,15 @@ func ParseProxyGroup(config map[string]any, proxyMap map[string]C.Proxy, provide
 	case "select":
 		group = NewSelector(groupOption, providers)
 	case "fallback":
-		group = NewFallback(groupOption, providers)
+		fallback := NewFallback(groupOption, providers)
+		if groupOption.FallbackTo != "" {
+			next, ok := proxyMap[groupOption.FallbackTo]
+			if !ok {
+				return nil, fmt.Errorf("fallback-to '%s' not found", groupOption.FallbackTo)
+			}
+			fallback.fallbackTo = next
+		}
+		group = fallback
 	case "load-balance":
 		strategy := parseStrategy(config)
 		return NewLoadBalance(groupOption, providers, strategy)
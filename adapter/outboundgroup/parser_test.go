@@ -0,0 +1,104 @@
+package outboundgroup
+
+import (
+	"testing"
+
+	"github.com/Dreamacro/clash/adapter"
+	"github.com/Dreamacro/clash/adapter/outbound"
+	C "github.com/Dreamacro/clash/constant"
+	types "github.com/Dreamacro/clash/constant/provider"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testProxyMap() map[string]C.Proxy {
+	return map[string]C.Proxy{
+		"DIRECT": adapter.NewProxy(outbound.NewDirect()),
+		"REJECT": adapter.NewProxy(outbound.NewReject()),
+	}
+}
+
+// testProvider stands for a proxy provider not loaded yet
+type testProvider struct {
+	types.ProxyProvider
+	name string
+}
+
+func (p *testProvider) Name() string { return p.name }
+
+func (p *testProvider) VehicleType() types.VehicleType { return types.HTTP }
+
+func TestParseProxyGroup_Hidden(t *testing.T) {
+	tests := []struct {
+		name    string
+		hidden  []any
+		use     bool
+		wantErr bool
+	}{
+		{name: "member", hidden: []any{"REJECT"}},
+		{name: "unknown", hidden: []any{"missing"}, wantErr: true},
+		{name: "one unknown", hidden: []any{"REJECT", "missing"}, wantErr: true},
+		// a provider member can't be checked before the provider is loaded
+		{name: "unknown with providers", hidden: []any{"missing"}, use: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]any{
+				"name":    "group",
+				"type":    "select",
+				"proxies": []any{"DIRECT", "REJECT"},
+				"hidden":  tt.hidden,
+			}
+			providers := map[string]types.ProxyProvider{}
+			if tt.use {
+				providers["pd"] = &testProvider{name: "pd"}
+				config["use"] = []any{"pd"}
+			}
+
+			_, err := ParseProxyGroup(config, testProxyMap(), providers)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseProxyGroup_FallbackTo(t *testing.T) {
+	tests := []struct {
+		name       string
+		groupType  string
+		fallbackTo string
+		wantErr    bool
+	}{
+		{name: "fallback", groupType: "fallback", fallbackTo: "REJECT"},
+		{name: "fallback unknown target", groupType: "fallback", fallbackTo: "missing", wantErr: true},
+		{name: "select", groupType: "select", fallbackTo: "REJECT", wantErr: true},
+		{name: "url-test", groupType: "url-test", fallbackTo: "REJECT", wantErr: true},
+		{name: "load-balance", groupType: "load-balance", fallbackTo: "REJECT", wantErr: true},
+		{name: "select without", groupType: "select"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]any{
+				"name":    "group",
+				"type":    tt.groupType,
+				"proxies": []any{"DIRECT"},
+				"lazy":    true,
+			}
+			if tt.fallbackTo != "" {
+				config["fallback-to"] = tt.fallbackTo
+			}
+
+			_, err := ParseProxyGroup(config, testProxyMap(), map[string]types.ProxyProvider{})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
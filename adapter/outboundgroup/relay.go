@@ -13,6 +13,7 @@ import (
 
 type Relay struct {
 	*GroupBase
+	strictUDP bool
 }
 
 // DialContext implements C.ProxyAdapter
@@ -37,6 +38,7 @@ func (r *Relay) DialContext(ctx context.Context, metadata *C.Metadata, opts ...d
 
 	var currentMeta *C.Metadata
 	for _, proxy := range proxies[1:] {
+		// a hop's server domain stays unresolved, the preceding hop resolves it from its side
 		currentMeta, err = addrToMetadata(proxy.Addr())
 		if err != nil {
 			return nil, err
@@ -69,6 +71,9 @@ func (r *Relay) DialContext(ctx context.Context, metadata *C.Metadata, opts ...d
 // ListenPacketContext implements C.ProxyAdapter
 func (r *Relay) ListenPacketContext(ctx context.Context, metadata *C.Metadata, opts ...dialer.Option) (_ C.PacketConn, err error) {
 	proxies, chainProxies := r.proxies(metadata, true)
+	if err := r.udpError(proxies); err != nil {
+		return nil, err
+	}
 
 	switch len(proxies) {
 	case 0:
@@ -88,6 +93,7 @@ func (r *Relay) ListenPacketContext(ctx context.Context, metadata *C.Metadata, o
 
 	var currentMeta *C.Metadata
 	for _, proxy := range proxies[1:] {
+		// a hop's server domain stays unresolved, the preceding hop resolves it from its side
 		currentMeta, err = addrToMetadata(proxy.Addr())
 		if err != nil {
 			return nil, err
@@ -121,25 +127,44 @@ func (r *Relay) ListenPacketContext(ctx context.Context, metadata *C.Metadata, o
 	return pc, nil
 }
 
-// SupportUDP implements C.ProxyAdapter
+// SupportUDP implements C.ProxyAdapter, a strict-udp relay takes all UDP and fails what its chain can't carry with udpError
 func (r *Relay) SupportUDP() bool {
-	proxies, _ := r.proxies(nil, false)
-	if len(proxies) == 0 { // C.Direct
+	if r.strictUDP {
 		return true
 	}
+	proxies, _ := r.proxies(nil, false)
+	return r.udpError(proxies) == nil
+}
+
+// udpError names the hop keeping the chain from carrying UDP, only the last one relays the packets
+func (r *Relay) udpError(proxies []C.Proxy) error {
+	switch len(proxies) {
+	case 0: // C.Direct
+		return nil
+	case 1:
+		if !proxies[0].SupportUDP() {
+			return fmt.Errorf("relay %s: %s (%s) does not support UDP", r.Name(), proxies[0].Name(), proxies[0].Type())
+		}
+		return nil
+	}
+
 	last := proxies[len(proxies)-1]
-	return last.SupportUDP() && last.SupportUOT()
+	if !last.SupportUDP() {
+		return fmt.Errorf("relay %s: last hop %s (%s) does not support UDP", r.Name(), last.Name(), last.Type())
+	}
+	if !last.SupportUOT() {
+		return fmt.Errorf("relay %s: last hop %s (%s) can't carry UDP over the relayed stream", r.Name(), last.Name(), last.Type())
+	}
+	return nil
 }
 
 // MarshalJSON implements C.ProxyAdapter
 func (r *Relay) MarshalJSON() ([]byte, error) {
-	all := []string{}
-	for _, proxy := range r.GetProxies(false) {
-		all = append(all, proxy.Name())
-	}
+	all, hidden := r.memberNames()
 	return json.Marshal(map[string]any{
-		"type": r.Type().String(),
-		"all":  all,
+		"type":   r.Type().String(),
+		"all":    all,
+		"hidden": hidden,
 	})
 }
 
@@ -179,13 +204,19 @@ func NewRelay(option *GroupCommonOption, providers []provider.ProxyProvider) *Re
 	return &Relay{
 		GroupBase: NewGroupBase(GroupBaseOption{
 			outbound.BaseOption{
-				Name:        option.Name,
-				Type:        C.Relay,
-				Interface:   option.Interface,
-				RoutingMark: option.RoutingMark,
+				Name:          option.Name,
+				Type:          C.Relay,
+				Interface:     option.Interface,
+				RoutingMark:   option.RoutingMark,
+				MPTCP:         option.MPTCP,
+				SendBuffer:    option.SendBuffer,
+				ReceiveBuffer: option.ReceiveBuffer,
 			},
 			"",
 			providers,
+			option.Hidden,
+			option.ExpectedStatus,
 		}),
+		strictUDP: option.StrictUDP,
 	}
 }
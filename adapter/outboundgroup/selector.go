@@ -4,17 +4,23 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"sync"
 
 	"github.com/Dreamacro/clash/adapter/outbound"
 	"github.com/Dreamacro/clash/component/dialer"
 	C "github.com/Dreamacro/clash/constant"
 	"github.com/Dreamacro/clash/constant/provider"
+	"github.com/Dreamacro/clash/log"
+
+	"go.uber.org/atomic"
 )
 
 type Selector struct {
 	*GroupBase
 	disableUDP bool
-	selected   string
+	selected   *atomic.String
+	autoSelect bool
+	autoOnce   sync.Once
 }
 
 // DialContext implements C.ProxyAdapter
@@ -46,15 +52,14 @@ func (s *Selector) SupportUDP() bool {
 
 // MarshalJSON implements C.ProxyAdapter
 func (s *Selector) MarshalJSON() ([]byte, error) {
-	all := []string{}
-	for _, proxy := range s.GetProxies(false) {
-		all = append(all, proxy.Name())
-	}
+	all, hidden := s.memberNames()
 
 	return json.Marshal(map[string]any{
-		"type": s.Type().String(),
-		"now":  s.Now(),
-		"all":  all,
+		"type":   s.Type().String(),
+		"now":    s.Now(),
+		"all":    all,
+		"hidden": hidden,
+		"delays": s.memberDelays(),
 	})
 }
 
@@ -65,7 +70,7 @@ func (s *Selector) Now() string {
 func (s *Selector) Set(name string) error {
 	for _, proxy := range s.GetProxies(false) {
 		if proxy.Name() == name {
-			s.selected = name
+			s.selected.Store(name)
 			return nil
 		}
 	}
@@ -80,28 +85,72 @@ func (s *Selector) Unwrap(metadata *C.Metadata, touch bool) C.Proxy {
 
 func (s *Selector) selectedProxy(touch bool) C.Proxy {
 	proxies := s.GetProxies(touch)
+	selected := s.selected.Load()
 	for _, proxy := range proxies {
-		if proxy.Name() == s.selected {
+		if proxy.Name() == selected {
 			return proxy
 		}
 	}
 
+	if touch && s.autoSelect {
+		s.autoOnce.Do(func() {
+			go s.selectFastest()
+		})
+	}
+
 	return proxies[0]
 }
 
+// selectFastest tests all members once and selects the fastest alive one,
+// unless a choice was made or restored in the meantime
+func (s *Selector) selectFastest() {
+	s.healthCheck()
+
+	var (
+		fast     C.Proxy
+		min      uint16
+		selected = s.selected.Load()
+	)
+	for _, proxy := range s.GetProxies(false) {
+		if proxy.Name() == selected {
+			return
+		}
+
+		if !proxy.Alive() {
+			continue
+		}
+
+		if delay := proxy.LastDelay(); fast == nil || delay < min {
+			fast = proxy
+			min = delay
+		}
+	}
+
+	// a Set during the tests wins
+	if fast != nil && s.selected.CompareAndSwap(selected, fast.Name()) {
+		log.Infoln("ProxyGroup: %s auto selected %s", s.Name(), fast.Name())
+	}
+}
+
 func NewSelector(option *GroupCommonOption, providers []provider.ProxyProvider) *Selector {
 	return &Selector{
 		GroupBase: NewGroupBase(GroupBaseOption{
 			outbound.BaseOption{
-				Name:        option.Name,
-				Type:        C.Selector,
-				Interface:   option.Interface,
-				RoutingMark: option.RoutingMark,
+				Name:          option.Name,
+				Type:          C.Selector,
+				Interface:     option.Interface,
+				RoutingMark:   option.RoutingMark,
+				MPTCP:         option.MPTCP,
+				SendBuffer:    option.SendBuffer,
+				ReceiveBuffer: option.ReceiveBuffer,
 			},
 			option.Filter,
 			providers,
+			option.Hidden,
+			option.ExpectedStatus,
 		}),
-		selected:   "COMPATIBLE",
+		selected:   atomic.NewString("COMPATIBLE"),
 		disableUDP: option.DisableUDP,
+		autoSelect: option.AutoSelect,
 	}
 }
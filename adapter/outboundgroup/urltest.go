@@ -76,15 +76,17 @@ func (u *URLTest) fast(touch bool) C.Proxy {
 				continue
 			}
 
+			// a lower loss, only measured by burst health checks, wins over a lower delay
 			delay := proxy.LastDelay()
-			if delay < min {
+			if loss := proxy.LastLoss(); !fast.Alive() || loss < fast.LastLoss() || loss == fast.LastLoss() && delay < min {
 				fast = proxy
 				min = delay
 			}
 		}
 
 		// tolerance
-		if u.fastNode == nil || fastNotExist || !u.fastNode.Alive() || u.fastNode.LastDelay() > fast.LastDelay()+u.tolerance {
+		if u.fastNode == nil || fastNotExist || !u.fastNode.Alive() || u.fastNode.LastLoss() > fast.LastLoss() ||
+			u.fastNode.LastDelay() > fast.LastDelay()+u.tolerance {
 			u.fastNode = fast
 		}
 
@@ -108,14 +110,12 @@ func (u *URLTest) SupportUDP() bool {
 
 // MarshalJSON implements C.ProxyAdapter
 func (u *URLTest) MarshalJSON() ([]byte, error) {
-	all := []string{}
-	for _, proxy := range u.GetProxies(false) {
-		all = append(all, proxy.Name())
-	}
+	all, hidden := u.memberNames()
 	return json.Marshal(map[string]any{
-		"type": u.Type().String(),
-		"now":  u.Now(),
-		"all":  all,
+		"type":   u.Type().String(),
+		"now":    u.Now(),
+		"all":    all,
+		"hidden": hidden,
 	})
 }
 
@@ -136,14 +136,19 @@ func NewURLTest(option *GroupCommonOption, providers []provider.ProxyProvider, o
 	urlTest := &URLTest{
 		GroupBase: NewGroupBase(GroupBaseOption{
 			outbound.BaseOption{
-				Name:        option.Name,
-				Type:        C.URLTest,
-				Interface:   option.Interface,
-				RoutingMark: option.RoutingMark,
+				Name:          option.Name,
+				Type:          C.URLTest,
+				Interface:     option.Interface,
+				RoutingMark:   option.RoutingMark,
+				MPTCP:         option.MPTCP,
+				SendBuffer:    option.SendBuffer,
+				ReceiveBuffer: option.ReceiveBuffer,
 			},
 
 			option.Filter,
 			providers,
+			option.Hidden,
+			option.ExpectedStatus,
 		}),
 		fastSingle: singledo.NewSingle[C.Proxy](time.Second * 10),
 		disableUDP: option.DisableUDP,
@@ -1,14 +1,17 @@
 package outboundgroup
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/netip"
 	"time"
 
+	"github.com/Dreamacro/clash/common/utils"
 	C "github.com/Dreamacro/clash/constant"
 )
 
+// addrToMetadata keeps a domain as is instead of resolving it locally
 func addrToMetadata(rawAddress string) (addr *C.Metadata, err error) {
 	host, port, err := net.SplitHostPort(rawAddress)
 	if err != nil {
@@ -55,3 +58,23 @@ func tcpKeepAlive(c net.Conn) {
 type SelectAble interface {
 	Set(string) error
 }
+
+// URLTestStreamer is a group reporting the result of each proxy while testing them
+type URLTestStreamer interface {
+	URLTestEach(ctx context.Context, url string, fn func(proxy C.Proxy, delay uint16, err error))
+}
+
+// HealthCheckEditable is a group whose test url, expected status and laziness can be changed at runtime
+type HealthCheckEditable interface {
+	HealthCheckOption() (string, utils.IntRanges[uint16], error)
+	SetHealthCheckOption(url string, expectedStatus utils.IntRanges[uint16]) error
+	HealthCheckLazy() (bool, error)
+	SetHealthCheckLazy(lazy bool) error
+}
+
+type healthCheckOptioner interface {
+	HealthCheckOption() (string, utils.IntRanges[uint16])
+	SetHealthCheckOption(url string, expectedStatus utils.IntRanges[uint16])
+	HealthCheckLazy() bool
+	SetHealthCheckLazy(lazy bool)
+}
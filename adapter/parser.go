@@ -2,6 +2,7 @@ package adapter
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/Dreamacro/clash/adapter/outbound"
 	"github.com/Dreamacro/clash/common/structure"
@@ -88,6 +89,27 @@ func ParseProxy(mapping map[string]any) (C.Proxy, error) {
 			break
 		}
 		proxy, err = outbound.NewHysteria(*hyOption)
+	case "quic":
+		quicOption := &outbound.QuicOption{}
+		err = decoder.Decode(mapping, quicOption)
+		if err != nil {
+			break
+		}
+		proxy, err = outbound.NewQuic(*quicOption)
+	case "direct":
+		directOption := &outbound.DirectOption{}
+		err = decoder.Decode(mapping, directOption)
+		if err != nil {
+			break
+		}
+		proxy, err = outbound.NewDirectWithOption(*directOption)
+	case "reject":
+		rejectOption := &outbound.RejectOption{}
+		err = decoder.Decode(mapping, rejectOption)
+		if err != nil {
+			break
+		}
+		proxy, err = outbound.NewRejectWithOption(*rejectOption)
 	default:
 		return nil, fmt.Errorf("unsupport proxy type: %s", proxyType)
 	}
@@ -96,5 +118,13 @@ func ParseProxy(mapping map[string]any) (C.Proxy, error) {
 		return nil, err
 	}
 
-	return NewProxy(proxy), nil
+	p := NewProxy(proxy)
+	// the location of a node may come with the proxy config, e.g. from a provider
+	if country, ok := mapping["country"].(string); ok {
+		p.country = strings.ToUpper(country)
+	}
+	if city, ok := mapping["city"].(string); ok {
+		p.city = city
+	}
+	return p, nil
 }
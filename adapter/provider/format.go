@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Dreamacro/clash/component/mmdb"
+	"github.com/Dreamacro/clash/component/resolver"
+	C "github.com/Dreamacro/clash/constant"
+)
+
+var nameFormatReg = regexp.MustCompile(`\{([\w-]+)\}`)
+
+// formatProxyName renders format with the fields of a proxy mapping, e.g. "{country}-{server}",
+// {country} is the country given by the proxy or else the GeoIP country of a server given as an ip.
+// A domain server is not resolved, loading a provider must not wait for DNS
+func formatProxyName(format string, mapping map[string]any) string {
+	return nameFormatReg.ReplaceAllStringFunc(format, func(s string) string {
+		key := s[1 : len(s)-1]
+		if country, _ := mapping["country"].(string); key == "country" && country == "" {
+			server, _ := mapping["server"].(string)
+			return ipCountry(server)
+		}
+
+		switch value := mapping[key].(type) {
+		case string:
+			return value
+		case int:
+			return strconv.Itoa(value)
+		case nil:
+			return ""
+		default:
+			return fmt.Sprint(value)
+		}
+	})
+}
+
+// ipCountry returns the ISO country code of server from the MaxMind database when it is an ip
+func ipCountry(server string) string {
+	ip, err := netip.ParseAddr(server)
+	if err != nil || C.GeodataMode {
+		return ""
+	}
+
+	record, _ := mmdb.Instance().Country(ip.AsSlice())
+	return strings.ToUpper(record.Country.IsoCode)
+}
+
+// ServerCountry returns the ISO country code of server from the MaxMind database
+func ServerCountry(server string) string {
+	if server == "" || C.GeodataMode {
+		return ""
+	}
+
+	ip, err := resolver.ResolveIP(server)
+	if err != nil {
+		return ""
+	}
+
+	record, _ := mmdb.Instance().Country(ip.AsSlice())
+	return strings.ToUpper(record.Country.IsoCode)
+}
+
+// renameProxy returns a copy of mapping named by format, duplicated names get a number suffix
+func renameProxy(mapping map[string]any, format string, names map[string]struct{}) (map[string]any, error) {
+	name := strings.TrimSpace(formatProxyName(format, mapping))
+	if name == "" {
+		return nil, errors.New("name-format renders an empty name")
+	}
+	if _, ok := names[name]; ok {
+		for i := 2; ; i++ {
+			if _, ok := names[fmt.Sprintf("%s %d", name, i)]; !ok {
+				name = fmt.Sprintf("%s %d", name, i)
+				break
+			}
+		}
+	}
+	names[name] = struct{}{}
+
+	renamed := make(map[string]any, len(mapping))
+	for k, v := range mapping {
+		renamed[k] = v
+	}
+	renamed["name"] = name
+	return renamed, nil
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenameProxy(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		mapping map[string]any
+		want    string
+		wantErr bool
+	}{
+		{name: "fields", format: "{type}-{server}:{port}", mapping: map[string]any{"type": "ss", "server": "a.com", "port": 443}, want: "ss-a.com:443"},
+		{name: "given country", format: "{country} {name}", mapping: map[string]any{"name": "n", "country": "JP", "server": "a.com"}, want: "JP n"},
+		{name: "domain server is not resolved", format: "{country}{name}", mapping: map[string]any{"name": "n", "server": "a.com"}, want: "n"},
+		{name: "missing field", format: "{name}-{missing}", mapping: map[string]any{"name": "n"}, want: "n-"},
+		{name: "empty", format: "{country}", mapping: map[string]any{"server": "a.com"}, wantErr: true},
+		{name: "blank", format: " {missing} ", mapping: map[string]any{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renamed, err := renameProxy(tt.mapping, tt.format, map[string]struct{}{})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, renamed["name"])
+			}
+		})
+	}
+}
+
+func TestRenameProxy_Duplicates(t *testing.T) {
+	names := map[string]struct{}{}
+	mapping := map[string]any{"name": "a", "server": "a.com"}
+
+	for _, want := range []string{"a.com", "a.com 2", "a.com 3"} {
+		renamed, err := renameProxy(mapping, "{server}", names)
+		if assert.NoError(t, err) {
+			assert.Equal(t, want, renamed["name"])
+		}
+	}
+	assert.Equal(t, "a", mapping["name"], "the original mapping is changed")
+}
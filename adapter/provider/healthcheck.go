@@ -2,10 +2,14 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/Dreamacro/clash/common/batch"
 	"github.com/Dreamacro/clash/common/singledo"
+	"github.com/Dreamacro/clash/common/utils"
 	C "github.com/Dreamacro/clash/constant"
 	"github.com/Dreamacro/clash/log"
 
@@ -23,37 +27,57 @@ type HealthCheckOption struct {
 }
 
 type HealthCheck struct {
-	url       string
-	proxies   []C.Proxy
-	interval  uint
-	lazy      bool
-	lastTouch *atomic.Int64
-	done      chan struct{}
-	singleDo  *singledo.Single[struct{}]
+	url            string
+	urls           []string
+	round          *atomic.Uint32
+	expectedStatus utils.IntRanges[uint16]
+	optionMux      sync.RWMutex
+	proxies        []C.Proxy
+	interval       uint
+	jitter         uint
+	lazy           *atomic.Bool
+	ttfb           bool
+	burst          uint
+	lastTouch      *atomic.Int64
+	done           chan struct{}
+	singleDo       *singledo.Single[struct{}]
 }
 
 func (hc *HealthCheck) process() {
-	ticker := time.NewTicker(time.Duration(hc.interval) * time.Second)
+	timer := time.NewTimer(hc.nextInterval())
 
 	go func() {
-		time.Sleep(30 * time.Second)
+		time.Sleep(30*time.Second + hc.randomJitter())
 		hc.lazyCheck()
 	}()
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-timer.C:
 			hc.lazyCheck()
+			timer.Reset(hc.nextInterval())
 		case <-hc.done:
-			ticker.Stop()
+			timer.Stop()
 			return
 		}
 	}
 }
 
+// nextInterval spreads the checks of providers sharing the same interval
+func (hc *HealthCheck) nextInterval() time.Duration {
+	return time.Duration(hc.interval)*time.Second + hc.randomJitter()
+}
+
+func (hc *HealthCheck) randomJitter() time.Duration {
+	if hc.jitter == 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(time.Duration(hc.jitter) * time.Second)))
+}
+
 func (hc *HealthCheck) lazyCheck() bool {
 	now := time.Now().Unix()
-	if !hc.lazy || now-hc.lastTouch.Load() < int64(hc.interval) {
+	if !hc.lazy.Load() || now-hc.lastTouch.Load() < int64(hc.interval) {
 		hc.check()
 		return true
 	} else {
@@ -74,13 +98,63 @@ func (hc *HealthCheck) touch() {
 	hc.lastTouch.Store(time.Now().Unix())
 }
 
+// Option returns the test url and the expected response status
+func (hc *HealthCheck) Option() (string, utils.IntRanges[uint16]) {
+	hc.optionMux.RLock()
+	defer hc.optionMux.RUnlock()
+	return hc.url, hc.expectedStatus
+}
+
+// SetOption changes the test url and the expected response status, used from the next check.
+// It also stops the rotation of SetURLs, the new url is used for every check
+func (hc *HealthCheck) SetOption(url string, expectedStatus utils.IntRanges[uint16]) {
+	hc.optionMux.Lock()
+	defer hc.optionMux.Unlock()
+	hc.url = url
+	hc.urls = nil
+	hc.expectedStatus = expectedStatus
+}
+
+// SetURLs makes every check cycle use the next url of urls in turn, so one test
+// target going down doesn't mark all the proxies dead
+func (hc *HealthCheck) SetURLs(urls []string) {
+	hc.optionMux.Lock()
+	defer hc.optionMux.Unlock()
+	hc.urls = urls
+	if len(urls) != 0 {
+		hc.url = urls[0]
+	}
+}
+
+// Lazy reports whether the periodic check is skipped while the proxies are unused
+func (hc *HealthCheck) Lazy() bool {
+	return hc.lazy.Load()
+}
+
+// SetLazy switches between lazy and eager periodic checks, used from the next one
+func (hc *HealthCheck) SetLazy(lazy bool) {
+	hc.lazy.Store(lazy)
+}
+
+// nextOption returns the url and the expected status of the coming check
+func (hc *HealthCheck) nextOption() (string, utils.IntRanges[uint16]) {
+	hc.optionMux.RLock()
+	defer hc.optionMux.RUnlock()
+	if len(hc.urls) < 2 {
+		return hc.url, hc.expectedStatus
+	}
+	return hc.urls[(hc.round.Inc()-1)%uint32(len(hc.urls))], hc.expectedStatus
+}
+
 func (hc *HealthCheck) check() {
 	_, _, _ = hc.singleDo.Do(func() (struct{}, error) {
+		// a check coalesced into a running one doesn't take the next url
+		url, expectedStatus := hc.nextOption()
 		id := ""
 		if uid, err := uuid.NewV4(); err == nil {
 			id = uid.String()
 		}
-		log.Debugln("Start New Health Checking {%s}", id)
+		log.Debugln("Start New Health Checking {%s} with %s", id, url)
 		b, _ := batch.New[bool](context.Background(), batch.WithConcurrencyNum[bool](10))
 		for _, proxy := range hc.proxies {
 			p := proxy
@@ -88,8 +162,15 @@ func (hc *HealthCheck) check() {
 				ctx, cancel := context.WithTimeout(context.Background(), defaultURLTestTimeout)
 				defer cancel()
 				log.Debugln("Health Checking %s {%s}", p.Name(), id)
-				_, _ = p.URLTest(ctx, hc.url)
-				log.Debugln("Health Checked %s : %t %d ms {%s}", p.Name(), p.Alive(), p.LastDelay(), id)
+				switch {
+				case hc.burst > 1:
+					_, _, _ = p.BurstTest(ctx, url, expectedStatus, int(hc.burst), hc.ttfb)
+				case hc.ttfb:
+					_, _ = p.TTFBTest(ctx, url, expectedStatus)
+				default:
+					_, _ = p.URLTest(ctx, url, expectedStatus)
+				}
+				log.Debugln("Health Checked %s : %t %d ms %d%% loss {%s}", p.Name(), p.Alive(), p.LastDelay(), p.LastLoss(), id)
 				return false, nil
 			})
 		}
@@ -104,14 +185,31 @@ func (hc *HealthCheck) close() {
 	hc.done <- struct{}{}
 }
 
-func NewHealthCheck(proxies []C.Proxy, url string, interval uint, lazy bool) *HealthCheck {
+// ParseHealthCheckMode reports whether mode asks for the time to first byte, "connect" (default) only waits for the response header
+func ParseHealthCheckMode(mode string) (ttfb bool, err error) {
+	switch mode {
+	case "", "connect":
+		return false, nil
+	case "ttfb":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unsupported health check mode: %s", mode)
+	}
+}
+
+func NewHealthCheck(proxies []C.Proxy, url string, expectedStatus utils.IntRanges[uint16], interval uint, jitter uint, lazy bool, ttfb bool, burst uint) *HealthCheck {
 	return &HealthCheck{
-		proxies:   proxies,
-		url:       url,
-		interval:  interval,
-		lazy:      lazy,
-		lastTouch: atomic.NewInt64(0),
-		done:      make(chan struct{}, 1),
-		singleDo:  singledo.NewSingle[struct{}](time.Second),
+		proxies:        proxies,
+		url:            url,
+		expectedStatus: expectedStatus,
+		interval:       interval,
+		jitter:         jitter,
+		lazy:           atomic.NewBool(lazy),
+		ttfb:           ttfb,
+		burst:          burst,
+		round:          atomic.NewUint32(0),
+		lastTouch:      atomic.NewInt64(0),
+		done:           make(chan struct{}, 1),
+		singleDo:       singledo.NewSingle[struct{}](time.Second),
 	}
 }
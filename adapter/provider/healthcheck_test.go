@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/Dreamacro/clash/common/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthCheck_NextOption(t *testing.T) {
+	tests := []struct {
+		name string
+		urls []string
+		want []string
+	}{
+		{name: "no rotation", urls: nil, want: []string{"http://default", "http://default"}},
+		{name: "single url", urls: []string{"http://a"}, want: []string{"http://a", "http://a"}},
+		{name: "rotation", urls: []string{"http://a", "http://b", "http://c"}, want: []string{"http://a", "http://b", "http://c", "http://a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHealthCheck(nil, "http://default", utils.IntRanges[uint16]{}, 0, 0, true, false, 0)
+			hc.SetURLs(tt.urls)
+			for _, want := range tt.want {
+				url, _ := hc.nextOption()
+				assert.Equal(t, want, url)
+			}
+		})
+	}
+}
+
+func TestHealthCheck_CoalescedCheckKeepsRound(t *testing.T) {
+	hc := NewHealthCheck(nil, "", utils.IntRanges[uint16]{}, 0, 0, true, false, 0)
+	hc.SetURLs([]string{"http://a", "http://b", "http://c"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hc.check()
+		}()
+	}
+	wg.Wait()
+	hc.check()
+	assert.Equal(t, uint32(1), hc.round.Load())
+
+	// once the coalescing window is over a check runs and takes the next url
+	hc.singleDo.Reset()
+	hc.check()
+	assert.Equal(t, uint32(2), hc.round.Load())
+	url, _ := hc.nextOption()
+	assert.Equal(t, "http://c", url)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Dreamacro/clash/common/structure"
+	"github.com/Dreamacro/clash/common/utils"
 	C "github.com/Dreamacro/clash/constant"
 	types "github.com/Dreamacro/clash/constant/provider"
 )
@@ -14,19 +15,27 @@ import (
 var errVehicleType = errors.New("unsupport vehicle type")
 
 type healthCheckSchema struct {
-	Enable   bool   `provider:"enable"`
-	URL      string `provider:"url"`
-	Interval int    `provider:"interval"`
-	Lazy     bool   `provider:"lazy,omitempty"`
+	Enable         bool   `provider:"enable"`
+	URL            string `provider:"url"`
+	Interval       int    `provider:"interval"`
+	Jitter         int    `provider:"jitter,omitempty"`
+	Lazy           bool   `provider:"lazy,omitempty"`
+	ExpectedStatus string `provider:"expected-status,omitempty"`
+	Mode           string `provider:"mode,omitempty"`
+	Burst          int    `provider:"burst,omitempty"`
+	// URLs are used in turn by the checks, url is ignored when set
+	URLs []string `provider:"urls,omitempty"`
 }
 
 type proxyProviderSchema struct {
 	Type          string            `provider:"type"`
 	Path          string            `provider:"path"`
 	URL           string            `provider:"url,omitempty"`
+	Command       []string          `provider:"command,omitempty"`
 	Interval      int               `provider:"interval,omitempty"`
 	Filter        string            `provider:"filter,omitempty"`
 	ExcludeFilter string            `provider:"exclude-filter,omitempty"`
+	NameFormat    string            `provider:"name-format,omitempty"`
 	HealthCheck   healthCheckSchema `provider:"health-check,omitempty"`
 }
 
@@ -46,7 +55,16 @@ func ParseProxyProvider(name string, mapping map[string]any) (types.ProxyProvide
 	if schema.HealthCheck.Enable {
 		hcInterval = uint(schema.HealthCheck.Interval)
 	}
-	hc := NewHealthCheck([]C.Proxy{}, schema.HealthCheck.URL, hcInterval, schema.HealthCheck.Lazy)
+	expectedStatus, err := utils.NewIntRanges[uint16](schema.HealthCheck.ExpectedStatus)
+	if err != nil {
+		return nil, fmt.Errorf("health-check expected-status error: %w", err)
+	}
+	ttfb, err := ParseHealthCheckMode(schema.HealthCheck.Mode)
+	if err != nil {
+		return nil, err
+	}
+	hc := NewHealthCheck([]C.Proxy{}, schema.HealthCheck.URL, expectedStatus, hcInterval, uint(schema.HealthCheck.Jitter), schema.HealthCheck.Lazy, ttfb, uint(schema.HealthCheck.Burst))
+	hc.SetURLs(schema.HealthCheck.URLs)
 
 	path := C.Path.Resolve(schema.Path)
 
@@ -56,6 +74,12 @@ func ParseProxyProvider(name string, mapping map[string]any) (types.ProxyProvide
 		vehicle = resource.NewFileVehicle(path)
 	case "http":
 		vehicle = resource.NewHTTPVehicle(schema.URL, path)
+	case "command":
+		v, err := resource.NewCommandVehicle(schema.Command, path)
+		if err != nil {
+			return nil, err
+		}
+		vehicle = v
 	default:
 		return nil, fmt.Errorf("%w: %s", errVehicleType, schema.Type)
 	}
@@ -63,5 +87,5 @@ func ParseProxyProvider(name string, mapping map[string]any) (types.ProxyProvide
 	interval := time.Duration(uint(schema.Interval)) * time.Second
 	filter := schema.Filter
 	excludeFilter := schema.ExcludeFilter
-	return NewProxySetProvider(name, interval, filter, excludeFilter, vehicle, hc)
+	return NewProxySetProvider(name, interval, filter, excludeFilter, schema.NameFormat, vehicle, hc)
 }
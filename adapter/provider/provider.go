@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/Dreamacro/clash/common/convert"
+	"github.com/Dreamacro/clash/component/profile/cachefile"
 	"github.com/Dreamacro/clash/component/resource"
 	"github.com/dlclark/regexp2"
 	"runtime"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/Dreamacro/clash/adapter"
+	"github.com/Dreamacro/clash/common/utils"
 	C "github.com/Dreamacro/clash/constant"
 	types "github.com/Dreamacro/clash/constant/provider"
 
@@ -65,15 +67,27 @@ func (pp *proxySetProvider) Update() error {
 	if err == nil && !same {
 		pp.OnUpdate(elm)
 	}
+	if err == nil {
+		pp.recordSubscription()
+	}
 	return err
 }
 
+// UpdateIfOlder updates the provider only when its content is older than maxAge
+func (pp *proxySetProvider) UpdateIfOlder(maxAge time.Duration) error {
+	if pp.UpdatedAt != nil && time.Since(*pp.UpdatedAt) < maxAge {
+		return nil
+	}
+	return pp.Update()
+}
+
 func (pp *proxySetProvider) Initial() error {
 	elm, err := pp.Fetcher.Initial()
 	if err != nil {
 		return err
 	}
 	pp.OnUpdate(elm)
+	pp.recordSubscription()
 	return nil
 }
 
@@ -90,6 +104,7 @@ func (pp *proxySetProvider) Touch() {
 }
 
 func (pp *proxySetProvider) setProxies(proxies []C.Proxy) {
+	restoreRemarks(proxies)
 	pp.proxies = proxies
 	pp.healthCheck.setProxy(proxies)
 	if pp.healthCheck.auto() {
@@ -97,12 +112,28 @@ func (pp *proxySetProvider) setProxies(proxies []C.Proxy) {
 	}
 }
 
+// restoreRemarks sets the notes stored through the API on the proxies of a new fetch
+func restoreRemarks(proxies []C.Proxy) {
+	mapping := cachefile.Cache().RemarkMap()
+	if len(mapping) == 0 {
+		return
+	}
+
+	for _, proxy := range proxies {
+		if p, ok := proxy.(*adapter.Proxy); ok {
+			if remark, exist := mapping[p.Name()]; exist {
+				p.SetRemark(remark)
+			}
+		}
+	}
+}
+
 func stopProxyProvider(pd *ProxySetProvider) {
 	pd.healthCheck.close()
 	_ = pd.Fetcher.Destroy()
 }
 
-func NewProxySetProvider(name string, interval time.Duration, filter string, excludeFilter string, vehicle types.Vehicle, hc *HealthCheck) (*ProxySetProvider, error) {
+func NewProxySetProvider(name string, interval time.Duration, filter string, excludeFilter string, nameFormat string, vehicle types.Vehicle, hc *HealthCheck) (*ProxySetProvider, error) {
 	excludeFilterReg, err := regexp2.Compile(excludeFilter, 0)
 	if err != nil {
 		return nil, fmt.Errorf("invalid excludeFilter regex: %w", err)
@@ -125,7 +156,8 @@ func NewProxySetProvider(name string, interval time.Duration, filter string, exc
 		healthCheck: hc,
 	}
 
-	fetcher := resource.NewFetcher[[]C.Proxy](name, interval, vehicle, proxiesParseAndFilter(filter, excludeFilter, filterRegs, excludeFilterReg), proxiesOnUpdate(pd))
+	fetcher := resource.NewFetcher[[]C.Proxy](name, interval, vehicle, proxiesParseAndFilter(filter, excludeFilter, nameFormat, filterRegs, excludeFilterReg), proxiesOnUpdate(pd))
+	fetcher.OnFetch = pd.recordSubscription
 	pd.Fetcher = fetcher
 
 	wrapper := &ProxySetProvider{pd}
@@ -166,6 +198,22 @@ func (cp *compatibleProvider) HealthCheck() {
 	cp.healthCheck.check()
 }
 
+func (cp *compatibleProvider) HealthCheckOption() (string, utils.IntRanges[uint16]) {
+	return cp.healthCheck.Option()
+}
+
+func (cp *compatibleProvider) SetHealthCheckOption(url string, expectedStatus utils.IntRanges[uint16]) {
+	cp.healthCheck.SetOption(url, expectedStatus)
+}
+
+func (cp *compatibleProvider) HealthCheckLazy() bool {
+	return cp.healthCheck.Lazy()
+}
+
+func (cp *compatibleProvider) SetHealthCheckLazy(lazy bool) {
+	cp.healthCheck.SetLazy(lazy)
+}
+
 func (cp *compatibleProvider) Update() error {
 	return nil
 }
@@ -221,7 +269,7 @@ func proxiesOnUpdate(pd *proxySetProvider) func([]C.Proxy) {
 	}
 }
 
-func proxiesParseAndFilter(filter string, excludeFilter string, filterRegs []*regexp2.Regexp, excludeFilterReg *regexp2.Regexp) resource.Parser[[]C.Proxy] {
+func proxiesParseAndFilter(filter string, excludeFilter string, nameFormat string, filterRegs []*regexp2.Regexp, excludeFilterReg *regexp2.Regexp) resource.Parser[[]C.Proxy] {
 	return func(buf []byte) ([]C.Proxy, error) {
 		schema := &ProxySchema{}
 
@@ -239,6 +287,7 @@ func proxiesParseAndFilter(filter string, excludeFilter string, filterRegs []*re
 
 		proxies := []C.Proxy{}
 		proxiesSet := map[string]struct{}{}
+		namesSet := map[string]struct{}{}
 		for _, filterReg := range filterRegs {
 			for idx, mapping := range schema.Proxies {
 				mName, ok := mapping["name"]
@@ -262,11 +311,17 @@ func proxiesParseAndFilter(filter string, excludeFilter string, filterRegs []*re
 				if _, ok := proxiesSet[name]; ok {
 					continue
 				}
+				proxiesSet[name] = struct{}{}
+				if nameFormat != "" {
+					var err error
+					if mapping, err = renameProxy(mapping, nameFormat, namesSet); err != nil {
+						return nil, fmt.Errorf("proxy %d error: %w", idx, err)
+					}
+				}
 				proxy, err := adapter.ParseProxy(mapping)
 				if err != nil {
 					return nil, fmt.Errorf("proxy %d error: %w", idx, err)
 				}
-				proxiesSet[name] = struct{}{}
 				proxies = append(proxies, proxy)
 			}
 		}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dreamacro/clash/component/profile/cachefile"
+)
+
+// parseSubscriptionUserInfo reads a header like
+// "upload=455727941; download=6174315083; total=1073741824000; expire=1671815872",
+// unknown or malformed fields are skipped
+func parseSubscriptionUserInfo(header string, record *cachefile.SubscriptionRecord) {
+	for _, field := range strings.Split(header, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			continue
+		}
+
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "upload":
+			record.Upload = n
+		case "download":
+			record.Download = n
+		case "total":
+			record.Total = n
+		case "expire":
+			record.Expire = n
+		}
+	}
+}
+
+// recordSubscription appends the node count and the traffic reported by the
+// subscription to the history of a provider downloaded over http, a load from
+// the local cache or a download without subscription-userinfo is not recorded
+func (pp *proxySetProvider) recordSubscription() {
+	vehicle, ok := pp.Vehicle().(interface{ SubscriptionUserInfo() string })
+	if !ok {
+		return
+	}
+	userInfo := vehicle.SubscriptionUserInfo()
+	if userInfo == "" {
+		return
+	}
+
+	record := cachefile.SubscriptionRecord{
+		Time:  time.Now(),
+		Nodes: len(pp.Proxies()),
+	}
+	parseSubscriptionUserInfo(userInfo, &record)
+	cachefile.Cache().AppendSubscription(pp.Name(), record)
+}
@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/Dreamacro/clash/component/profile/cachefile"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSubscriptionUserInfo(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   cachefile.SubscriptionRecord
+	}{
+		{
+			name:   "full",
+			header: "upload=455727941; download=6174315083; total=1073741824000; expire=1671815872",
+			want:   cachefile.SubscriptionRecord{Upload: 455727941, Download: 6174315083, Total: 1073741824000, Expire: 1671815872},
+		},
+		{
+			name:   "no spaces and mixed case",
+			header: "Upload=1;DOWNLOAD=2;total=3",
+			want:   cachefile.SubscriptionRecord{Upload: 1, Download: 2, Total: 3},
+		},
+		{
+			name:   "malformed and unknown fields",
+			header: "upload=abc; download; total=10; foo=5; expire=",
+			want:   cachefile.SubscriptionRecord{Total: 10},
+		},
+		{
+			name:   "empty",
+			header: "",
+			want:   cachefile.SubscriptionRecord{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var record cachefile.SubscriptionRecord
+			parseSubscriptionUserInfo(tt.header, &record)
+			assert.Equal(t, tt.want, record)
+		})
+	}
+}
@@ -188,6 +188,18 @@ func (c *LruCache[K, V]) get(key K) *entry[K, V] {
 	return el
 }
 
+// Oldest returns the least recently used element without moving it
+func (c *LruCache[K, V]) Oldest() (K, V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	le := c.lru.Front()
+	if le == nil {
+		return getZero[K](), getZero[V](), false
+	}
+	return le.Value.key, le.Value.value, true
+}
+
 // Delete removes the value associated with a key.
 func (c *LruCache[K, V]) Delete(key K) {
 	c.mu.Lock()
@@ -221,6 +233,7 @@ func (c *LruCache[K, V]) Clear() error {
 	c.mu.Lock()
 
 	c.cache = make(map[K]*list.Element[*entry[K, V]])
+	c.lru = list.New[*entry[K, V]]()
 
 	c.mu.Unlock()
 	return nil
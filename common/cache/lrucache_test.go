@@ -124,6 +124,25 @@ func TestExist(t *testing.T) {
 	assert.False(t, c.Exist(1))
 }
 
+func TestOldest(t *testing.T) {
+	c := NewLRUCache[int, int]()
+	_, _, ok := c.Oldest()
+	assert.False(t, ok)
+
+	c.Set(1, 2)
+	c.Set(2, 3)
+	c.Get(1)
+
+	key, value, ok := c.Oldest()
+	assert.True(t, ok)
+	assert.Equal(t, 2, key)
+	assert.Equal(t, 3, value)
+
+	_ = c.Clear()
+	_, _, ok = c.Oldest()
+	assert.False(t, ok)
+}
+
 func TestEvict(t *testing.T) {
 	temp := 0
 	evict := func(key int, value int) {
@@ -20,6 +20,52 @@ func DecodeBase64(buf []byte) []byte {
 	return result
 }
 
+// decodeBase64Lines is like DecodeBase64 but also handles several base64
+// blobs or a mix of blobs and plaintext lines, each line is decoded on its own
+func decodeBase64Lines(buf []byte) []byte {
+	lines := strings.Split(string(buf), "\n")
+	decoded := make([]string, 0, len(lines))
+	separate := true
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if !strings.Contains(line, "://") {
+			if data, err := tryDecodeBase64([]byte(line)); err == nil {
+				line = string(data)
+			}
+		}
+		separate = separate && hasScheme(line)
+		decoded = append(decoded, line)
+	}
+
+	// the decoder skips newlines, decoding the whole buffer would glue
+	// separate blobs together, so it's only for one blob, maybe wrapped
+	if !separate || len(decoded) <= 1 {
+		if result, err := tryDecodeBase64(buf); err == nil {
+			return result
+		}
+	}
+	return []byte(strings.Join(decoded, "\n"))
+}
+
+// hasScheme reports whether line starts with a uri scheme, which a line of a
+// wrapped blob rarely does
+func hasScheme(line string) bool {
+	scheme, _, found := strings.Cut(line, "://")
+	if !found || scheme == "" {
+		return false
+	}
+	for _, c := range scheme {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '+' || c == '-' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
 func tryDecodeBase64(buf []byte) ([]byte, error) {
 	dBuf := make([]byte, encRaw.DecodedLen(len(buf)))
 	n, err := encRaw.Decode(dBuf, buf)
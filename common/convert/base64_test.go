@@ -0,0 +1,37 @@
+package convert
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeBase64Lines(t *testing.T) {
+	b64 := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+	raw := func(s string) string { return base64.RawStdEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name string
+		buf  string
+		want string
+	}{
+		{name: "one blob", buf: b64("ss://a\nvmess://b"), want: "ss://a\nvmess://b"},
+		{name: "raw blob", buf: raw("trojan://c"), want: "trojan://c"},
+		{name: "plaintext", buf: "ss://a\nvmess://b", want: "ss://a\nvmess://b"},
+		{name: "several blobs", buf: b64("ss://a") + "\n" + raw("ss://b\nss://c"), want: "ss://a\nss://b\nss://c"},
+		{name: "mixed", buf: "vless://d\n" + b64("ss://a") + "\n", want: "vless://d\nss://a"},
+		{name: "blank lines and crlf", buf: "\r\n" + b64("ss://a") + "\r\n\r\nss://b\r\n", want: "ss://a\nss://b"},
+		{name: "link kept as is", buf: "ss://YWVzLTEyOC1nY206cGFzcw==@1.2.3.4:8388\n" + b64("ss://a"), want: "ss://YWVzLTEyOC1nY206cGFzcw==@1.2.3.4:8388\nss://a"},
+		{name: "undecodable line kept", buf: "not base64!\n" + b64("ss://a"), want: "not base64!\nss://a"},
+		{name: "wrapped blob", buf: b64("ss://aaaaaa\nss://bbbbbb")[:12] + "\n" + b64("ss://aaaaaa\nss://bbbbbb")[12:24] + "\n" + b64("ss://aaaaaa\nss://bbbbbb")[24:], want: "ss://aaaaaa\nss://bbbbbb"},
+		{name: "unpadded blobs", buf: b64("ss://a") + "\n" + b64("ss://b"), want: "ss://a\nss://b"},
+		{name: "empty", buf: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, string(decodeBase64Lines([]byte(tt.buf))))
+		})
+	}
+}
@@ -11,7 +11,7 @@ import (
 
 // ConvertsV2Ray convert V2Ray subscribe proxies data to clash proxies config
 func ConvertsV2Ray(buf []byte) ([]map[string]any, error) {
-	data := DecodeBase64(buf)
+	data := decodeBase64Lines(buf)
 
 	arr := strings.Split(string(data), "\n")
 
@@ -2,6 +2,8 @@ package net
 
 import (
 	"bufio"
+	"bytes"
+	"io"
 	"net"
 )
 
@@ -22,6 +24,19 @@ func (c *BufferedConn) Reader() *bufio.Reader {
 	return c.r
 }
 
+// Grow makes the buffer hold at least size bytes so a Peek(size) can succeed,
+// the data already buffered is kept.
+func (c *BufferedConn) Grow(size int) {
+	if c.r.Size() >= size {
+		return
+	}
+
+	buffered, _ := c.r.Peek(c.r.Buffered())
+	remain := make([]byte, len(buffered))
+	copy(remain, buffered)
+	c.r = bufio.NewReaderSize(io.MultiReader(bytes.NewReader(remain), c.Conn), size)
+}
+
 // Peek returns the next n bytes without advancing the reader.
 func (c *BufferedConn) Peek(n int) ([]byte, error) {
 	return c.r.Peek(n)
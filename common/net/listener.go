@@ -0,0 +1,144 @@
+package net
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// ListenOption tunes an accepting listener, zero values keep the system defaults
+type ListenOption struct {
+	// Backlog is the size of the pending connection queue
+	Backlog int
+	// AcceptRate is the number of connections accepted per second
+	AcceptRate int
+	// AcceptBurst is the number of connections accepted at once before AcceptRate applies
+	AcceptBurst int
+	// MaxConnections is the number of accepted connections open at once, Accept waits for one to close beyond it.
+	// Every listener wrapped by Apply counts its own connections
+	MaxConnections int
+}
+
+// Apply sets the backlog of l and wraps it with the connection and accept rate limits
+func (o ListenOption) Apply(l net.Listener) net.Listener {
+	if o.Backlog > 0 {
+		_ = setListenBacklog(l, o.Backlog)
+	}
+
+	if o.MaxConnections > 0 {
+		l = &limitedListener{
+			Listener: l,
+			slots:    make(chan struct{}, o.MaxConnections),
+			closed:   make(chan struct{}),
+		}
+	}
+
+	if o.AcceptRate <= 0 {
+		return l
+	}
+
+	burst := o.AcceptBurst
+	if burst < 1 {
+		burst = 1
+	}
+
+	interval := time.Second / time.Duration(o.AcceptRate)
+	return &rateLimitedListener{
+		Listener:  l,
+		interval:  interval,
+		tolerance: time.Duration(burst-1) * interval,
+	}
+}
+
+// rateLimitedListener delays Accept so that pending connections
+// wait in the backlog instead of flooding the handlers
+type rateLimitedListener struct {
+	net.Listener
+	interval  time.Duration
+	tolerance time.Duration
+
+	mux sync.Mutex
+	tat time.Time
+}
+
+func (l *rateLimitedListener) Accept() (net.Conn, error) {
+	l.mux.Lock()
+	now := time.Now()
+	if l.tat.Before(now) {
+		l.tat = now
+	}
+	wait := l.tat.Add(-l.tolerance).Sub(now)
+	l.tat = l.tat.Add(l.interval)
+	l.mux.Unlock()
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+
+	return l.Listener.Accept()
+}
+
+// limitedListener stops accepting while MaxConnections accepted connections are open,
+// so the new ones wait in the backlog instead of piling up in memory
+type limitedListener struct {
+	net.Listener
+	slots chan struct{}
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (l *limitedListener) Accept() (net.Conn, error) {
+	select {
+	case l.slots <- struct{}{}:
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		<-l.slots
+		return nil, err
+	}
+	// the inbounds only turn on keepalive for a bare *net.TCPConn, do it before wrapping
+	if c, ok := conn.(*net.TCPConn); ok {
+		_ = c.SetKeepAlive(true)
+	}
+	return &limitedConn{Conn: conn, slots: l.slots}, nil
+}
+
+func (l *limitedListener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.closed)
+	})
+	return l.Listener.Close()
+}
+
+// limitedConn frees its slot of the listener once closed
+type limitedConn struct {
+	net.Conn
+	slots     chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *limitedConn) Close() error {
+	c.closeOnce.Do(func() {
+		<-c.slots
+	})
+	return c.Conn.Close()
+}
+
+// ReadFrom keeps the splice and sendfile paths of the wrapped conn
+func (c *limitedConn) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(c.Conn, r)
+}
+
+// WriteTo lets a writer splice from the wrapped conn
+func (c *limitedConn) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, c.Conn)
+}
+
+func (c *limitedConn) Upstream() any {
+	return c.Conn
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package net
+
+import "net"
+
+func setListenBacklog(l net.Listener, backlog int) error {
+	return nil
+}
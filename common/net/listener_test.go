@@ -0,0 +1,154 @@
+package net
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func listen(t *testing.T, opt ListenOption) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l = opt.Apply(l)
+	t.Cleanup(func() { _ = l.Close() })
+	return l
+}
+
+func dial(t *testing.T, l net.Listener) net.Conn {
+	c, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+// acceptAsync accepts one connection in the background
+func acceptAsync(l net.Listener) chan net.Conn {
+	ch := make(chan net.Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			close(ch)
+			return
+		}
+		ch <- conn
+	}()
+	return ch
+}
+
+func TestLimitedListener_AcceptLimit(t *testing.T) {
+	l := listen(t, ListenOption{MaxConnections: 1})
+	dial(t, l)
+	dial(t, l)
+
+	first, err := l.Accept()
+	assert.NoError(t, err)
+
+	second := acceptAsync(l)
+	select {
+	case <-second:
+		t.Fatal("accepted over MaxConnections")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// closing frees the slot, closing twice must not free another one
+	assert.NoError(t, first.Close())
+	_ = first.Close()
+	select {
+	case conn, ok := <-second:
+		assert.True(t, ok)
+		_ = conn.Close()
+	case <-time.After(time.Second):
+		t.Fatal("slot not released on Close")
+	}
+}
+
+func TestLimitedListener_CloseUnblocksAccept(t *testing.T) {
+	l := listen(t, ListenOption{MaxConnections: 1})
+	dial(t, l)
+
+	conn, err := l.Accept()
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := l.Accept()
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, l.Close())
+
+	select {
+	case err := <-done:
+		assert.True(t, errors.Is(err, net.ErrClosed))
+	case <-time.After(time.Second):
+		t.Fatal("Accept still waiting after Close")
+	}
+}
+
+func TestLimitedConn_Upstream(t *testing.T) {
+	l := listen(t, ListenOption{MaxConnections: 1})
+	dial(t, l)
+
+	conn, err := l.Accept()
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	upstream, ok := conn.(interface{ Upstream() any })
+	if assert.True(t, ok) {
+		_, ok = upstream.Upstream().(*net.TCPConn)
+		assert.True(t, ok)
+	}
+}
+
+func TestRateLimitedListener(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    int
+		burst   int
+		accepts int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{name: "no burst", rate: 20, burst: 0, accepts: 4, min: 140 * time.Millisecond, max: time.Second},
+		{name: "within burst", rate: 20, burst: 4, accepts: 4, min: 0, max: 100 * time.Millisecond},
+		{name: "over burst", rate: 20, burst: 2, accepts: 4, min: 90 * time.Millisecond, max: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := listen(t, ListenOption{AcceptRate: tt.rate, AcceptBurst: tt.burst})
+			for i := 0; i < tt.accepts; i++ {
+				dial(t, l)
+			}
+
+			start := time.Now()
+			for i := 0; i < tt.accepts; i++ {
+				conn, err := l.Accept()
+				if !assert.NoError(t, err) {
+					return
+				}
+				_ = conn.Close()
+			}
+			elapsed := time.Since(start)
+			assert.GreaterOrEqual(t, elapsed, tt.min)
+			assert.Less(t, elapsed, tt.max)
+		})
+	}
+}
+
+func TestListenOption_ApplyDefault(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	assert.Equal(t, l, ListenOption{}.Apply(l))
+}
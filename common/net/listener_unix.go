@@ -0,0 +1,33 @@
+//go:build unix
+
+package net
+
+import (
+	"net"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// setListenBacklog calls listen(2) again on the listening socket, which updates the backlog in place
+func setListenBacklog(l net.Listener, backlog int) error {
+	sc, ok := l.(syscall.Conn)
+	if !ok {
+		return nil
+	}
+
+	rc, err := sc.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var innerErr error
+	err = rc.Control(func(fd uintptr) {
+		innerErr = unix.Listen(int(fd), backlog)
+	})
+	if innerErr != nil {
+		err = innerErr
+	}
+
+	return err
+}
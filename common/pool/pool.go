@@ -10,6 +10,10 @@ const (
 	// request 32Kib. Most UDPs are smaller than the MTU, and the TUN's MTU
 	// set to 9000, so the UDP Buffer size set to 16Kib
 	UDPBufferSize = 16 * 1024
+
+	// MaxUDPBufferSize fits any UDP datagram, used for the remote to local
+	// direction where a response (e.g. DNSSEC) may exceed UDPBufferSize
+	MaxUDPBufferSize = 64 * 1024
 )
 
 func Get(size int) []byte {
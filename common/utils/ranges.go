@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/exp/constraints"
+)
+
+// IntRanges is a list of closed ranges, an empty list contains every value
+type IntRanges[T constraints.Integer] []Range[T]
+
+// NewIntRanges parses values and ranges separated by "/", like "200/204/300-399"
+func NewIntRanges[T constraints.Integer](expected string) (IntRanges[T], error) {
+	expected = strings.TrimSpace(expected)
+	if expected == "" || expected == "*" {
+		return nil, nil
+	}
+
+	var ranges IntRanges[T]
+	for _, part := range strings.Split(expected, "/") {
+		startStr, endStr, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		start, err := parseInt[T](strings.TrimSpace(startStr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %s: %w", part, err)
+		}
+
+		end := start
+		if isRange {
+			end, err = parseInt[T](strings.TrimSpace(endStr))
+			if err != nil {
+				return nil, fmt.Errorf("invalid range %s: %w", part, err)
+			}
+		}
+
+		ranges = append(ranges, *NewRange(start, end))
+	}
+	return ranges, nil
+}
+
+// parseInt parses s with the sign and bit size of T, so out of range values fail instead of wrapping
+func parseInt[T constraints.Integer](s string) (T, error) {
+	bitSize := int(unsafe.Sizeof(T(0))) * 8
+	if ^T(0) < 0 {
+		v, err := strconv.ParseInt(s, 10, bitSize)
+		return T(v), err
+	}
+	v, err := strconv.ParseUint(s, 10, bitSize)
+	return T(v), err
+}
+
+func (ranges IntRanges[T]) Check(status T) bool {
+	if len(ranges) == 0 {
+		return true
+	}
+
+	for _, r := range ranges {
+		if r.Contains(status) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ranges IntRanges[T]) String() string {
+	if len(ranges) == 0 {
+		return "*"
+	}
+
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		if r.Start() == r.End() {
+			parts[i] = strconv.FormatInt(int64(r.Start()), 10)
+		} else {
+			parts[i] = fmt.Sprintf("%d-%d", r.Start(), r.End())
+		}
+	}
+	return strings.Join(parts, "/")
+}
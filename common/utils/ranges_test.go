@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestIntRanges(t *testing.T) {
+	ranges, err := NewIntRanges[uint16]("204/300-399")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for status, want := range map[uint16]bool{200: false, 204: true, 300: true, 399: true, 404: false} {
+		if got := ranges.Check(status); got != want {
+			t.Errorf("Check(%d) = %v, want %v", status, got, want)
+		}
+	}
+
+	if got := ranges.String(); got != "204/300-399" {
+		t.Errorf("String() = %s", got)
+	}
+
+	if all, _ := NewIntRanges[uint16](""); !all.Check(500) || all.String() != "*" {
+		t.Error("empty ranges should accept any status")
+	}
+
+	if _, err := NewIntRanges[uint16]("2xx"); err == nil {
+		t.Error("invalid ranges should fail")
+	}
+}
+
+func TestIntRanges_OutOfRange(t *testing.T) {
+	for _, expected := range []string{"65536", "200-70000", "-1"} {
+		if _, err := NewIntRanges[uint16](expected); err == nil {
+			t.Errorf("%s should fail for uint16", expected)
+		}
+	}
+
+	if _, err := NewIntRanges[int8]("128"); err == nil {
+		t.Error("128 should fail for int8")
+	}
+
+	ranges, err := NewIntRanges[int8]("0-127")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ranges.Check(0) || !ranges.Check(127) {
+		t.Errorf("ranges = %s", ranges)
+	}
+}
@@ -0,0 +1,29 @@
+package dialer
+
+import (
+	"net"
+	"syscall"
+)
+
+func socketBufferToDialer(dialer *net.Dialer, send, receive int) {
+	dialer.Control = socketBufferControl(send, receive, dialer.Control)
+}
+
+func socketBufferToListenConfig(lc *net.ListenConfig, send, receive int) {
+	lc.Control = socketBufferControl(send, receive, lc.Control)
+}
+
+// socketBufferControl sets SO_SNDBUF and SO_RCVBUF before connecting, the kernel may cap them (net.core.wmem_max and rmem_max on Linux)
+func socketBufferControl(send, receive int, chain func(network, address string, c syscall.RawConn) error) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) (err error) {
+		defer func() {
+			if err == nil && chain != nil {
+				err = chain(network, address, c)
+			}
+		}()
+
+		return c.Control(func(fd uintptr) {
+			setSocketBuffer(fd, send, receive)
+		})
+	}
+}
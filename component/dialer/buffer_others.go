@@ -0,0 +1,5 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+
+package dialer
+
+func setSocketBuffer(uintptr, int, int) {}
@@ -0,0 +1,16 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package dialer
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+func setSocketBuffer(fd uintptr, send, receive int) {
+	if send > 0 {
+		_ = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_SNDBUF, send)
+	}
+	if receive > 0 {
+		_ = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_RCVBUF, receive)
+	}
+}
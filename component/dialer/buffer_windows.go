@@ -0,0 +1,14 @@
+package dialer
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+func setSocketBuffer(fd uintptr, send, receive int) {
+	if send > 0 {
+		_ = windows.SetsockoptInt(windows.Handle(fd), windows.SOL_SOCKET, windows.SO_SNDBUF, send)
+	}
+	if receive > 0 {
+		_ = windows.SetsockoptInt(windows.Handle(fd), windows.SOL_SOCKET, windows.SO_RCVBUF, receive)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/Dreamacro/clash/component/loopback"
 	"github.com/Dreamacro/clash/component/resolver"
 	"go.uber.org/atomic"
 	"net"
@@ -46,14 +47,22 @@ func DialContext(ctx context.Context, network, address string, options ...Option
 		network = fmt.Sprintf("%s%d", network, opt.network)
 	}
 
+	var (
+		conn net.Conn
+		err  error
+	)
 	switch network {
 	case "tcp4", "tcp6", "udp4", "udp6":
-		return actualSingleDialContext(ctx, network, address, opt)
+		conn, err = actualSingleDialContext(ctx, network, address, opt)
 	case "tcp", "udp":
-		return actualDualStackDialContext(ctx, network, address, opt)
+		conn, err = actualDualStackDialContext(ctx, network, address, opt)
 	default:
 		return nil, ErrorInvalidedNetworkStack
 	}
+	if err == nil {
+		loopback.Register(conn.LocalAddr())
+	}
+	return conn, err
 }
 
 func ListenPacket(ctx context.Context, network, address string, options ...Option) (net.PacketConn, error) {
@@ -84,8 +93,18 @@ func ListenPacket(ctx context.Context, network, address string, options ...Optio
 	if cfg.routingMark != 0 {
 		bindMarkToListenConfig(cfg.routingMark, lc, network, address)
 	}
+	if cfg.sendBuffer > 0 || cfg.receiveBuffer > 0 {
+		socketBufferToListenConfig(lc, cfg.sendBuffer, cfg.receiveBuffer)
+	}
+	if cfg.dscpSet {
+		dscpToListenConfig(lc, cfg.dscp)
+	}
 
-	return lc.ListenPacket(ctx, network, address)
+	pc, err := lc.ListenPacket(ctx, network, address)
+	if err == nil {
+		loopback.Register(pc.LocalAddr())
+	}
+	return pc, err
 }
 
 func SetDial(concurrent bool) {
@@ -116,11 +135,21 @@ func dialContext(ctx context.Context, network string, destination netip.Addr, po
 	if opt.routingMark != 0 {
 		bindMarkToDialer(opt.routingMark, dialer, network, destination)
 	}
+	if opt.sendBuffer > 0 || opt.receiveBuffer > 0 {
+		socketBufferToDialer(dialer, opt.sendBuffer, opt.receiveBuffer)
+	}
+	if opt.dscpSet {
+		dscpToDialer(dialer, opt.dscp)
+	}
 
 	if DisableIPv6 && destination.Is6() {
 		return nil, ErrorDisableIPv6
 	}
 
+	if opt.mptcp && strings.HasPrefix(network, "tcp") {
+		return dialMPTCP(ctx, dialer, network, destination, port)
+	}
+
 	return dialer.DialContext(ctx, network, net.JoinHostPort(destination.String(), port))
 }
 
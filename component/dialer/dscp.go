@@ -0,0 +1,29 @@
+package dialer
+
+import (
+	"net"
+	"syscall"
+)
+
+func dscpToDialer(dialer *net.Dialer, dscp int) {
+	dialer.Control = dscpControl(dscp, dialer.Control)
+}
+
+func dscpToListenConfig(lc *net.ListenConfig, dscp int) {
+	lc.Control = dscpControl(dscp, lc.Control)
+}
+
+// dscpControl writes dscp to the upper 6 bits of the traffic class byte, the ECN bits are left to the kernel
+func dscpControl(dscp int, chain func(network, address string, c syscall.RawConn) error) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) (err error) {
+		defer func() {
+			if err == nil && chain != nil {
+				err = chain(network, address, c)
+			}
+		}()
+
+		return c.Control(func(fd uintptr) {
+			setDSCP(fd, network, dscp<<2)
+		})
+	}
+}
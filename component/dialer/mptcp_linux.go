@@ -0,0 +1,130 @@
+//go:build linux
+
+package dialer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// ipProtoMPTCP is IPPROTO_MPTCP, available since Linux 5.6
+const ipProtoMPTCP = 262
+
+var aLongTimeAgo = time.Unix(1, 0)
+
+func dialMPTCP(ctx context.Context, dialer *net.Dialer, network string, destination netip.Addr, port string) (net.Conn, error) {
+	address := net.JoinHostPort(destination.String(), port)
+	portNum, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, err
+	}
+
+	raddr := net.TCPAddrFromAddrPort(netip.AddrPortFrom(destination, uint16(portNum)))
+
+	family := unix.AF_INET6
+	var sa unix.Sockaddr
+	if network == "tcp4" {
+		family = unix.AF_INET
+		sa = &unix.SockaddrInet4{Port: int(portNum), Addr: destination.Unmap().As4()}
+	} else {
+		sa6 := &unix.SockaddrInet6{Port: int(portNum), Addr: destination.As16()}
+		if zone := destination.Zone(); zone != "" {
+			if iface, err := net.InterfaceByName(zone); err == nil {
+				sa6.ZoneId = uint32(iface.Index)
+			}
+		}
+		sa = sa6
+	}
+
+	fd, err := unix.Socket(family, unix.SOCK_STREAM|unix.SOCK_NONBLOCK|unix.SOCK_CLOEXEC, ipProtoMPTCP)
+	if err != nil {
+		// kernel without MPTCP or net.mptcp.enabled=0, use plain TCP
+		if errors.Is(err, unix.EPROTONOSUPPORT) || errors.Is(err, unix.ENOPROTOOPT) || errors.Is(err, unix.EINVAL) {
+			return dialer.DialContext(ctx, network, address)
+		}
+		return nil, os.NewSyscallError("socket", err)
+	}
+
+	// os.NewFile registers the non-blocking fd with the runtime poller
+	f := os.NewFile(uintptr(fd), "mptcp")
+	defer f.Close()
+
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	if dialer.Control != nil {
+		if err := dialer.Control(network, address, rc); err != nil {
+			return nil, err
+		}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = f.SetWriteDeadline(deadline)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = f.SetWriteDeadline(aLongTimeAgo)
+		case <-done:
+		}
+	}()
+
+	if err := unix.Connect(fd, sa); err != nil && !errors.Is(err, unix.EINPROGRESS) {
+		return nil, &net.OpError{Op: "dial", Net: network, Addr: raddr, Err: os.NewSyscallError("connect", err)}
+	}
+
+	var connectErr error
+	polled := false
+	err = rc.Write(func(fd uintptr) bool {
+		// wait for the first writable event before asking for the result
+		if !polled {
+			polled = true
+			return false
+		}
+
+		soErr, err := unix.GetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_ERROR)
+		if err != nil {
+			connectErr = os.NewSyscallError("getsockopt", err)
+			return true
+		}
+
+		switch errno := unix.Errno(soErr); errno {
+		case unix.EINPROGRESS, unix.EALREADY, unix.EINTR:
+			return false
+		case 0:
+			if _, err := unix.Getpeername(int(fd)); err != nil {
+				return false
+			}
+			return true
+		default:
+			connectErr = os.NewSyscallError("connect", errno)
+			return true
+		}
+	})
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+		connectErr = err
+	}
+	if connectErr != nil {
+		return nil, &net.OpError{Op: "dial", Net: network, Addr: raddr, Err: connectErr}
+	}
+
+	_ = f.SetWriteDeadline(time.Time{})
+
+	// FileConn dups the fd, the original one is closed by the deferred f.Close
+	return net.FileConn(f)
+}
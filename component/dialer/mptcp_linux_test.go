@@ -0,0 +1,117 @@
+//go:build linux
+
+package dialer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// echoServer accepts on network and echoes every connection
+func echoServer(t *testing.T, network, address string) netip.AddrPort {
+	l, err := net.Listen(network, address)
+	if err != nil {
+		t.Skipf("listen %s %s: %v", network, address, err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				_, _ = io.Copy(c, c)
+			}()
+		}
+	}()
+	return l.Addr().(*net.TCPAddr).AddrPort()
+}
+
+func TestDialMPTCP(t *testing.T) {
+	tests := []struct {
+		name    string
+		network string
+		address string
+	}{
+		{name: "ipv4", network: "tcp4", address: "127.0.0.1:0"},
+		{name: "ipv6", network: "tcp6", address: "[::1]:0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := echoServer(t, tt.network, tt.address)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			conn, err := dialMPTCP(ctx, &net.Dialer{}, tt.network, addr.Addr(), strconv.Itoa(int(addr.Port())))
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer conn.Close()
+			assert.Equal(t, addr, conn.RemoteAddr().(*net.TCPAddr).AddrPort())
+
+			_, err = conn.Write([]byte("ping"))
+			assert.NoError(t, err)
+			buf := make([]byte, 4)
+			_, err = io.ReadFull(conn, buf)
+			assert.NoError(t, err)
+			assert.Equal(t, "ping", string(buf))
+		})
+	}
+}
+
+func TestDialMPTCP_Errors(t *testing.T) {
+	// a port nobody listens on
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := l.Addr().(*net.TCPAddr).AddrPort()
+	_ = l.Close()
+
+	errControl := errors.New("control failed")
+	tests := []struct {
+		name    string
+		dialer  *net.Dialer
+		port    string
+		wantErr error
+	}{
+		{name: "refused", dialer: &net.Dialer{}, port: strconv.Itoa(int(closed.Port())), wantErr: syscall.ECONNREFUSED},
+		{name: "invalid port", dialer: &net.Dialer{}, port: "http"},
+		{
+			name: "control error",
+			dialer: &net.Dialer{Control: func(network, address string, c syscall.RawConn) error {
+				return errControl
+			}},
+			port:    strconv.Itoa(int(closed.Port())),
+			wantErr: errControl,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			conn, err := dialMPTCP(ctx, tt.dialer, "tcp4", closed.Addr(), tt.port)
+			if conn != nil {
+				_ = conn.Close()
+			}
+			assert.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
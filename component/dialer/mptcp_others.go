@@ -0,0 +1,22 @@
+//go:build !linux
+
+package dialer
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"sync"
+
+	"github.com/Dreamacro/clash/log"
+)
+
+var printMPTCPWarnOnce sync.Once
+
+func dialMPTCP(ctx context.Context, dialer *net.Dialer, network string, destination netip.Addr, port string) (net.Conn, error) {
+	printMPTCPWarnOnce.Do(func() {
+		log.Warnln("Multipath TCP is not supported on current platform, fallback to TCP")
+	})
+
+	return dialer.DialContext(ctx, network, net.JoinHostPort(destination.String(), port))
+}
@@ -17,6 +17,12 @@ type option struct {
 	direct        bool
 	network       int
 	prefer        int
+	mptcp         bool
+	sendBuffer    int
+	receiveBuffer int
+	dscp          int
+	// dscpSet tells a dscp of 0 apart from no marking
+	dscpSet bool
 }
 
 type Option func(opt *option)
@@ -39,6 +45,28 @@ func WithRoutingMark(mark int) Option {
 	}
 }
 
+func WithMPTCP(mptcp bool) Option {
+	return func(opt *option) {
+		opt.mptcp = mptcp
+	}
+}
+
+// WithSocketBuffer sets SO_SNDBUF and SO_RCVBUF of the socket, 0 keeps the system default
+func WithSocketBuffer(send, receive int) Option {
+	return func(opt *option) {
+		opt.sendBuffer = send
+		opt.receiveBuffer = receive
+	}
+}
+
+// WithDSCP marks the packets of the socket with dscp in IP_TOS or IPV6_TCLASS, without it the system default is kept
+func WithDSCP(dscp int) Option {
+	return func(opt *option) {
+		opt.dscp = dscp
+		opt.dscpSet = true
+	}
+}
+
 func WithDirect() Option {
 	return func(opt *option) {
 		opt.direct = true
@@ -56,6 +56,12 @@ func (m *memoryStore) Exist(ip netip.Addr) bool {
 	return m.cacheHost.Exist(ip)
 }
 
+// oldestIP returns the fake ip whose mapping was used the longest time ago
+func (m *memoryStore) oldestIP() (netip.Addr, bool) {
+	ip, _, ok := m.cacheHost.Oldest()
+	return ip, ok
+}
+
 // CloneTo implements store.CloneTo
 // only for memoryStore to memoryStore
 func (m *memoryStore) CloneTo(store store) {
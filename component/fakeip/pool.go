@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"github.com/Dreamacro/clash/common/nnip"
+	"github.com/Dreamacro/clash/component/geodata/router"
 	"github.com/Dreamacro/clash/component/profile/cachefile"
 	"github.com/Dreamacro/clash/component/trie"
 )
@@ -33,9 +34,12 @@ type Pool struct {
 	last    netip.Addr
 	offset  netip.Addr
 	cycle   bool
+	lru     bool
 	mux     sync.Mutex
 	host    *trie.DomainTrie[bool]
+	geoSite []*router.DomainMatcher
 	ipnet   *netip.Prefix
+	ipnet6  *netip.Prefix
 	store   store
 }
 
@@ -52,8 +56,29 @@ func (p *Pool) Lookup(host string) netip.Addr {
 	return ip
 }
 
+// Lookup6 return the fake ip of host mapped into the ipv6 range, see FromIPv6
+func (p *Pool) Lookup6(host string) netip.Addr {
+	ip := p.Lookup(host).As16()
+	prefix := p.ipnet6.Addr().As16()
+	copy(prefix[12:], ip[12:])
+	return netip.AddrFrom16(prefix)
+}
+
+// FromIPv6 returns the fake ipv4 embedded in the last 32 bits of an address in the ipv6 range
+func (p *Pool) FromIPv6(ip netip.Addr) (netip.Addr, bool) {
+	if p.ipnet6 == nil || !ip.Is6() || !p.ipnet6.Contains(ip) {
+		return netip.Addr{}, false
+	}
+	b := ip.As16()
+	return netip.AddrFrom4([4]byte{b[12], b[13], b[14], b[15]}), true
+}
+
 // LookBack return host with the fake ip
 func (p *Pool) LookBack(ip netip.Addr) (string, bool) {
+	if v4, ok := p.FromIPv6(ip); ok {
+		ip = v4
+	}
+
 	p.mux.Lock()
 	defer p.mux.Unlock()
 
@@ -62,14 +87,23 @@ func (p *Pool) LookBack(ip netip.Addr) (string, bool) {
 
 // ShouldSkipped return if domain should be skipped
 func (p *Pool) ShouldSkipped(domain string) bool {
-	if p.host == nil {
-		return false
+	if p.host != nil && p.host.Search(domain) != nil {
+		return true
+	}
+	for _, matcher := range p.geoSite {
+		if matcher.ApplyDomain(domain) {
+			return true
+		}
 	}
-	return p.host.Search(domain) != nil
+	return false
 }
 
 // Exist returns if given ip exists in fake-ip pool
 func (p *Pool) Exist(ip netip.Addr) bool {
+	if v4, ok := p.FromIPv6(ip); ok {
+		ip = v4
+	}
+
 	p.mux.Lock()
 	defer p.mux.Unlock()
 
@@ -91,6 +125,11 @@ func (p *Pool) IPNet() *netip.Prefix {
 	return p.ipnet
 }
 
+// IPNet6 return the ipv6 range answering AAAA, nil when AAAA gets an empty answer
+func (p *Pool) IPNet6() *netip.Prefix {
+	return p.ipnet6
+}
+
 // CloneFrom clone cache from old pool
 func (p *Pool) CloneFrom(o *Pool) {
 	o.store.CloneTo(p.store)
@@ -104,12 +143,20 @@ func (p *Pool) get(host string) netip.Addr {
 		p.offset = p.first
 	}
 
-	if p.cycle || p.store.Exist(p.offset) {
-		p.store.DelByIP(p.offset)
+	ip := p.offset
+	// once the range is used up, take back the least recently used address instead of the next one
+	if ms, ok := p.store.(*memoryStore); ok && p.lru && p.cycle && ms.Exist(ip) {
+		if oldest, ok := ms.oldestIP(); ok {
+			ip = oldest
+		}
+	}
+
+	if p.cycle || p.store.Exist(ip) {
+		p.store.DelByIP(ip)
 	}
 
-	p.store.PutByIP(p.offset, host)
-	return p.offset
+	p.store.PutByIP(ip, host)
+	return ip
 }
 
 func (p *Pool) FlushFakeIP() error {
@@ -152,10 +199,20 @@ type Options struct {
 	IPNet *netip.Prefix
 	Host  *trie.DomainTrie[bool]
 
+	// GeoSite skips the domains of these geosite categories like Host
+	GeoSite []*router.DomainMatcher
+
+	// IPNet6 is an ipv6 range of /96 or larger, the fake ipv4 of a host fills its last 32 bits
+	IPNet6 *netip.Prefix
+
 	// Size sets the maximum number of entries in memory
 	// and does not work if Persistence is true
 	Size int
 
+	// LRU recycles the least recently used fake ip when the range is used up,
+	// instead of the next one in turn. It does not work if Persistence is true
+	LRU bool
+
 	// Persistence will save the data to disk.
 	// Size will not work and record will be fully stored.
 	Persistence bool
@@ -174,14 +231,25 @@ func New(options Options) (*Pool, error) {
 		return nil, errors.New("ipnet don't have valid ip")
 	}
 
+	if ipnet6 := options.IPNet6; ipnet6 != nil {
+		if !ipnet6.IsValid() || !ipnet6.Addr().Is6() || ipnet6.Addr().Is4In6() || ipnet6.Bits() > 96 {
+			return nil, errors.New("ipnet6 should be an ipv6 range of /96 or larger")
+		}
+		masked := ipnet6.Masked()
+		options.IPNet6 = &masked
+	}
+
 	pool := &Pool{
 		gateway: gateway,
 		first:   first,
 		last:    last,
 		offset:  first.Prev(),
 		cycle:   false,
+		lru:     options.LRU,
 		host:    options.Host,
+		geoSite: options.GeoSite,
 		ipnet:   options.IPNet,
+		ipnet6:  options.IPNet6,
 	}
 	if options.Persistence {
 		pool.store = &cachefileStore{
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Dreamacro/clash/component/geodata/router"
 	"github.com/Dreamacro/clash/component/profile/cachefile"
 	"github.com/Dreamacro/clash/component/trie"
 
@@ -126,6 +127,32 @@ func TestPool_CycleUsed(t *testing.T) {
 	}
 }
 
+func TestPool_CycleLRU(t *testing.T) {
+	ipnet := netip.MustParsePrefix("192.168.0.16/28")
+	pool, err := New(Options{
+		IPNet: &ipnet,
+		Size:  20,
+		LRU:   true,
+	})
+	assert.Nil(t, err)
+
+	foo := pool.Lookup("foo.com")
+	bar := pool.Lookup("bar.com")
+	for i := 0; i < 9; i++ {
+		pool.Lookup(fmt.Sprintf("%d.com", i))
+	}
+
+	// foo.com is used again, so bar.com is the least recently used one
+	assert.True(t, pool.Lookup("foo.com") == foo)
+	baz := pool.Lookup("baz.com")
+	assert.True(t, baz == bar)
+	assert.True(t, pool.Lookup("foo.com") == foo)
+
+	host, exist := pool.LookBack(bar)
+	assert.True(t, exist)
+	assert.Equal(t, "baz.com", host)
+}
+
 func TestPool_Skip(t *testing.T) {
 	ipnet := netip.MustParsePrefix("192.168.0.1/29")
 	tree := trie.New[bool]()
@@ -144,6 +171,26 @@ func TestPool_Skip(t *testing.T) {
 	}
 }
 
+func TestPool_SkipGeoSite(t *testing.T) {
+	ipnet := netip.MustParsePrefix("192.168.0.1/29")
+	matcher, err := router.NewDomainMatcher([]*router.Domain{
+		{Type: router.Domain_Domain, Value: "lan"},
+	}, false)
+	assert.Nil(t, err)
+	pools, tempfile, err := createPools(Options{
+		IPNet:   &ipnet,
+		Size:    10,
+		GeoSite: []*router.DomainMatcher{matcher},
+	})
+	assert.Nil(t, err)
+	defer os.Remove(tempfile)
+
+	for _, pool := range pools {
+		assert.True(t, pool.ShouldSkipped("router.lan"))
+		assert.False(t, pool.ShouldSkipped("example.com"))
+	}
+}
+
 func TestPool_MaxCacheSize(t *testing.T) {
 	ipnet := netip.MustParsePrefix("192.168.0.1/24")
 	pool, _ := New(Options{
@@ -281,3 +328,33 @@ func TestPool_FlushMemoryCache(t *testing.T) {
 	assert.False(t, bar == next)
 	assert.True(t, baz == nero)
 }
+
+func TestPool_IPv6(t *testing.T) {
+	ipnet := netip.MustParsePrefix("192.168.0.1/28")
+	ipnet6 := netip.MustParsePrefix("fdfe:dcba:9876::/96")
+	pool, err := New(Options{
+		IPNet:  &ipnet,
+		IPNet6: &ipnet6,
+		Size:   10,
+	})
+	assert.Nil(t, err)
+
+	ip6 := pool.Lookup6("foo.com")
+	assert.Equal(t, netip.MustParseAddr("fdfe:dcba:9876::c0a8:4"), ip6)
+
+	ip, ok := pool.FromIPv6(ip6)
+	assert.True(t, ok)
+	assert.Equal(t, pool.Lookup("foo.com"), ip)
+
+	host, exist := pool.LookBack(ip6)
+	assert.True(t, exist)
+	assert.Equal(t, "foo.com", host)
+	assert.True(t, pool.Exist(ip6))
+
+	_, ok = pool.FromIPv6(netip.MustParseAddr("2001:db8::c0a8:4"))
+	assert.False(t, ok)
+
+	invalid := netip.MustParsePrefix("fdfe:dcba:9876::/112")
+	_, err = New(Options{IPNet: &ipnet, IPNet6: &invalid})
+	assert.Error(t, err)
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"fmt"
 	"github.com/Dreamacro/clash/component/tls"
 	"github.com/Dreamacro/clash/listener/inner"
 	"io"
@@ -14,6 +15,8 @@ import (
 
 const (
 	UA = "Clash"
+
+	maxRedirects = 10
 )
 
 func HttpRequest(ctx context.Context, url, method string, header map[string][]string, body io.Reader) (*http.Response, error) {
@@ -52,13 +55,41 @@ func HttpRequest(ctx context.Context, url, method string, header map[string][]st
 		TLSHandshakeTimeout:   10 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
 		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
-			conn := inner.HandleTcp(address, urlRes.Hostname())
+			// take the host from address, a redirect may lead to another server than url
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return nil, err
+			}
+			conn := inner.HandleTcp(address, host)
 			return conn, nil
 		},
 		TLSClientConfig: tls.GetDefaultTLSConfig(),
 	}
 
-	client := http.Client{Transport: transport}
+	client := http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		},
+	}
 	return client.Do(req)
+}
 
+// PermanentlyMoved returns the final url of resp when every redirect that led
+// to it was permanent (301 or 308)
+func PermanentlyMoved(resp *http.Response) (string, bool) {
+	req := resp.Request
+	if req == nil || req.Response == nil {
+		return "", false
+	}
+
+	for r := req; r.Response != nil; r = r.Response.Request {
+		if code := r.Response.StatusCode; code != http.StatusMovedPermanently && code != http.StatusPermanentRedirect {
+			return "", false
+		}
+	}
+	return req.URL.String(), true
 }
@@ -24,6 +24,38 @@ var (
 var interfaces = singledo.NewSingle[map[string]*Interface](time.Second * 20)
 
 func ResolveInterface(name string) (*Interface, error) {
+	ifaces, err := allInterfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	iface, ok := ifaces[name]
+	if !ok {
+		return nil, ErrIfaceNotFound
+	}
+
+	return iface, nil
+}
+
+// IsInterfaceAddr reports whether ip is assigned to one of the interfaces of this host
+func IsInterfaceAddr(ip netip.Addr) bool {
+	ifaces, err := allInterfaces()
+	if err != nil {
+		return false
+	}
+
+	ip = ip.Unmap()
+	for _, iface := range ifaces {
+		for _, prefix := range iface.Addrs {
+			if prefix.Addr() == ip {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func allInterfaces() (map[string]*Interface, error) {
 	value, err, _ := interfaces.Do(func() (map[string]*Interface, error) {
 		ifaces, err := net.Interfaces()
 		if err != nil {
@@ -62,17 +94,7 @@ func ResolveInterface(name string) (*Interface, error) {
 
 		return r, nil
 	})
-	if err != nil {
-		return nil, err
-	}
-
-	ifaces := value
-	iface, ok := ifaces[name]
-	if !ok {
-		return nil, ErrIfaceNotFound
-	}
-
-	return iface, nil
+	return value, err
 }
 
 func FlushCache() {
@@ -0,0 +1,77 @@
+// Package loopback detects connections clash dialed itself that come back to
+// one of its own listeners, e.g. a DNS upstream routed through the tun.
+package loopback
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+
+	"github.com/Dreamacro/clash/common/cache"
+	"github.com/Dreamacro/clash/component/iface"
+
+	"go.uber.org/atomic"
+)
+
+var (
+	Enable = atomic.NewBool(false)
+
+	ErrLoopback = errors.New("loopback detected")
+
+	// a looped connection comes back while it is being dialed, so the local
+	// addresses only have to be remembered for a short time
+	localAddrs = cache.NewLRUCache[localAddr, struct{}](
+		cache.WithAge[localAddr, struct{}](10),
+		cache.WithSize[localAddr, struct{}](4096),
+	)
+
+	// isHostAddr reports whether an address belongs to this host, replaced in tests
+	isHostAddr = func(ip netip.Addr) bool {
+		return ip.IsLoopback() || iface.IsInterfaceAddr(ip)
+	}
+)
+
+type localAddr struct {
+	network string
+	addr    netip.AddrPort
+}
+
+// Register remembers the local address of an outgoing connection, the network is "tcp" or "udp" as of addr.Network()
+func Register(addr net.Addr) {
+	if !Enable.Load() || addr == nil {
+		return
+	}
+
+	if ap, err := netip.ParseAddrPort(addr.String()); err == nil {
+		localAddrs.Set(localAddr{network: addr.Network(), addr: unmap(ap)}, struct{}{})
+	}
+}
+
+// Check returns ErrLoopback when the source of an inbound connection of network
+// ("tcp" or "udp") is the local address of a connection clash dialed
+func Check(network string, src netip.AddrPort) error {
+	if !Enable.Load() || !src.IsValid() {
+		return nil
+	}
+
+	src = unmap(src)
+	if localAddrs.Exist(localAddr{network: network, addr: src}) {
+		return ErrLoopback
+	}
+
+	// an unconnected udp socket is bound to the unspecified address, so only
+	// a source on this host can be one of them
+	if !isHostAddr(src.Addr()) {
+		return nil
+	}
+	for _, ip := range []netip.Addr{netip.IPv4Unspecified(), netip.IPv6Unspecified()} {
+		if localAddrs.Exist(localAddr{network: network, addr: netip.AddrPortFrom(ip, src.Port())}) {
+			return ErrLoopback
+		}
+	}
+	return nil
+}
+
+func unmap(ap netip.AddrPort) netip.AddrPort {
+	return netip.AddrPortFrom(ap.Addr().Unmap(), ap.Port())
+}
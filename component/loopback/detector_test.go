@@ -0,0 +1,28 @@
+package loopback
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	Enable.Store(true)
+	defer Enable.Store(false)
+	isHostAddr = func(ip netip.Addr) bool {
+		return ip == netip.MustParseAddr("192.168.1.2") || ip.IsLoopback()
+	}
+
+	Register(&net.TCPAddr{IP: net.ParseIP("192.168.1.2"), Port: 40001})
+	Register(&net.UDPAddr{IP: net.IPv4zero, Port: 40002})
+
+	assert.ErrorIs(t, Check("tcp", netip.MustParseAddrPort("192.168.1.2:40001")), ErrLoopback)
+	assert.NoError(t, Check("udp", netip.MustParseAddrPort("192.168.1.2:40001")), "other network")
+
+	assert.ErrorIs(t, Check("udp", netip.MustParseAddrPort("192.168.1.2:40002")), ErrLoopback)
+	assert.ErrorIs(t, Check("udp", netip.MustParseAddrPort("[::ffff:127.0.0.1]:40002")), ErrLoopback)
+	assert.NoError(t, Check("tcp", netip.MustParseAddrPort("192.168.1.2:40002")), "wildcard udp socket")
+	assert.NoError(t, Check("udp", netip.MustParseAddrPort("192.168.1.3:40002")), "lan client")
+}
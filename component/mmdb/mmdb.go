@@ -1,25 +1,28 @@
 package mmdb
 
 import (
-	"github.com/oschwald/geoip2-golang"
+	"os"
 	"sync"
 
+	"github.com/oschwald/geoip2-golang"
+	"go.uber.org/atomic"
+
 	C "github.com/Dreamacro/clash/constant"
 	"github.com/Dreamacro/clash/log"
 )
 
 var (
-	mmdb *geoip2.Reader
+	mmdb atomic.Pointer[geoip2.Reader]
 	once sync.Once
 )
 
 func LoadFromBytes(buffer []byte) {
 	once.Do(func() {
-		var err error
-		mmdb, err = geoip2.FromBytes(buffer)
+		instance, err := geoip2.FromBytes(buffer)
 		if err != nil {
 			log.Fatalln("Can't load mmdb: %s", err.Error())
 		}
+		mmdb.Store(instance)
 	})
 }
 
@@ -31,14 +34,32 @@ func Verify() bool {
 	return err == nil
 }
 
+// Reload replaces the loaded database with the one on disk. The new file is
+// read into memory so readers of the old instance are never unmapped.
+func Reload() error {
+	buf, err := os.ReadFile(C.Path.MMDB())
+	if err != nil {
+		return err
+	}
+
+	instance, err := geoip2.FromBytes(buf)
+	if err != nil {
+		return err
+	}
+
+	once.Do(func() {})
+	mmdb.Store(instance)
+	return nil
+}
+
 func Instance() *geoip2.Reader {
 	once.Do(func() {
-		var err error
-		mmdb, err = geoip2.Open(C.Path.MMDB())
+		instance, err := geoip2.Open(C.Path.MMDB())
 		if err != nil {
 			log.Fatalln("Can't load mmdb: %s", err.Error())
 		}
+		mmdb.Store(instance)
 	})
 
-	return mmdb
+	return mmdb.Load()
 }
@@ -1,6 +1,7 @@
 package cachefile
 
 import (
+	"encoding/json"
 	"os"
 	"sync"
 	"time"
@@ -17,10 +18,33 @@ var (
 	fileMode     os.FileMode = 0o666
 	defaultCache *CacheFile
 
-	bucketSelected = []byte("selected")
-	bucketFakeip   = []byte("fakeip")
+	bucketSelected     = []byte("selected")
+	bucketFakeip       = []byte("fakeip")
+	bucketHealthCheck  = []byte("healthcheck")
+	bucketRemark       = []byte("remark")
+	bucketSubscription = []byte("subscription")
 )
 
+// HealthCheckOption is the test url and expected status of a group changed through the API
+type HealthCheckOption struct {
+	URL            string `json:"url"`
+	ExpectedStatus string `json:"expected-status"`
+}
+
+// SubscriptionRecord is the state of a proxy provider after one download,
+// the traffic and expire come from its subscription-userinfo header
+type SubscriptionRecord struct {
+	Time     time.Time `json:"time"`
+	Nodes    int       `json:"nodes"`
+	Upload   int64     `json:"upload"`
+	Download int64     `json:"download"`
+	Total    int64     `json:"total"`
+	Expire   int64     `json:"expire"`
+}
+
+// maxSubscriptionRecords bounds the history kept per provider
+const maxSubscriptionRecords = 200
+
 // CacheFile store and update the cache file
 type CacheFile struct {
 	DB *bbolt.DB
@@ -69,6 +93,149 @@ func (c *CacheFile) SelectedMap() map[string]string {
 	return mapping
 }
 
+func (c *CacheFile) SetHealthCheck(group string, option HealthCheckOption) {
+	if !profile.StoreSelected.Load() {
+		return
+	} else if c.DB == nil {
+		return
+	}
+
+	buf, err := json.Marshal(option)
+	if err != nil {
+		return
+	}
+
+	err = c.DB.Batch(func(t *bbolt.Tx) error {
+		bucket, err := t.CreateBucketIfNotExists(bucketHealthCheck)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(group), buf)
+	})
+	if err != nil {
+		log.Warnln("[CacheFile] write cache to %s failed: %s", c.DB.Path(), err.Error())
+	}
+}
+
+func (c *CacheFile) HealthCheckMap() map[string]HealthCheckOption {
+	if !profile.StoreSelected.Load() {
+		return nil
+	} else if c.DB == nil {
+		return nil
+	}
+
+	mapping := map[string]HealthCheckOption{}
+	c.DB.View(func(t *bbolt.Tx) error {
+		bucket := t.Bucket(bucketHealthCheck)
+		if bucket == nil {
+			return nil
+		}
+
+		c := bucket.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			option := HealthCheckOption{}
+			if json.Unmarshal(v, &option) == nil {
+				mapping[string(k)] = option
+			}
+		}
+		return nil
+	})
+	return mapping
+}
+
+// SetRemark stores the note of a proxy, an empty remark deletes it.
+// Unlike the selection it is kept whether store-selected is enabled or not
+func (c *CacheFile) SetRemark(proxy, remark string) {
+	if c.DB == nil {
+		return
+	}
+
+	err := c.DB.Batch(func(t *bbolt.Tx) error {
+		bucket, err := t.CreateBucketIfNotExists(bucketRemark)
+		if err != nil {
+			return err
+		}
+		if remark == "" {
+			return bucket.Delete([]byte(proxy))
+		}
+		return bucket.Put([]byte(proxy), []byte(remark))
+	})
+	if err != nil {
+		log.Warnln("[CacheFile] write cache to %s failed: %s", c.DB.Path(), err.Error())
+	}
+}
+
+func (c *CacheFile) RemarkMap() map[string]string {
+	if c.DB == nil {
+		return nil
+	}
+
+	mapping := map[string]string{}
+	c.DB.View(func(t *bbolt.Tx) error {
+		bucket := t.Bucket(bucketRemark)
+		if bucket == nil {
+			return nil
+		}
+
+		c := bucket.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			mapping[string(k)] = string(v)
+		}
+		return nil
+	})
+	return mapping
+}
+
+// AppendSubscription adds a record to the history of a provider, dropping the oldest over the limit
+func (c *CacheFile) AppendSubscription(provider string, record SubscriptionRecord) {
+	if c.DB == nil {
+		return
+	}
+
+	err := c.DB.Batch(func(t *bbolt.Tx) error {
+		bucket, err := t.CreateBucketIfNotExists(bucketSubscription)
+		if err != nil {
+			return err
+		}
+
+		var history []SubscriptionRecord
+		if v := bucket.Get([]byte(provider)); v != nil {
+			_ = json.Unmarshal(v, &history)
+		}
+		history = append(history, record)
+		if len(history) > maxSubscriptionRecords {
+			history = history[len(history)-maxSubscriptionRecords:]
+		}
+
+		buf, err := json.Marshal(history)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(provider), buf)
+	})
+	if err != nil {
+		log.Warnln("[CacheFile] write cache to %s failed: %s", c.DB.Path(), err.Error())
+	}
+}
+
+// SubscriptionHistory returns the records of a provider, the oldest first
+func (c *CacheFile) SubscriptionHistory(provider string) []SubscriptionRecord {
+	history := []SubscriptionRecord{}
+	if c.DB == nil {
+		return history
+	}
+
+	c.DB.View(func(t *bbolt.Tx) error {
+		if bucket := t.Bucket(bucketSubscription); bucket != nil {
+			if v := bucket.Get([]byte(provider)); v != nil {
+				_ = json.Unmarshal(v, &history)
+			}
+		}
+		return nil
+	})
+	return history
+}
+
 func (c *CacheFile) PutFakeip(key, value []byte) error {
 	if c.DB == nil {
 		return nil
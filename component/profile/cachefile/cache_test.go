@@ -0,0 +1,63 @@
+package cachefile
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/bbolt"
+)
+
+func newTestCacheFile(t *testing.T) *CacheFile {
+	db, err := bbolt.Open(filepath.Join(t.TempDir(), "cache.db"), 0o666, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return &CacheFile{DB: db}
+}
+
+func TestCacheFile_AppendSubscription(t *testing.T) {
+	tests := []struct {
+		name    string
+		appends int
+		// first and last are the node counts of the oldest and newest records kept
+		len, first, last int
+	}{
+		{name: "empty", appends: 0, len: 0},
+		{name: "one", appends: 1, len: 1, first: 0, last: 0},
+		{name: "at the limit", appends: maxSubscriptionRecords, len: maxSubscriptionRecords, first: 0, last: maxSubscriptionRecords - 1},
+		{name: "over the limit", appends: maxSubscriptionRecords + 5, len: maxSubscriptionRecords, first: 5, last: maxSubscriptionRecords + 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCacheFile(t)
+			for i := 0; i < tt.appends; i++ {
+				c.AppendSubscription("sub", SubscriptionRecord{Nodes: i})
+			}
+
+			history := c.SubscriptionHistory("sub")
+			if assert.Len(t, history, tt.len) && tt.len > 0 {
+				assert.Equal(t, tt.first, history[0].Nodes)
+				assert.Equal(t, tt.last, history[len(history)-1].Nodes)
+			}
+		})
+	}
+}
+
+func TestCacheFile_AppendSubscriptionPerProvider(t *testing.T) {
+	c := newTestCacheFile(t)
+	c.AppendSubscription("a", SubscriptionRecord{Nodes: 1, Total: 100})
+	c.AppendSubscription("b", SubscriptionRecord{Nodes: 2})
+
+	assert.Equal(t, []SubscriptionRecord{{Nodes: 1, Total: 100}}, c.SubscriptionHistory("a"))
+	assert.Equal(t, []SubscriptionRecord{{Nodes: 2}}, c.SubscriptionHistory("b"))
+	assert.Empty(t, c.SubscriptionHistory("c"))
+}
+
+func TestCacheFile_NoDB(t *testing.T) {
+	c := &CacheFile{}
+	c.AppendSubscription("a", SubscriptionRecord{Nodes: 1})
+	assert.Empty(t, c.SubscriptionHistory("a"))
+}
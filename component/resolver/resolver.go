@@ -26,6 +26,9 @@ var (
 	// DefaultHosts aim to resolve hosts
 	DefaultHosts = trie.New[netip.Addr]()
 
+	// DefaultHostAliases maps a domain to another domain in hosts
+	DefaultHostAliases = trie.New[string]()
+
 	// DefaultDNSTimeout defined the default dns request timeout
 	DefaultDNSTimeout = time.Second * 5
 )
@@ -121,11 +124,21 @@ func ResolveProxyServerHost(host string) (netip.Addr, error) {
 	return ResolveIP(host)
 }
 
+// HostAlias returns the target domain if host is aliased in hosts, otherwise host itself
+func HostAlias(host string) string {
+	if node := DefaultHostAliases.Search(host); node != nil {
+		return node.Data
+	}
+	return host
+}
+
 func ResolveAllIPv6WithResolver(host string, r Resolver) ([]netip.Addr, error) {
 	if DisableIPv6 {
 		return []netip.Addr{}, ErrIPv6Disabled
 	}
 
+	host = HostAlias(host)
+
 	if node := DefaultHosts.Search(host); node != nil {
 		if ip := node.Data; ip.Is6() {
 			return []netip.Addr{ip}, nil
@@ -160,6 +173,7 @@ func ResolveAllIPv6WithResolver(host string, r Resolver) ([]netip.Addr, error) {
 }
 
 func ResolveAllIPv4WithResolver(host string, r Resolver) ([]netip.Addr, error) {
+	host = HostAlias(host)
 	if node := DefaultHosts.Search(host); node != nil {
 		if ip := node.Data; ip.Is4() {
 			return []netip.Addr{node.Data}, nil
@@ -199,6 +213,7 @@ func ResolveAllIPv4WithResolver(host string, r Resolver) ([]netip.Addr, error) {
 }
 
 func ResolveAllIPWithResolver(host string, r Resolver) ([]netip.Addr, error) {
+	host = HostAlias(host)
 	if node := DefaultHosts.Search(host); node != nil {
 		return []netip.Addr{node.Data}, nil
 	}
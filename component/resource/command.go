@@ -0,0 +1,88 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	C "github.com/Dreamacro/clash/constant"
+	types "github.com/Dreamacro/clash/constant/provider"
+
+	"go.uber.org/atomic"
+)
+
+var (
+	// commandPermitted is only set by the process owner, never by a config
+	commandPermitted = atomic.NewBool(false)
+	allowCommand     = atomic.NewBool(false)
+
+	errCommandNotPermitted = errors.New("command vehicle is not permitted, start clash with -allow-command or CLASH_ALLOW_COMMAND=1 to permit it")
+	errCommandDisabled     = errors.New("command vehicle is disabled, set allow-command-provider to true to enable it")
+	errCommandEmpty        = errors.New("command vehicle requires a command")
+)
+
+// PermitCommand lets configs enable the command vehicle, it is called for the -allow-command flag
+// since the vehicle runs arbitrary programs taken from the config
+func PermitCommand() {
+	commandPermitted.Store(true)
+}
+
+func CommandPermitted() bool {
+	return commandPermitted.Load()
+}
+
+// SetAllowCommand enables or disables the command vehicle for the applied config, it stays off
+// unless the process owner permitted it
+func SetAllowCommand(allow bool) {
+	allowCommand.Store(allow && commandPermitted.Load())
+}
+
+// CommandVehicle runs a local program and uses its stdout as the content
+type CommandVehicle struct {
+	command []string
+	path    string
+}
+
+func (c *CommandVehicle) Type() types.VehicleType {
+	return types.Command
+}
+
+func (c *CommandVehicle) Path() string {
+	return c.path
+}
+
+func (c *CommandVehicle) Read() ([]byte, error) {
+	if !allowCommand.Load() {
+		return nil, errCommandDisabled
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.command[0], c.command[1:]...)
+	cmd.Dir = C.Path.HomeDir()
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("run %s failed: %w: %s", c.command[0], err, msg)
+		}
+		return nil, fmt.Errorf("run %s failed: %w", c.command[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+func NewCommandVehicle(command []string, path string) (*CommandVehicle, error) {
+	if !commandPermitted.Load() {
+		return nil, errCommandNotPermitted
+	}
+	if len(command) == 0 || command[0] == "" {
+		return nil, errCommandEmpty
+	}
+	return &CommandVehicle{command: command, path: path}, nil
+}
@@ -0,0 +1,71 @@
+package resource
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// acceptEncoding is sent by the HTTP vehicle, setting it turns off the
+// transparent gzip of net/http so readBody decodes the body itself
+const acceptEncoding = "gzip, deflate"
+
+// maxBodySize bounds a downloaded provider both as sent and unpacked, so a broken
+// subscription or a gzip bomb can't exhaust the memory
+const maxBodySize = 64 << 20
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// readLimited reads r up to maxBodySize, failing on anything larger
+func readLimited(r io.Reader) ([]byte, error) {
+	buf, err := io.ReadAll(io.LimitReader(r, maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) > maxBodySize {
+		return nil, fmt.Errorf("body larger than %d bytes", maxBodySize)
+	}
+	return buf, nil
+}
+
+// readBody reads a response body according to its Content-Encoding. A plain
+// body that is a gzip file, like a provider hosted as rules.yaml.gz, is
+// unpacked as well
+func readBody(encoding string, body io.Reader) ([]byte, error) {
+	var r io.Reader
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		buf, err := readLimited(body)
+		if err != nil || !bytes.HasPrefix(buf, gzipMagic) {
+			return buf, err
+		}
+
+		gr, err := gzip.NewReader(bytes.NewReader(buf))
+		if err != nil {
+			// not a gzip file after all
+			return buf, nil
+		}
+		defer gr.Close()
+		return readLimited(gr)
+	case "gzip", "x-gzip":
+		gr, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		r = gr
+	case "deflate":
+		zr, err := zlib.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %s", encoding)
+	}
+	return readLimited(r)
+}
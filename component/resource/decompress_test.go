@@ -0,0 +1,95 @@
+package resource
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func gzipped(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+func deflated(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+func TestReadBody(t *testing.T) {
+	payload := []byte("proxies: []\n")
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+		want     []byte
+		wantErr  bool
+	}{
+		{name: "plain", encoding: "", body: payload, want: payload},
+		{name: "identity", encoding: "identity", body: payload, want: payload},
+		{name: "gzip file", encoding: "", body: gzipped(t, payload), want: payload},
+		{name: "gzip", encoding: "gzip", body: gzipped(t, payload), want: payload},
+		{name: "x-gzip", encoding: " X-GZIP ", body: gzipped(t, payload), want: payload},
+		{name: "deflate", encoding: "deflate", body: deflated(t, payload), want: payload},
+		{name: "broken gzip", encoding: "gzip", body: payload, wantErr: true},
+		{name: "unsupported", encoding: "br", body: payload, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := readBody(tt.encoding, bytes.NewReader(tt.body))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, buf)
+			}
+		})
+	}
+}
+
+func TestReadBody_SizeLimit(t *testing.T) {
+	exact := io.LimitReader(zeroReader{}, maxBodySize)
+	buf, err := readBody("", exact)
+	assert.NoError(t, err)
+	assert.Len(t, buf, maxBodySize)
+
+	_, err = readBody("", io.LimitReader(zeroReader{}, maxBodySize+1))
+	assert.Error(t, err)
+
+	// a small gzip unpacking beyond the limit
+	bomb := gzipped(t, bytes.Repeat([]byte{0}, maxBodySize+1))
+	for _, encoding := range []string{"", "gzip"} {
+		_, err = readBody(encoding, bytes.NewReader(bomb))
+		assert.Error(t, err, encoding)
+	}
+
+	_, err = readBody("deflate", bytes.NewReader(deflated(t, []byte(strings.Repeat("a", maxBodySize+1)))))
+	assert.Error(t, err)
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
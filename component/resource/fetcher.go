@@ -9,6 +9,8 @@ import (
 
 	types "github.com/Dreamacro/clash/constant/provider"
 	"github.com/Dreamacro/clash/log"
+
+	"go.uber.org/atomic"
 )
 
 var (
@@ -28,18 +30,56 @@ type Fetcher[V any] struct {
 	hash         [16]byte
 	parser       Parser[V]
 	interval     time.Duration
+	lastErr      *atomic.String
 	OnUpdate     func(V)
+	// OnFetch is called after every successful pull, changed or not
+	OnFetch func()
 }
 
 func (f *Fetcher[V]) Name() string {
 	return f.name
 }
 
+func (f *Fetcher[V]) Vehicle() types.Vehicle {
+	return f.vehicle
+}
+
 func (f *Fetcher[V]) VehicleType() types.VehicleType {
 	return f.vehicle.Type()
 }
 
+// URL returns the address the resource is downloaded from, empty if it isn't fetched over http
+func (f *Fetcher[V]) URL() string {
+	if v, ok := f.vehicle.(interface{ URL() string }); ok {
+		return v.URL()
+	}
+	return ""
+}
+
+func (f *Fetcher[V]) Interval() time.Duration {
+	return f.interval
+}
+
+// LastError returns the error of the latest fetch, empty if it succeeded
+func (f *Fetcher[V]) LastError() string {
+	return f.lastErr.Load()
+}
+
+func (f *Fetcher[V]) setLastError(err error) {
+	if err != nil {
+		f.lastErr.Store(err.Error())
+	} else {
+		f.lastErr.Store("")
+	}
+}
+
 func (f *Fetcher[V]) Initial() (V, error) {
+	contents, err := f.initial()
+	f.setLastError(err)
+	return contents, err
+}
+
+func (f *Fetcher[V]) initial() (V, error) {
 	var (
 		buf         []byte
 		err         error
@@ -115,6 +155,12 @@ func (f *Fetcher[V]) Initial() (V, error) {
 }
 
 func (f *Fetcher[V]) Update() (V, bool, error) {
+	contents, same, err := f.update()
+	f.setLastError(err)
+	return contents, same, err
+}
+
+func (f *Fetcher[V]) update() (V, bool, error) {
 	buf, err := f.vehicle.Read()
 	if err != nil {
 		return getZero[V](), false, err
@@ -164,12 +210,15 @@ func (f *Fetcher[V]) pullLoop() {
 
 			if same {
 				log.Debugln("[Provider] %s's content doesn't change", f.Name())
-				continue
+			} else {
+				log.Infoln("[Provider] %s's content update", f.Name())
+				if f.OnUpdate != nil {
+					f.OnUpdate(elm)
+				}
 			}
 
-			log.Infoln("[Provider] %s's content update", f.Name())
-			if f.OnUpdate != nil {
-				f.OnUpdate(elm)
+			if f.OnFetch != nil {
+				f.OnFetch()
 			}
 		case <-f.done:
 			f.ticker.Stop()
@@ -202,6 +251,7 @@ func NewFetcher[V any](name string, interval time.Duration, vehicle types.Vehicl
 		vehicle:  vehicle,
 		parser:   parser,
 		done:     make(chan struct{}, 1),
+		lastErr:  atomic.NewString(""),
 		OnUpdate: onUpdate,
 		interval: interval,
 	}
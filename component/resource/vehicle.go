@@ -2,12 +2,15 @@ package resource
 
 import (
 	"context"
+	"fmt"
 	netHttp "github.com/Dreamacro/clash/component/http"
 	types "github.com/Dreamacro/clash/constant/provider"
-	"io"
+	"github.com/Dreamacro/clash/log"
 	"net/http"
 	"os"
 	"time"
+
+	"go.uber.org/atomic"
 )
 
 type FileVehicle struct {
@@ -31,8 +34,9 @@ func NewFileVehicle(path string) *FileVehicle {
 }
 
 type HTTPVehicle struct {
-	url  string
-	path string
+	url      *atomic.String
+	path     string
+	userInfo *atomic.String
 }
 
 func (h *HTTPVehicle) Type() types.VehicleType {
@@ -43,22 +47,43 @@ func (h *HTTPVehicle) Path() string {
 	return h.path
 }
 
+func (h *HTTPVehicle) URL() string {
+	return h.url.Load()
+}
+
+// SubscriptionUserInfo returns the subscription-userinfo header of the latest download, empty once a download failed
+func (h *HTTPVehicle) SubscriptionUserInfo() string {
+	return h.userInfo.Load()
+}
+
 func (h *HTTPVehicle) Read() ([]byte, error) {
+	h.userInfo.Store("")
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
 	defer cancel()
-	resp, err := netHttp.HttpRequest(ctx, h.url, http.MethodGet, nil, nil)
+	header := map[string][]string{"Accept-Encoding": {acceptEncoding}}
+	resp, err := netHttp.HttpRequest(ctx, h.url.Load(), http.MethodGet, header, nil)
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	// follow a subscription that moved for good, until the next config reload
+	if url, ok := netHttp.PermanentlyMoved(resp); ok {
+		log.Infoln("[Provider] %s moved permanently to %s", h.url.Load(), url)
+		h.url.Store(url)
+	}
 
 	defer resp.Body.Close()
-	buf, err := io.ReadAll(resp.Body)
+	buf, err := readBody(resp.Header.Get("Content-Encoding"), resp.Body)
 	if err != nil {
 		return nil, err
 	}
+	h.userInfo.Store(resp.Header.Get("Subscription-Userinfo"))
 	return buf, nil
 }
 
 func NewHTTPVehicle(url string, path string) *HTTPVehicle {
-	return &HTTPVehicle{url, path}
+	return &HTTPVehicle{atomic.NewString(url), path, atomic.NewString("")}
 }
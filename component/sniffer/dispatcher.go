@@ -1,8 +1,8 @@
 package sniffer
 
 import (
+	"encoding/binary"
 	"errors"
-	"fmt"
 	"net"
 	"net/netip"
 	"strconv"
@@ -39,6 +39,29 @@ type SnifferDispatcher struct {
 
 	forceDnsMapping bool
 	parsePureIp     bool
+	pureIpOnly      bool
+
+	// overrideDestination sniffs connections with a client provided domain too,
+	// so the sniffed host is used for routing instead of a possibly fronted one
+	overrideDestination bool
+}
+
+// shouldSniff reports whether the connection destination is worth sniffing,
+// with pureIpOnly only the ones without a known domain are sniffed
+func (sd *SnifferDispatcher) shouldSniff(metadata *C.Metadata) bool {
+	if metadata.Host == "" {
+		return sd.parsePureIp || sd.pureIpOnly
+	}
+
+	if sd.pureIpOnly {
+		return false
+	}
+
+	if sd.overrideDestination {
+		return true
+	}
+
+	return sd.forceDomain.Search(metadata.Host) != nil || (metadata.DNSMode == C.DNSMapping && sd.forceDnsMapping)
 }
 
 func (sd *SnifferDispatcher) TCPSniff(conn net.Conn, metadata *C.Metadata) {
@@ -47,7 +70,7 @@ func (sd *SnifferDispatcher) TCPSniff(conn net.Conn, metadata *C.Metadata) {
 		return
 	}
 
-	if (metadata.Host == "" && sd.parsePureIp) || sd.forceDomain.Search(metadata.Host) != nil || (metadata.DNSMode == C.DNSMapping && sd.forceDnsMapping) {
+	if sd.shouldSniff(metadata) {
 		port, err := strconv.ParseUint(metadata.DstPort, 10, 16)
 		if err != nil {
 			log.Debugln("[Sniffer] Dst port is error")
@@ -67,7 +90,7 @@ func (sd *SnifferDispatcher) TCPSniff(conn net.Conn, metadata *C.Metadata) {
 		}
 
 		sd.rwMux.RLock()
-		dst := fmt.Sprintf("%s:%s", metadata.DstIP, metadata.DstPort)
+		dst := metadata.RemoteAddress()
 		if count, ok := sd.skipList.Get(dst); ok && count > 5 {
 			log.Debugln("[Sniffer] Skip sniffing[%s] due to multiple failures", dst)
 			defer sd.rwMux.RUnlock()
@@ -112,6 +135,8 @@ func (sd *SnifferDispatcher) replaceDomain(metadata *C.Metadata, host string) {
 			metadata.Host, host)
 	}
 
+	metadata.RequestHost = originHost
+	metadata.SniffHost = host
 	metadata.AddrType = C.AtypDomainName
 	metadata.Host = host
 	metadata.DNSMode = C.DNSNormal
@@ -121,6 +146,32 @@ func (sd *SnifferDispatcher) Enable() bool {
 	return sd.enable
 }
 
+// maxTLSRecordSize is the largest TLS record, header included
+const maxTLSRecordSize = 5 + 16384
+
+// waitTLSRecord buffers the whole first record when the conn starts with a TLS
+// handshake, a large ClientHello often arrives in several segments
+func waitTLSRecord(conn *N.BufferedConn) {
+	if first, err := conn.Peek(1); err != nil || first[0] != 0x16 {
+		return
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(1 * time.Second))
+	defer conn.SetReadDeadline(time.Time{})
+
+	header, err := conn.Peek(5)
+	if err != nil {
+		return
+	}
+
+	length := 5 + int(binary.BigEndian.Uint16(header[3:5]))
+	if length > maxTLSRecordSize {
+		length = maxTLSRecordSize
+	}
+	conn.Grow(length)
+	_, _ = conn.Peek(length)
+}
+
 func (sd *SnifferDispatcher) sniffDomain(conn *N.BufferedConn, metadata *C.Metadata) (string, error) {
 	for _, s := range sd.sniffers {
 		if s.SupportNetwork() == C.TCP {
@@ -138,6 +189,8 @@ func (sd *SnifferDispatcher) sniffDomain(conn *N.BufferedConn, metadata *C.Metad
 				return "", err
 			}
 
+			waitTLSRecord(conn)
+
 			bufferedLen := conn.Buffered()
 			bytes, err := conn.Peek(bufferedLen)
 			if err != nil {
@@ -151,6 +204,17 @@ func (sd *SnifferDispatcher) sniffDomain(conn *N.BufferedConn, metadata *C.Metad
 				continue
 			}
 
+			switch s.(type) {
+			case *HTTPSniffer:
+				if method, path, ok := SniffHTTPRequestLine(bytes); ok {
+					metadata.HTTPMethod, metadata.HTTPPath = method, path
+				}
+			case *TLSSniffer:
+				if ja3, ja4, ok := SniffTLSFingerprint(bytes); ok {
+					metadata.JA3, metadata.JA4 = ja3, ja4
+				}
+			}
+
 			_, err = netip.ParseAddr(host)
 			if err == nil {
 				//log.Debugln("[Sniffer] [%s] Sniff data failed %s", s.Protocol(), metadata.DstIP)
@@ -166,7 +230,7 @@ func (sd *SnifferDispatcher) sniffDomain(conn *N.BufferedConn, metadata *C.Metad
 
 func (sd *SnifferDispatcher) cacheSniffFailed(metadata *C.Metadata) {
 	sd.rwMux.Lock()
-	dst := fmt.Sprintf("%s:%s", metadata.DstIP, metadata.DstPort)
+	dst := metadata.RemoteAddress()
 	count, _ := sd.skipList.Get(dst)
 	if count <= 5 {
 		count++
@@ -185,7 +249,7 @@ func NewCloseSnifferDispatcher() (*SnifferDispatcher, error) {
 
 func NewSnifferDispatcher(needSniffer []sniffer.Type, forceDomain *trie.DomainTrie[bool],
 	skipSNI *trie.DomainTrie[bool], ports *[]utils.Range[uint16],
-	forceDnsMapping bool, parsePureIp bool) (*SnifferDispatcher, error) {
+	forceDnsMapping bool, parsePureIp bool, pureIpOnly bool, overrideDestination bool) (*SnifferDispatcher, error) {
 	dispatcher := SnifferDispatcher{
 		enable:          true,
 		forceDomain:     forceDomain,
@@ -194,6 +258,9 @@ func NewSnifferDispatcher(needSniffer []sniffer.Type, forceDomain *trie.DomainTr
 		skipList:        cache.NewLRUCache[string, uint8](cache.WithSize[string, uint8](128), cache.WithAge[string, uint8](600)),
 		forceDnsMapping: forceDnsMapping,
 		parsePureIp:     parsePureIp,
+		pureIpOnly:      pureIpOnly,
+
+		overrideDestination: overrideDestination,
 	}
 
 	for _, snifferName := range needSniffer {
@@ -0,0 +1,248 @@
+package sniffer
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type clientHello struct {
+	version       uint16
+	ciphers       []uint16
+	extensions    []uint16
+	groups        []uint16
+	pointFormats  []uint8
+	versions      []uint16
+	signatureAlgs []uint16
+	alpn          []string
+	serverName    bool
+}
+
+// isGREASE reports the reserved values of RFC 8701, they are skipped by JA3 and JA4
+func isGREASE(v uint16) bool {
+	return v&0x0f0f == 0x0a0a && v>>8 == v&0xff
+}
+
+func readUint16List(b []byte) []uint16 {
+	list := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		if v := binary.BigEndian.Uint16(b[i:]); !isGREASE(v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// parseClientHello reads the fields used by fingerprints from a TLS record holding a ClientHello
+func parseClientHello(b []byte) (*clientHello, bool) {
+	if len(b) < 5 || b[0] != 0x16 {
+		return nil, false
+	}
+	recordLen := int(binary.BigEndian.Uint16(b[3:5]))
+	if len(b) < 5+recordLen {
+		return nil, false
+	}
+	data := b[5 : 5+recordLen]
+
+	// handshake header, legacy version, random
+	if len(data) < 4+2+32+1 || data[0] != 0x01 {
+		return nil, false
+	}
+	hello := &clientHello{version: binary.BigEndian.Uint16(data[4:6])}
+	data = data[38:]
+
+	sessionIDLen := int(data[0])
+	if len(data) < 1+sessionIDLen+2 {
+		return nil, false
+	}
+	data = data[1+sessionIDLen:]
+
+	cipherLen := int(binary.BigEndian.Uint16(data))
+	if len(data) < 2+cipherLen+1 {
+		return nil, false
+	}
+	hello.ciphers = readUint16List(data[2 : 2+cipherLen])
+	data = data[2+cipherLen:]
+
+	compressionLen := int(data[0])
+	if len(data) < 1+compressionLen {
+		return nil, false
+	}
+	data = data[1+compressionLen:]
+	if len(data) < 2 {
+		return hello, true
+	}
+
+	extensionsLen := int(binary.BigEndian.Uint16(data))
+	data = data[2:]
+	if len(data) < extensionsLen {
+		return nil, false
+	}
+	data = data[:extensionsLen]
+
+	for len(data) >= 4 {
+		extension := binary.BigEndian.Uint16(data)
+		length := int(binary.BigEndian.Uint16(data[2:]))
+		data = data[4:]
+		if len(data) < length {
+			return nil, false
+		}
+		body := data[:length]
+		data = data[length:]
+
+		if isGREASE(extension) {
+			continue
+		}
+		hello.extensions = append(hello.extensions, extension)
+
+		switch extension {
+		case 0x0000: // server_name
+			hello.serverName = true
+		case 0x000a: // supported_groups
+			if len(body) >= 2 {
+				hello.groups = readUint16List(body[2:])
+			}
+		case 0x000b: // ec_point_formats
+			if len(body) >= 1 && len(body) >= 1+int(body[0]) {
+				hello.pointFormats = body[1 : 1+int(body[0])]
+			}
+		case 0x000d: // signature_algorithms
+			if len(body) >= 2 {
+				hello.signatureAlgs = readUint16List(body[2:])
+			}
+		case 0x0010: // application_layer_protocol_negotiation
+			if len(body) >= 2 {
+				protocols := body[2:]
+				for len(protocols) > 0 && len(protocols) >= 1+int(protocols[0]) {
+					hello.alpn = append(hello.alpn, string(protocols[1:1+int(protocols[0])]))
+					protocols = protocols[1+int(protocols[0]):]
+				}
+			}
+		case 0x002b: // supported_versions
+			if len(body) >= 1 {
+				hello.versions = readUint16List(body[1:])
+			}
+		}
+	}
+
+	return hello, true
+}
+
+func joinDecimal[T uint8 | uint16](list []T) string {
+	parts := make([]string, len(list))
+	for i, v := range list {
+		parts[i] = strconv.Itoa(int(v))
+	}
+	return strings.Join(parts, "-")
+}
+
+func joinHex(list []uint16) string {
+	parts := make([]string, len(list))
+	for i, v := range list {
+		parts[i] = fmt.Sprintf("%04x", v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (h *clientHello) ja3String() string {
+	return strings.Join([]string{
+		strconv.Itoa(int(h.version)),
+		joinDecimal(h.ciphers),
+		joinDecimal(h.extensions),
+		joinDecimal(h.groups),
+		joinDecimal(h.pointFormats),
+	}, ",")
+}
+
+func (h *clientHello) ja3() string {
+	sum := md5.Sum([]byte(h.ja3String()))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncatedSHA256(s string) string {
+	if s == "" {
+		return "000000000000"
+	}
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func (h *clientHello) ja4() string {
+	version := h.version
+	if len(h.versions) > 0 {
+		version = h.versions[0]
+		for _, v := range h.versions[1:] {
+			if v > version {
+				version = v
+			}
+		}
+	}
+
+	var tlsVersion string
+	switch version {
+	case 0x0304:
+		tlsVersion = "13"
+	case 0x0303:
+		tlsVersion = "12"
+	case 0x0302:
+		tlsVersion = "11"
+	case 0x0301:
+		tlsVersion = "10"
+	case 0x0300:
+		tlsVersion = "s3"
+	default:
+		tlsVersion = "00"
+	}
+
+	sni := "i"
+	if h.serverName {
+		sni = "d"
+	}
+
+	alpn := "00"
+	if len(h.alpn) > 0 && h.alpn[0] != "" {
+		first := h.alpn[0]
+		alpn = string(first[0]) + string(first[len(first)-1])
+	}
+
+	ciphers := append([]uint16{}, h.ciphers...)
+	sort.Slice(ciphers, func(i, j int) bool { return ciphers[i] < ciphers[j] })
+
+	extensions := make([]uint16, 0, len(h.extensions))
+	for _, extension := range h.extensions {
+		if extension != 0x0000 && extension != 0x0010 {
+			extensions = append(extensions, extension)
+		}
+	}
+	sort.Slice(extensions, func(i, j int) bool { return extensions[i] < extensions[j] })
+
+	extensionsHash := joinHex(extensions)
+	if len(h.signatureAlgs) > 0 {
+		extensionsHash += "_" + joinHex(h.signatureAlgs)
+	}
+
+	return fmt.Sprintf("t%s%s%02d%02d%s_%s_%s",
+		tlsVersion, sni, min(len(h.ciphers), 99), min(len(h.extensions), 99), alpn,
+		truncatedSHA256(joinHex(ciphers)), truncatedSHA256(extensionsHash))
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// SniffTLSFingerprint returns the JA3 and JA4 fingerprints of a TLS ClientHello record
+func SniffTLSFingerprint(b []byte) (ja3 string, ja4 string, ok bool) {
+	hello, ok := parseClientHello(b)
+	if !ok {
+		return "", "", false
+	}
+	return hello.ja3(), hello.ja4(), true
+}
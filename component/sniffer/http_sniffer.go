@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/url"
 	"strings"
 
 	C "github.com/Dreamacro/clash/constant"
@@ -52,6 +53,26 @@ func (http *HTTPSniffer) SniffTCP(bytes []byte) (string, error) {
 	}
 }
 
+// SniffHTTPRequestLine returns the method and the path without query of a plaintext HTTP request
+func SniffHTTPRequestLine(b []byte) (method string, path string, ok bool) {
+	line, _, found := bytes.Cut(b, []byte{'\n'})
+	if !found {
+		return
+	}
+
+	parts := strings.Fields(string(line))
+	if len(parts) != 3 || !strings.HasPrefix(parts[2], "HTTP/") {
+		return
+	}
+
+	u, err := url.ParseRequestURI(parts[1])
+	if err != nil {
+		return
+	}
+
+	return strings.ToUpper(parts[0]), u.Path, true
+}
+
 func beginWithHTTPMethod(b []byte) error {
 	for _, m := range &methods {
 		if len(b) >= len(m) && strings.EqualFold(string(b[:len(m)]), m) {
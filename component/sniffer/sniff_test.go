@@ -1,7 +1,13 @@
 package sniffer
 
 import (
+	"bytes"
+	"io"
+	"net"
 	"testing"
+	"time"
+
+	N "github.com/Dreamacro/clash/common/net"
 )
 
 func TestTLSHeaders(t *testing.T) {
@@ -157,3 +163,96 @@ func TestTLSHeaders(t *testing.T) {
 		}
 	}
 }
+
+func TestHTTPRequestLine(t *testing.T) {
+	cases := []struct {
+		input  string
+		method string
+		path   string
+		ok     bool
+	}{
+		{input: "GET /v1/telemetry?id=1 HTTP/1.1\r\nHost: example.com\r\n\r\n", method: "GET", path: "/v1/telemetry", ok: true},
+		{input: "post http://example.com/api HTTP/1.0\r\n\r\n", method: "POST", path: "/api", ok: true},
+		{input: "GET /incomplete", ok: false},
+		{input: "GET /no-version\r\n", ok: false},
+	}
+
+	for _, test := range cases {
+		method, path, ok := SniffHTTPRequestLine([]byte(test.input))
+		if ok != test.ok || method != test.method || path != test.path {
+			t.Errorf("expect %s %s %v but got %s %s %v in test %q", test.method, test.path, test.ok, method, path, ok, test.input)
+		}
+	}
+}
+
+func TestTLSFingerprint(t *testing.T) {
+	u16 := func(v ...uint16) []byte {
+		b := make([]byte, 0, len(v)*2)
+		for _, x := range v {
+			b = append(b, byte(x>>8), byte(x))
+		}
+		return b
+	}
+	extension := func(tp uint16, body []byte) []byte {
+		return append(u16(tp, uint16(len(body))), body...)
+	}
+
+	var extensions []byte
+	extensions = append(extensions, extension(0x2a2a, nil)...)
+	extensions = append(extensions, extension(0x0000, append(u16(8), append([]byte{0}, append(u16(5), "a.com"...)...)...))...)
+	extensions = append(extensions, extension(0x000a, append(u16(6), u16(0x0a0a, 29, 23)...))...)
+	extensions = append(extensions, extension(0x000b, []byte{1, 0})...)
+	extensions = append(extensions, extension(0x0010, append(u16(3), 2, 'h', '2'))...)
+	extensions = append(extensions, extension(0x002b, append([]byte{4}, u16(0x0304, 0x0303)...))...)
+	extensions = append(extensions, extension(0x000d, append(u16(4), u16(0x0403, 0x0804)...))...)
+
+	hello := u16(0x0303)
+	hello = append(hello, make([]byte, 32)...)
+	hello = append(hello, 0)
+	hello = append(hello, u16(6, 0x1a1a, 0x1301, 0xc02b)...)
+	hello = append(hello, 1, 0)
+	hello = append(hello, u16(uint16(len(extensions)))...)
+	hello = append(hello, extensions...)
+
+	handshake := append([]byte{0x01, 0, byte(len(hello) >> 8), byte(len(hello))}, hello...)
+	record := append([]byte{0x16, 0x03, 0x01}, u16(uint16(len(handshake)))...)
+	record = append(record, handshake...)
+
+	ja3, ja4, ok := SniffTLSFingerprint(record)
+	if !ok {
+		t.Fatal("expect a client hello")
+	}
+	if ja3 != "5e928cebd5eb6b303efae166049cea2e" {
+		t.Errorf("unexpected ja3 %s", ja3)
+	}
+	if ja4 != "t13d0206h2_777cda164f4b_fb71836bce29" {
+		t.Errorf("unexpected ja4 %s", ja4)
+	}
+}
+
+func TestWaitTLSRecord(t *testing.T) {
+	record := append([]byte{0x16, 0x03, 0x01, 0x17, 0x70}, make([]byte, 6000)...)
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	go func() {
+		_, _ = server.Write(record[:1000])
+		time.Sleep(50 * time.Millisecond)
+		_, _ = server.Write(record[1000:])
+	}()
+
+	conn := N.NewBufferedConn(client)
+	if _, err := conn.Peek(1); err != nil {
+		t.Fatal(err)
+	}
+	waitTLSRecord(conn)
+	if conn.Buffered() != len(record) {
+		t.Fatalf("expect the whole record buffered, got %d bytes", conn.Buffered())
+	}
+
+	buf := make([]byte, len(record))
+	if _, err := io.ReadFull(conn, buf); err != nil || !bytes.Equal(buf, record) {
+		t.Fatal("the buffered record is not read back unchanged")
+	}
+}
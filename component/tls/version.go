@@ -0,0 +1,41 @@
+package tls
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+var versions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseVersionRange parses the tls-min-version and tls-max-version of a proxy,
+// an empty version is returned as 0 and leaves the default of crypto/tls
+func ParseVersionRange(minVersion, maxVersion string) (uint16, uint16, error) {
+	parse := func(version string) (uint16, error) {
+		if version == "" {
+			return 0, nil
+		}
+		if v, ok := versions[version]; ok {
+			return v, nil
+		}
+		return 0, fmt.Errorf("unsupported tls version: %s", version)
+	}
+
+	min, err := parse(minVersion)
+	if err != nil {
+		return 0, 0, err
+	}
+	max, err := parse(maxVersion)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if min != 0 && max != 0 && min > max {
+		return 0, 0, fmt.Errorf("tls-min-version %s is higher than tls-max-version %s", minVersion, maxVersion)
+	}
+	return min, max, nil
+}
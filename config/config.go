@@ -16,6 +16,7 @@ import (
 
 	"github.com/Dreamacro/clash/common/utils"
 	R "github.com/Dreamacro/clash/rules"
+	RC "github.com/Dreamacro/clash/rules/common"
 	RP "github.com/Dreamacro/clash/rules/provider"
 
 	"github.com/Dreamacro/clash/adapter"
@@ -34,6 +35,7 @@ import (
 	snifferTypes "github.com/Dreamacro/clash/constant/sniffer"
 	"github.com/Dreamacro/clash/dns"
 	"github.com/Dreamacro/clash/log"
+	"github.com/Dreamacro/clash/transport/socks5"
 	T "github.com/Dreamacro/clash/tunnel"
 
 	"gopkg.in/yaml.v3"
@@ -43,19 +45,30 @@ import (
 type General struct {
 	Inbound
 	Controller
-	Mode          T.TunnelMode `json:"mode"`
-	UnifiedDelay  bool
-	LogLevel      log.LogLevel `json:"log-level"`
-	IPv6          bool         `json:"ipv6"`
-	Interface     string       `json:"interface-name"`
-	RoutingMark   int          `json:"-"`
-	GeodataMode   bool         `json:"geodata-mode"`
-	GeodataLoader string       `json:"geodata-loader"`
-	TCPConcurrent bool         `json:"tcp-concurrent"`
-	EnableProcess bool         `json:"enable-process"`
-	Tun           Tun          `json:"tun"`
-	Sniffing      bool         `json:"sniffing"`
-	EBpf          EBpf         `json:"-"`
+	Mode              T.TunnelMode `json:"mode"`
+	UnifiedDelay      bool
+	LoopbackDetection bool
+	LogLevel          log.LogLevel `json:"log-level"`
+	IPv6              bool         `json:"ipv6"`
+	Interface         string       `json:"interface-name"`
+	RoutingMark       int          `json:"-"`
+	GeodataMode       bool         `json:"geodata-mode"`
+	GeodataLoader     string       `json:"geodata-loader"`
+	TCPConcurrent     bool         `json:"tcp-concurrent"`
+	EnableProcess     bool         `json:"enable-process"`
+	FailClosed        bool         `json:"group-fail-closed"`
+	FirstByteTimeout  int          `json:"first-byte-timeout"`
+	DialRetry         int          `json:"dial-retry"`
+	RetryStrategy     string       `json:"dial-retry-strategy"`
+	Tun               Tun          `json:"tun"`
+	Sniffing          bool         `json:"sniffing"`
+	EBpf              EBpf         `json:"-"`
+	AllowCommand      bool         `json:"-"`
+
+	// provenance of the running config, only filled in for GET /configs
+	ConfigPath   string                       `json:"config-path"`
+	LastReload   time.Time                    `json:"last-reload"`
+	ProviderURLs map[string]map[string]string `json:"provider-urls"`
 }
 
 // Inbound config
@@ -69,15 +82,31 @@ type Inbound struct {
 	AllowLan       bool     `json:"allow-lan"`
 	BindAddress    string   `json:"bind-address"`
 	InboundTfo     bool     `json:"inbound-tfo"`
+	Backlog        int      `json:"inbound-backlog"`
+	AcceptRate     int      `json:"inbound-accept-rate"`
+	AcceptBurst    int      `json:"inbound-accept-burst"`
+	MaxConn        int      `json:"inbound-max-connections"`
+	// UDP turns off the UDP relay of the socks, mixed, redir or tproxy inbound set to false
+	UDP map[string]bool `json:"inbound-udp,omitempty"`
 }
 
 // Controller config
 type Controller struct {
 	ExternalController string `json:"-"`
+	ControllerMixed    bool   `json:"-"`
 	ExternalUI         string `json:"-"`
 	Secret             string `json:"-"`
 }
 
+// Tunnel forwards a local address to a fixed target through a proxy
+type Tunnel struct {
+	Address string `yaml:"address" json:"address"`
+	Target  string `yaml:"target" json:"target"`
+	Proxy   string `yaml:"proxy" json:"proxy"`
+	// TargetAddr is Target parsed by parseTunnels
+	TargetAddr socks5.Addr `yaml:"-" json:"-"`
+}
+
 // DNS config
 type DNS struct {
 	Enable                bool             `yaml:"enable"`
@@ -91,6 +120,7 @@ type DNS struct {
 	DefaultNameserver     []dns.NameServer `yaml:"default-nameserver"`
 	FakeIPRange           *fakeip.Pool
 	Hosts                 *trie.DomainTrie[netip.Addr]
+	HostAliases           *trie.DomainTrie[string]
 	NameServerPolicy      map[string]dns.NameServer
 	ProxyServerNameserver []dns.NameServer
 }
@@ -124,6 +154,7 @@ type Tun struct {
 	Inet4Address           []ListenPrefix `yaml:"inet4-address" json:"inet4_address,omitempty"`
 	Inet6Address           []ListenPrefix `yaml:"inet6-address" json:"inet6_address,omitempty"`
 	StrictRoute            bool           `yaml:"strict-route" json:"strict_route,omitempty"`
+	AutoRedirect           bool           `yaml:"auto-redirect" json:"auto_redirect,omitempty"`
 	Inet4RouteAddress      []ListenPrefix `yaml:"inet4_route_address" json:"inet4_route_address,omitempty"`
 	Inet6RouteAddress      []ListenPrefix `yaml:"inet6_route_address" json:"inet6_route_address,omitempty"`
 	IncludeUID             []uint32       `yaml:"include-uid" json:"include_uid,omitempty"`
@@ -203,6 +234,9 @@ type Sniffer struct {
 	Ports           *[]utils.Range[uint16]
 	ForceDnsMapping bool
 	ParsePureIp     bool
+	PureIpOnly      bool
+
+	OverrideDestination bool
 }
 
 // Experimental config
@@ -218,11 +252,14 @@ type Config struct {
 	DNS           *DNS
 	Experimental  *Experimental
 	Hosts         *trie.DomainTrie[netip.Addr]
+	HostAliases   *trie.DomainTrie[string]
+	Tunnels       []Tunnel
 	Profile       *Profile
 	Rules         []C.Rule
 	SubRules      *map[string][]C.Rule
 	Users         []auth.AuthUser
 	Proxies       map[string]C.Proxy
+	Chains        map[string]C.Proxy // inline [A,B,C] rule targets, not listed by the API
 	Providers     map[string]providerTypes.ProxyProvider
 	RuleProviders map[string]providerTypes.RuleProvider
 	Sniffer       *Sniffer
@@ -239,7 +276,10 @@ type RawDNS struct {
 	Listen                string            `yaml:"listen"`
 	EnhancedMode          C.DNSMode         `yaml:"enhanced-mode"`
 	FakeIPRange           string            `yaml:"fake-ip-range"`
+	FakeIPRange6          string            `yaml:"fake-ip-range6"`
 	FakeIPFilter          []string          `yaml:"fake-ip-filter"`
+	FakeIPSize            int               `yaml:"fake-ip-size"`
+	FakeIPLRU             bool              `yaml:"fake-ip-lru"`
 	DefaultNameserver     []string          `yaml:"default-nameserver"`
 	NameServerPolicy      map[string]string `yaml:"nameserver-policy"`
 	ProxyServerNameserver []string          `yaml:"proxy-server-nameserver"`
@@ -266,6 +306,7 @@ type RawTun struct {
 	//Inet4Address           []ListenPrefix `yaml:"inet4-address" json:"inet4_address,omitempty"`
 	Inet6Address           []ListenPrefix `yaml:"inet6-address" json:"inet6_address,omitempty"`
 	StrictRoute            bool           `yaml:"strict-route" json:"strict_route,omitempty"`
+	AutoRedirect           bool           `yaml:"auto-redirect" json:"auto_redirect,omitempty"`
 	Inet4RouteAddress      []ListenPrefix `yaml:"inet4_route_address" json:"inet4_route_address,omitempty"`
 	Inet6RouteAddress      []ListenPrefix `yaml:"inet6_route_address" json:"inet6_route_address,omitempty"`
 	IncludeUID             []uint32       `yaml:"include-uid" json:"include_uid,omitempty"`
@@ -286,24 +327,39 @@ type RawConfig struct {
 	TProxyPort         int          `yaml:"tproxy-port"`
 	MixedPort          int          `yaml:"mixed-port"`
 	InboundTfo         bool         `yaml:"inbound-tfo"`
+	InboundBacklog     int          `yaml:"inbound-backlog"`
+	InboundAcceptRate  int          `yaml:"inbound-accept-rate"`
+	InboundAcceptBurst int          `yaml:"inbound-accept-burst"`
+	InboundMaxConn     int          `yaml:"inbound-max-connections"`
 	Authentication     []string     `yaml:"authentication"`
 	AllowLan           bool         `yaml:"allow-lan"`
 	BindAddress        string       `yaml:"bind-address"`
 	Mode               T.TunnelMode `yaml:"mode"`
 	UnifiedDelay       bool         `yaml:"unified-delay"`
+	LoopbackDetection  bool         `yaml:"loopback-detection"`
 	LogLevel           log.LogLevel `yaml:"log-level"`
 	IPv6               bool         `yaml:"ipv6"`
 	ExternalController string       `yaml:"external-controller"`
+	ControllerMixed    bool         `yaml:"external-controller-mixed"`
 	ExternalUI         string       `yaml:"external-ui"`
 	Secret             string       `yaml:"secret"`
+	SecretFile         string       `yaml:"secret-file"`
+	SecretEnv          string       `yaml:"secret-env"`
 	Interface          string       `yaml:"interface-name"`
 	RoutingMark        int          `yaml:"routing-mark"`
 	GeodataMode        bool         `yaml:"geodata-mode"`
 	GeodataLoader      string       `yaml:"geodata-loader"`
 	TCPConcurrent      bool         `yaml:"tcp-concurrent" json:"tcp-concurrent"`
 	EnableProcess      bool         `yaml:"enable-process" json:"enable-process"`
+	GroupFailClosed    bool         `yaml:"group-fail-closed" json:"group-fail-closed"`
+	AllowCommand       bool         `yaml:"allow-command-provider"`
+	FirstByteTimeout   int          `yaml:"first-byte-timeout" json:"first-byte-timeout"`
+	DialRetry          int          `yaml:"dial-retry" json:"dial-retry"`
+	RetryStrategy      string       `yaml:"dial-retry-strategy" json:"dial-retry-strategy"`
+	Tunnels            []Tunnel     `yaml:"tunnels"`
 
 	Sniffer       RawSniffer                `yaml:"sniffer"`
+	InboundUDP    map[string]bool           `yaml:"inbound-udp"`
 	ProxyProvider map[string]map[string]any `yaml:"proxy-providers"`
 	RuleProvider  map[string]map[string]any `yaml:"rule-providers"`
 	Hosts         map[string]string         `yaml:"hosts"`
@@ -334,6 +390,9 @@ type RawSniffer struct {
 	Ports           []string `yaml:"port-whitelist" json:"port-whitelist"`
 	ForceDnsMapping bool     `yaml:"force-dns-mapping" json:"force-dns-mapping"`
 	ParsePureIp     bool     `yaml:"parse-pure-ip" json:"parse-pure-ip"`
+	PureIpOnly      bool     `yaml:"pure-ip-only" json:"pure-ip-only"`
+
+	OverrideDestination bool `yaml:"override-destination" json:"override-destination"`
 }
 
 // EBpf config
@@ -349,8 +408,9 @@ var (
 )
 
 // Parse config
-func Parse(buf []byte) (*Config, error) {
-	rawCfg, err := UnmarshalRawConfig(buf)
+// Parse parses buf with the overrides merged over it in order, see UnmarshalRawConfig for the precedence
+func Parse(buf []byte, overrides ...[]byte) (*Config, error) {
+	rawCfg, err := UnmarshalRawConfig(buf, overrides...)
 	if err != nil {
 		return nil, err
 	}
@@ -358,7 +418,10 @@ func Parse(buf []byte) (*Config, error) {
 	return ParseRawConfig(rawCfg)
 }
 
-func UnmarshalRawConfig(buf []byte) (*RawConfig, error) {
+// UnmarshalRawConfig unmarshals buf, then each override over it. A later file replaces the
+// keys it sets and merges nested maps, except that proxies and proxy-groups are merged by name
+// and its rules are put before the existing ones, so they match first
+func UnmarshalRawConfig(buf []byte, overrides ...[]byte) (*RawConfig, error) {
 	// config with default value
 	rawCfg := &RawConfig{
 		AllowLan:       false,
@@ -400,6 +463,7 @@ func UnmarshalRawConfig(buf []byte) (*RawConfig, error) {
 			UseHosts:     true,
 			EnhancedMode: C.DNSMapping,
 			FakeIPRange:  "198.18.0.1/16",
+			FakeIPSize:   1000,
 			FallbackFilter: RawFallbackFilter{
 				GeoIP:     true,
 				GeoIPCode: "CN",
@@ -445,9 +509,47 @@ func UnmarshalRawConfig(buf []byte) (*RawConfig, error) {
 		return nil, err
 	}
 
+	for i, override := range overrides {
+		if err := mergeRawConfig(rawCfg, override); err != nil {
+			return nil, fmt.Errorf("merge override %d: %w", i+1, err)
+		}
+	}
+
 	return rawCfg, nil
 }
 
+func mergeRawConfig(rawCfg *RawConfig, buf []byte) error {
+	proxies, groups, rules := rawCfg.Proxy, rawCfg.ProxyGroup, rawCfg.Rule
+	rawCfg.Proxy, rawCfg.ProxyGroup, rawCfg.Rule = nil, nil, nil
+
+	if err := yaml.Unmarshal(buf, rawCfg); err != nil {
+		return err
+	}
+
+	rawCfg.Proxy = mergeByName(proxies, rawCfg.Proxy)
+	rawCfg.ProxyGroup = mergeByName(groups, rawCfg.ProxyGroup)
+	rawCfg.Rule = append(rawCfg.Rule, rules...)
+	return nil
+}
+
+// mergeByName replaces the mappings of base named like one of override and appends the others
+func mergeByName(base, override []map[string]any) []map[string]any {
+	index := map[any]int{}
+	for i, mapping := range base {
+		index[mapping["name"]] = i
+	}
+
+	for _, mapping := range override {
+		if i, ok := index[mapping["name"]]; ok {
+			base[i] = mapping
+			continue
+		}
+		index[mapping["name"]] = len(base)
+		base = append(base, mapping)
+	}
+	return base
+}
+
 func ParseRawConfig(rawCfg *RawConfig) (*Config, error) {
 	config := &Config{}
 	log.Infoln("Start initial configuration in progress") //Segment finished in xxm
@@ -471,26 +573,35 @@ func ParseRawConfig(rawCfg *RawConfig) (*Config, error) {
 	config.Proxies = proxies
 	config.Providers = providers
 
-	subRules, ruleProviders, err := parseSubRules(rawCfg, proxies)
+	tunnels, err := parseTunnels(rawCfg.Tunnels, proxies)
+	if err != nil {
+		return nil, err
+	}
+	config.Tunnels = tunnels
+
+	chains := map[string]C.Proxy{}
+	subRules, ruleProviders, err := parseSubRules(rawCfg, proxies, chains)
 	if err != nil {
 		return nil, err
 	}
 	config.SubRules = subRules
 	config.RuleProviders = ruleProviders
 
-	rules, err := parseRules(rawCfg, proxies, subRules)
+	rules, err := parseRules(rawCfg, proxies, chains, subRules)
 	if err != nil {
 		return nil, err
 	}
 	config.Rules = rules
+	config.Chains = chains
 
-	hosts, err := parseHosts(rawCfg)
+	hosts, aliases, err := parseHosts(rawCfg)
 	if err != nil {
 		return nil, err
 	}
 	config.Hosts = hosts
+	config.HostAliases = aliases
 
-	dnsCfg, err := parseDNS(rawCfg, hosts, rules)
+	dnsCfg, err := parseDNS(rawCfg, hosts, aliases, rules)
 	if err != nil {
 		return nil, err
 	}
@@ -525,6 +636,22 @@ func parseGeneral(cfg *RawConfig) (*General, error) {
 			return nil, fmt.Errorf("external-ui: %s not exist", externalUI)
 		}
 	}
+	for name := range cfg.InboundUDP {
+		switch name {
+		case "socks", "mixed", "redir", "tproxy":
+		default:
+			return nil, fmt.Errorf("inbound-udp: unknown inbound %s", name)
+		}
+	}
+
+	retryStrategy, err := T.ParseRetryStrategy(cfg.RetryStrategy)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := parseSecret(cfg)
+	if err != nil {
+		return nil, err
+	}
 	cfg.Tun.RedirectToTun = cfg.EBpf.RedirectToTun
 	return &General{
 		Inbound: Inbound{
@@ -536,26 +663,73 @@ func parseGeneral(cfg *RawConfig) (*General, error) {
 			AllowLan:    cfg.AllowLan,
 			BindAddress: cfg.BindAddress,
 			InboundTfo:  cfg.InboundTfo,
+			Backlog:     cfg.InboundBacklog,
+			AcceptRate:  cfg.InboundAcceptRate,
+			AcceptBurst: cfg.InboundAcceptBurst,
+			MaxConn:     cfg.InboundMaxConn,
+			UDP:         cfg.InboundUDP,
 		},
 		Controller: Controller{
 			ExternalController: cfg.ExternalController,
+			ControllerMixed:    cfg.ControllerMixed,
 			ExternalUI:         cfg.ExternalUI,
-			Secret:             cfg.Secret,
+			Secret:             secret,
 		},
-		UnifiedDelay:  cfg.UnifiedDelay,
-		Mode:          cfg.Mode,
-		LogLevel:      cfg.LogLevel,
-		IPv6:          cfg.IPv6,
-		Interface:     cfg.Interface,
-		RoutingMark:   cfg.RoutingMark,
-		GeodataMode:   cfg.GeodataMode,
-		GeodataLoader: cfg.GeodataLoader,
-		TCPConcurrent: cfg.TCPConcurrent,
-		EnableProcess: cfg.EnableProcess,
-		EBpf:          cfg.EBpf,
+		UnifiedDelay:      cfg.UnifiedDelay,
+		LoopbackDetection: cfg.LoopbackDetection,
+		Mode:              cfg.Mode,
+		LogLevel:          cfg.LogLevel,
+		IPv6:              cfg.IPv6,
+		Interface:         cfg.Interface,
+		RoutingMark:       cfg.RoutingMark,
+		GeodataMode:       cfg.GeodataMode,
+		GeodataLoader:     cfg.GeodataLoader,
+		TCPConcurrent:     cfg.TCPConcurrent,
+		EnableProcess:     cfg.EnableProcess,
+		FailClosed:        cfg.GroupFailClosed,
+		FirstByteTimeout:  cfg.FirstByteTimeout,
+		DialRetry:         cfg.DialRetry,
+		RetryStrategy:     retryStrategy,
+		EBpf:              cfg.EBpf,
+		AllowCommand:      cfg.AllowCommand,
 	}, nil
 }
 
+// parseSecret returns the controller secret from secret, secret-file or secret-env, only one of them can be set
+func parseSecret(cfg *RawConfig) (string, error) {
+	set := 0
+	for _, value := range []string{cfg.Secret, cfg.SecretFile, cfg.SecretEnv} {
+		if value != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return "", errors.New("only one of secret, secret-file and secret-env can be set")
+	}
+
+	switch {
+	case cfg.SecretFile != "":
+		path := C.Path.Resolve(cfg.SecretFile)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("secret-file: %w", err)
+		}
+		secret := strings.TrimSpace(string(data))
+		if secret == "" {
+			return "", fmt.Errorf("secret-file: %s is empty", path)
+		}
+		return secret, nil
+	case cfg.SecretEnv != "":
+		secret, ok := os.LookupEnv(cfg.SecretEnv)
+		if !ok || secret == "" {
+			return "", fmt.Errorf("secret-env: %s is not set", cfg.SecretEnv)
+		}
+		return secret, nil
+	default:
+		return cfg.Secret, nil
+	}
+}
+
 func parseProxies(cfg *RawConfig) (proxies map[string]C.Proxy, providersMap map[string]providerTypes.ProxyProvider, err error) {
 	proxies = make(map[string]C.Proxy)
 	providersMap = make(map[string]providerTypes.ProxyProvider)
@@ -569,9 +743,10 @@ func parseProxies(cfg *RawConfig) (proxies map[string]C.Proxy, providersMap map[
 
 	proxies["DIRECT"] = adapter.NewProxy(outbound.NewDirect())
 	proxies["REJECT"] = adapter.NewProxy(outbound.NewReject())
+	proxies["REJECT-ICMP"] = adapter.NewProxy(outbound.NewRejectICMP())
 	proxies["COMPATIBLE"] = adapter.NewProxy(outbound.NewCompatible())
 	proxies["PASS"] = adapter.NewProxy(outbound.NewPass())
-	proxyList = append(proxyList, "DIRECT", "REJECT")
+	proxyList = append(proxyList, "DIRECT", "REJECT", "REJECT-ICMP")
 
 	// parse proxy
 	for idx, mapping := range proxiesConfig {
@@ -639,7 +814,7 @@ func parseProxies(cfg *RawConfig) (proxies map[string]C.Proxy, providersMap map[
 		}
 		ps = append(ps, proxies[v])
 	}
-	hc := provider.NewHealthCheck(ps, "", 0, true)
+	hc := provider.NewHealthCheck(ps, "", nil, 0, 0, true, false, 0)
 	pd, _ := provider.NewCompatibleProvider(provider.ReservedName, ps, hc)
 	providersMap[provider.ReservedName] = pd
 
@@ -659,7 +834,7 @@ func parseProxies(cfg *RawConfig) (proxies map[string]C.Proxy, providersMap map[
 	return proxies, providersMap, nil
 }
 
-func parseSubRules(cfg *RawConfig, proxies map[string]C.Proxy) (subRules *map[string][]C.Rule, ruleProviders map[string]providerTypes.RuleProvider, err error) {
+func parseSubRules(cfg *RawConfig, proxies map[string]C.Proxy, chains map[string]C.Proxy) (subRules *map[string][]C.Rule, ruleProviders map[string]providerTypes.RuleProvider, err error) {
 	ruleProviders = map[string]providerTypes.RuleProvider{}
 	subRules = &map[string][]C.Rule{}
 	log.Infoln("Geodata Loader mode: %s", geodata.LoaderName())
@@ -677,7 +852,7 @@ func parseSubRules(cfg *RawConfig, proxies map[string]C.Proxy) (subRules *map[st
 	for name, rawRules := range cfg.SubRules {
 		var rules []C.Rule
 		for idx, line := range rawRules {
-			rawRule := trimArr(strings.Split(line, ","))
+			rawRule := splitRule(line)
 			var (
 				payload  string
 				target   string
@@ -708,11 +883,15 @@ func parseSubRules(cfg *RawConfig, proxies map[string]C.Proxy) (subRules *map[st
 				params = rawRule[l:]
 			}
 
-			if _, ok := proxies[target]; !ok && ruleName != "SUB-RULE" {
+			if _, ok := proxies[target]; !ok && ruleName != "SUB-RULE" && !parseInterfaceTarget(proxies, target) && !parseChainTarget(proxies, chains, target) {
 				return nil, nil, fmt.Errorf("sub-rules[%d:%s] [%s] error: proxy [%s] not found", idx, name, line, target)
 			}
 
 			params = trimArr(params)
+			// the egress is marked by the matched top level rule, the SUB-RULE one for these
+			if RC.HasDSCPParam(params) {
+				return nil, nil, fmt.Errorf("sub-rules[%d] [%s] error: dscp param takes no effect inside sub-rules, set it on the SUB-RULE rule", idx, line)
+			}
 			parsed, parseErr := R.ParseRule(ruleName, payload, target, params, subRules)
 			if parseErr != nil {
 				return nil, nil, fmt.Errorf("sub-rules[%d] [%s] error: %s", idx, line, parseErr.Error())
@@ -769,13 +948,53 @@ func verifySubRuleCircularReferences(n string, subRules *map[string][]C.Rule, ar
 	return nil
 }
 
-func parseRules(cfg *RawConfig, proxies map[string]C.Proxy, subRules *map[string][]C.Rule) ([]C.Rule, error) {
+// parseInterfaceTarget adds the DIRECT@<interface-name> target to proxies on first use
+func parseInterfaceTarget(proxies map[string]C.Proxy, target string) bool {
+	name, ifaceName, found := strings.Cut(target, "@")
+	if !found || name != "DIRECT" || ifaceName == "" {
+		return false
+	}
+
+	proxies[target] = adapter.NewProxy(outbound.NewDirectWithInterface(ifaceName))
+	return true
+}
+
+// parseChainTarget adds the inline relay target [A,B,C] to chains on first use, every hop must be a known proxy
+func parseChainTarget(proxies map[string]C.Proxy, chains map[string]C.Proxy, target string) bool {
+	if !strings.HasPrefix(target, "[") || !strings.HasSuffix(target, "]") {
+		return false
+	}
+	if _, ok := chains[target]; ok {
+		return true
+	}
+
+	var ps []C.Proxy
+	for _, name := range strings.Split(target[1:len(target)-1], ",") {
+		proxy, ok := proxies[name]
+		if !ok {
+			return false
+		}
+		ps = append(ps, proxy)
+	}
+
+	hc := provider.NewHealthCheck(ps, "", nil, 0, 0, true, false, 0)
+	pd, err := provider.NewCompatibleProvider(target, ps, hc)
+	if err != nil {
+		return false
+	}
+
+	relay := outboundgroup.NewRelay(&outboundgroup.GroupCommonOption{Name: target}, []providerTypes.ProxyProvider{pd})
+	chains[target] = adapter.NewProxy(relay)
+	return true
+}
+
+func parseRules(cfg *RawConfig, proxies map[string]C.Proxy, chains map[string]C.Proxy, subRules *map[string][]C.Rule) ([]C.Rule, error) {
 	var rules []C.Rule
 	rulesConfig := cfg.Rule
 
 	// parse rules
 	for idx, line := range rulesConfig {
-		rule := trimArr(strings.Split(line, ","))
+		rule := splitRule(line)
 		var (
 			payload  string
 			target   string
@@ -805,7 +1024,7 @@ func parseRules(cfg *RawConfig, proxies map[string]C.Proxy, subRules *map[string
 			target = rule[l-1]
 			params = rule[l:]
 		}
-		if _, ok := proxies[target]; !ok {
+		if _, ok := proxies[target]; !ok && !parseInterfaceTarget(proxies, target) && !parseChainTarget(proxies, chains, target) {
 			if ruleName != "SUB-RULE" {
 				return nil, fmt.Errorf("rules[%d] [%s] error: proxy [%s] not found", idx, line, target)
 			} else if _, ok = (*subRules)[target]; !ok {
@@ -827,8 +1046,9 @@ func parseRules(cfg *RawConfig, proxies map[string]C.Proxy, subRules *map[string
 	return rules, nil
 }
 
-func parseHosts(cfg *RawConfig) (*trie.DomainTrie[netip.Addr], error) {
+func parseHosts(cfg *RawConfig) (*trie.DomainTrie[netip.Addr], *trie.DomainTrie[string], error) {
 	tree := trie.New[netip.Addr]()
+	aliases := trie.New[string]()
 
 	// add default hosts
 	if err := tree.Insert("localhost", netip.AddrFrom4([4]byte{127, 0, 0, 1})); err != nil {
@@ -839,13 +1059,42 @@ func parseHosts(cfg *RawConfig) (*trie.DomainTrie[netip.Addr], error) {
 		for domain, ipStr := range cfg.Hosts {
 			ip, err := netip.ParseAddr(ipStr)
 			if err != nil {
-				return nil, fmt.Errorf("%s is not a valid IP", ipStr)
+				// a domain value aliases the host, which then resolves like the target
+				if _, ok := trie.ValidAndSplitDomain(ipStr); !ok || strings.ContainsAny(ipStr, "*+ ") {
+					return nil, nil, fmt.Errorf("%s is not a valid IP or domain", ipStr)
+				}
+				_ = aliases.Insert(domain, ipStr)
+				continue
 			}
 			_ = tree.Insert(domain, ip)
 		}
 	}
 
-	return tree, nil
+	return tree, aliases, nil
+}
+
+func parseTunnels(tunnels []Tunnel, proxies map[string]C.Proxy) ([]Tunnel, error) {
+	for idx, tunnel := range tunnels {
+		if _, _, err := net.SplitHostPort(tunnel.Address); err != nil {
+			return nil, fmt.Errorf("tunnel %d address error: %w", idx, err)
+		}
+		host, port, err := net.SplitHostPort(tunnel.Target)
+		if err != nil {
+			return nil, fmt.Errorf("tunnel %d target error: %w", idx, err)
+		}
+		if portNum, err := strconv.ParseUint(port, 10, 16); err != nil || portNum == 0 || host == "" {
+			return nil, fmt.Errorf("tunnel %d target error: invalid address %s", idx, tunnel.Target)
+		}
+		addr := socks5.ParseAddr(tunnel.Target)
+		if addr == nil {
+			return nil, fmt.Errorf("tunnel %d target error: invalid address %s", idx, tunnel.Target)
+		}
+		if _, ok := proxies[tunnel.Proxy]; !ok {
+			return nil, fmt.Errorf("tunnel %d proxy %s not found", idx, tunnel.Proxy)
+		}
+		tunnels[idx].TargetAddr = addr
+	}
+	return tunnels, nil
 }
 
 func hostWithDefaultPort(host string, defPort string) (string, error) {
@@ -865,7 +1114,7 @@ func hostWithDefaultPort(host string, defPort string) (string, error) {
 	return net.JoinHostPort(hostname, port), nil
 }
 
-func parseNameServer(servers []string) ([]dns.NameServer, error) {
+func parseNameServer(servers []string, preferH3 bool) ([]dns.NameServer, error) {
 	var nameservers []dns.NameServer
 
 	for idx, server := range servers {
@@ -930,29 +1179,59 @@ func parseNameServer(servers []string) ([]dns.NameServer, error) {
 				ProxyAdapter: proxyAdapter,
 				Interface:    dialer.DefaultInterface,
 				Params:       params,
+				PreferH3:     preferH3,
 			},
 		)
 	}
 	return nameservers, nil
 }
 
-func parseNameServerPolicy(nsPolicy map[string]string) (map[string]dns.NameServer, error) {
+func parseNameServerPolicy(nsPolicy map[string]string, preferH3 bool) (map[string]dns.NameServer, error) {
 	policy := map[string]dns.NameServer{}
 
 	for domain, server := range nsPolicy {
-		nameservers, err := parseNameServer([]string{server})
+		nameservers, err := parseNameServer([]string{server}, preferH3)
 		if err != nil {
 			return nil, err
 		}
 		if _, valid := trie.ValidAndSplitDomain(domain); !valid {
 			return nil, fmt.Errorf("DNS ResoverRule invalid domain: %s", domain)
 		}
-		policy[domain] = nameservers[0]
+
+		ns := nameservers[0]
+		if ns.ClientSubnet, ns.ClientSubnetOverride, err = parseClientSubnet(server); err != nil {
+			return nil, fmt.Errorf("DNS ResoverRule %s: %w", domain, err)
+		}
+		// the DoH client must not take them for its own params
+		delete(ns.Params, "ecs")
+		delete(ns.Params, "ecs-override")
+		policy[domain] = ns
 	}
 
 	return policy, nil
 }
 
+// parseClientSubnet reads ecs=subnet and ecs-override=true from the fragment of a nameserver-policy server
+func parseClientSubnet(server string) (subnet netip.Prefix, override bool, err error) {
+	_, fragment, found := strings.Cut(server, "#")
+	if !found {
+		return
+	}
+
+	for _, s := range strings.Split(fragment, "&") {
+		key, value, _ := strings.Cut(s, "=")
+		switch key {
+		case "ecs":
+			if subnet, err = netip.ParsePrefix(value); err != nil {
+				return subnet, false, fmt.Errorf("ecs format error: %w", err)
+			}
+		case "ecs-override":
+			override = value == "true"
+		}
+	}
+	return
+}
+
 func parseFallbackIPCIDR(ips []string) ([]*netip.Prefix, error) {
 	var ipNets []*netip.Prefix
 
@@ -967,6 +1246,18 @@ func parseFallbackIPCIDR(ips []string) ([]*netip.Prefix, error) {
 	return ipNets, nil
 }
 
+func parseFakeIPGeoSite(code string) (*router.DomainMatcher, error) {
+	if err := geodata.InitGeoSite(); err != nil {
+		return nil, fmt.Errorf("can't initial GeoSite: %s", err)
+	}
+	matcher, recordsCount, err := geodata.LoadGeoSiteMatcher(code)
+	if err != nil {
+		return nil, fmt.Errorf("fake-ip-filter geosite:%s error: %w", code, err)
+	}
+	log.Infoln("Start initial GeoSite fake-ip filter `%s`, records: %d", code, recordsCount)
+	return matcher, nil
+}
+
 func parseFallbackGeoSite(countries []string, rules []C.Rule) ([]*router.DomainMatcher, error) {
 	var sites []*router.DomainMatcher
 	if len(countries) > 0 {
@@ -979,9 +1270,10 @@ func parseFallbackGeoSite(countries []string, rules []C.Rule) ([]*router.DomainM
 		found := false
 		for _, rule := range rules {
 			if rule.RuleType() == C.GEOSITE {
-				if strings.EqualFold(country, rule.Payload()) {
+				geoSite, ok := rule.(C.RuleGeoSite)
+				if ok && strings.EqualFold(country, rule.Payload()) {
 					found = true
-					sites = append(sites, rule.(C.RuleGeoSite).GetDomainMatcher())
+					sites = append(sites, geoSite.GetDomainMatcher())
 					log.Infoln("Start initial GeoSite dns fallback filter from rule `%s`", country)
 				}
 			}
@@ -1002,7 +1294,7 @@ func parseFallbackGeoSite(countries []string, rules []C.Rule) ([]*router.DomainM
 	return sites, nil
 }
 
-func parseDNS(rawCfg *RawConfig, hosts *trie.DomainTrie[netip.Addr], rules []C.Rule) (*DNS, error) {
+func parseDNS(rawCfg *RawConfig, hosts *trie.DomainTrie[netip.Addr], aliases *trie.DomainTrie[string], rules []C.Rule) (*DNS, error) {
 	cfg := rawCfg.DNS
 	if cfg.Enable && len(cfg.NameServer) == 0 {
 		return nil, fmt.Errorf("if DNS configuration is turned on, NameServer cannot be empty")
@@ -1020,26 +1312,26 @@ func parseDNS(rawCfg *RawConfig, hosts *trie.DomainTrie[netip.Addr], rules []C.R
 		},
 	}
 	var err error
-	if dnsCfg.NameServer, err = parseNameServer(cfg.NameServer); err != nil {
+	if dnsCfg.NameServer, err = parseNameServer(cfg.NameServer, cfg.PreferH3); err != nil {
 		return nil, err
 	}
 
-	if dnsCfg.Fallback, err = parseNameServer(cfg.Fallback); err != nil {
+	if dnsCfg.Fallback, err = parseNameServer(cfg.Fallback, cfg.PreferH3); err != nil {
 		return nil, err
 	}
 
-	if dnsCfg.NameServerPolicy, err = parseNameServerPolicy(cfg.NameServerPolicy); err != nil {
+	if dnsCfg.NameServerPolicy, err = parseNameServerPolicy(cfg.NameServerPolicy, cfg.PreferH3); err != nil {
 		return nil, err
 	}
 
-	if dnsCfg.ProxyServerNameserver, err = parseNameServer(cfg.ProxyServerNameserver); err != nil {
+	if dnsCfg.ProxyServerNameserver, err = parseNameServer(cfg.ProxyServerNameserver, cfg.PreferH3); err != nil {
 		return nil, err
 	}
 
 	if len(cfg.DefaultNameserver) == 0 {
 		return nil, errors.New("default nameserver should have at least one nameserver")
 	}
-	if dnsCfg.DefaultNameserver, err = parseNameServer(cfg.DefaultNameserver); err != nil {
+	if dnsCfg.DefaultNameserver, err = parseNameServer(cfg.DefaultNameserver, cfg.PreferH3); err != nil {
 		return nil, err
 	}
 	// check default nameserver is pure ip addr
@@ -1062,10 +1354,19 @@ func parseDNS(rawCfg *RawConfig, hosts *trie.DomainTrie[netip.Addr], rules []C.R
 		}
 
 		var host *trie.DomainTrie[bool]
-		// fake ip skip host filter
+		var geoSite []*router.DomainMatcher
+		// fake ip skip host filter, geosite:category entries skip the domains of the category
 		if len(cfg.FakeIPFilter) != 0 {
 			host = trie.New[bool]()
 			for _, domain := range cfg.FakeIPFilter {
+				if strings.HasPrefix(domain, "geosite:") {
+					matcher, err := parseFakeIPGeoSite(strings.TrimPrefix(domain, "geosite:"))
+					if err != nil {
+						return nil, err
+					}
+					geoSite = append(geoSite, matcher)
+					continue
+				}
 				_ = host.Insert(domain, true)
 			}
 		}
@@ -1082,10 +1383,22 @@ func parseDNS(rawCfg *RawConfig, hosts *trie.DomainTrie[netip.Addr], rules []C.R
 			}
 		}
 
+		var ipnet6 *netip.Prefix
+		if cfg.FakeIPRange6 != "" {
+			prefix, err := netip.ParsePrefix(cfg.FakeIPRange6)
+			if err != nil {
+				return nil, fmt.Errorf("fake-ip-range6 error: %w", err)
+			}
+			ipnet6 = &prefix
+		}
+
 		pool, err := fakeip.New(fakeip.Options{
 			IPNet:       &ipnet,
-			Size:        1000,
+			IPNet6:      ipnet6,
+			Size:        cfg.FakeIPSize,
+			LRU:         cfg.FakeIPLRU,
 			Host:        host,
+			GeoSite:     geoSite,
 			Persistence: rawCfg.Profile.StoreFakeIP,
 		})
 		if err != nil {
@@ -1111,6 +1424,7 @@ func parseDNS(rawCfg *RawConfig, hosts *trie.DomainTrie[netip.Addr], rules []C.R
 
 	if cfg.UseHosts {
 		dnsCfg.Hosts = hosts
+		dnsCfg.HostAliases = aliases
 	}
 
 	return dnsCfg, nil
@@ -1163,6 +1477,7 @@ func parseTun(rawTun RawTun, general *General, dnsCfg *DNS) (*Tun, error) {
 		Inet4Address:           []ListenPrefix{ListenPrefix(tunAddressPrefix)},
 		Inet6Address:           rawTun.Inet6Address,
 		StrictRoute:            rawTun.StrictRoute,
+		AutoRedirect:           rawTun.AutoRedirect,
 		Inet4RouteAddress:      rawTun.Inet4RouteAddress,
 		Inet6RouteAddress:      rawTun.Inet6RouteAddress,
 		IncludeUID:             rawTun.IncludeUID,
@@ -1182,6 +1497,13 @@ func parseSniffer(snifferRaw RawSniffer) (*Sniffer, error) {
 		Enable:          snifferRaw.Enable,
 		ForceDnsMapping: snifferRaw.ForceDnsMapping,
 		ParsePureIp:     snifferRaw.ParsePureIp,
+		PureIpOnly:      snifferRaw.PureIpOnly,
+
+		OverrideDestination: snifferRaw.OverrideDestination,
+	}
+
+	if sniffer.PureIpOnly && sniffer.OverrideDestination {
+		return nil, errors.New("sniffer pure-ip-only conflicts with override-destination")
 	}
 
 	var ports []utils.Range[uint16]
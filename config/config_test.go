@@ -0,0 +1,244 @@
+package config
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dreamacro/clash/adapter"
+	"github.com/Dreamacro/clash/adapter/outbound"
+	C "github.com/Dreamacro/clash/constant"
+	"github.com/Dreamacro/clash/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTunnels(t *testing.T) {
+	proxies := map[string]C.Proxy{"DIRECT": adapter.NewProxy(outbound.NewDirect())}
+
+	for _, target := range []string{"example.com:abc", "example.com:99999", "example.com:", "example.com:0", ":80", "example.com"} {
+		_, err := parseTunnels([]Tunnel{{Address: "127.0.0.1:6553", Target: target, Proxy: "DIRECT"}}, proxies)
+		assert.Errorf(t, err, "target %s should be refused", target)
+	}
+
+	tunnels, err := parseTunnels([]Tunnel{{Address: "127.0.0.1:6553", Target: "8.8.8.8:53", Proxy: "DIRECT"}}, proxies)
+	assert.NoError(t, err)
+	assert.Equal(t, "8.8.8.8:53", tunnels[0].TargetAddr.String())
+
+	_, err = parseTunnels([]Tunnel{{Address: "127.0.0.1:6553", Target: "8.8.8.8:53", Proxy: "missing"}}, proxies)
+	assert.Error(t, err)
+}
+
+func TestUnmarshalRawConfig_Overrides(t *testing.T) {
+	base := `
+mixed-port: 7890
+log-level: warning
+dns:
+  enable: true
+  ipv6: false
+  nameserver:
+    - 223.5.5.5
+proxy-providers:
+  base:
+    type: file
+    path: base.yaml
+proxies:
+  - {name: a, type: socks5, server: 1.1.1.1, port: 1080}
+  - {name: b, type: socks5, server: 2.2.2.2, port: 1080}
+proxy-groups:
+  - {name: g, type: select, proxies: [a]}
+rules:
+  - DOMAIN,base.com,a
+  - MATCH,g
+`
+
+	tests := []struct {
+		name     string
+		override string
+		check    func(t *testing.T, rawCfg *RawConfig)
+	}{
+		{
+			name:     "scalar override",
+			override: "mixed-port: 7891",
+			check: func(t *testing.T, rawCfg *RawConfig) {
+				assert.Equal(t, 7891, rawCfg.MixedPort)
+				assert.Equal(t, log.WARNING, rawCfg.LogLevel)
+			},
+		},
+		{
+			name: "nested map merge",
+			override: `
+dns:
+  ipv6: true
+proxy-providers:
+  extra:
+    type: file
+    path: extra.yaml
+`,
+			check: func(t *testing.T, rawCfg *RawConfig) {
+				assert.True(t, rawCfg.DNS.Enable)
+				assert.True(t, rawCfg.DNS.IPv6)
+				assert.Equal(t, []string{"223.5.5.5"}, rawCfg.DNS.NameServer)
+				assert.Len(t, rawCfg.ProxyProvider, 2)
+				assert.Equal(t, "base.yaml", rawCfg.ProxyProvider["base"]["path"])
+			},
+		},
+		{
+			name: "proxies and groups replace by name",
+			override: `
+proxies:
+  - {name: b, type: socks5, server: 3.3.3.3, port: 1080}
+  - {name: c, type: socks5, server: 4.4.4.4, port: 1080}
+proxy-groups:
+  - {name: g, type: select, proxies: [b, c]}
+`,
+			check: func(t *testing.T, rawCfg *RawConfig) {
+				var names, servers []any
+				for _, p := range rawCfg.Proxy {
+					names = append(names, p["name"])
+					servers = append(servers, p["server"])
+				}
+				assert.Equal(t, []any{"a", "b", "c"}, names)
+				assert.Equal(t, []any{"1.1.1.1", "3.3.3.3", "4.4.4.4"}, servers)
+				assert.Len(t, rawCfg.ProxyGroup, 1)
+				assert.Equal(t, []any{"b", "c"}, rawCfg.ProxyGroup[0]["proxies"])
+			},
+		},
+		{
+			name:     "rules come first",
+			override: "rules:\n  - DOMAIN,override.com,b\n",
+			check: func(t *testing.T, rawCfg *RawConfig) {
+				assert.Equal(t, []string{"DOMAIN,override.com,b", "DOMAIN,base.com,a", "MATCH,g"}, rawCfg.Rule)
+			},
+		},
+		{
+			name:     "no keys leave the base untouched",
+			override: "{}",
+			check: func(t *testing.T, rawCfg *RawConfig) {
+				assert.Equal(t, 7890, rawCfg.MixedPort)
+				assert.Len(t, rawCfg.Proxy, 2)
+				assert.Len(t, rawCfg.ProxyGroup, 1)
+				assert.Equal(t, []string{"DOMAIN,base.com,a", "MATCH,g"}, rawCfg.Rule)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawCfg, err := UnmarshalRawConfig([]byte(base), []byte(tt.override))
+			if !assert.NoError(t, err) {
+				return
+			}
+			tt.check(t, rawCfg)
+		})
+	}
+
+	_, err := UnmarshalRawConfig([]byte(base), []byte("mixed-port: [1"))
+	assert.Error(t, err)
+}
+
+func TestParseSecret(t *testing.T) {
+	home := C.Path.HomeDir()
+	defer C.SetHomeDir(home)
+	dir := t.TempDir()
+	C.SetHomeDir(dir)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("  from-file\n"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "empty.txt"), []byte(" \n"), 0o600))
+	t.Setenv("CLASH_TEST_SECRET", "from-env")
+
+	tests := []struct {
+		name    string
+		cfg     RawConfig
+		want    string
+		wantErr bool
+	}{
+		{name: "plain", cfg: RawConfig{Secret: "plain"}, want: "plain"},
+		{name: "none", cfg: RawConfig{}, want: ""},
+		{name: "file relative to the home dir, trimmed", cfg: RawConfig{SecretFile: "secret.txt"}, want: "from-file"},
+		{name: "file absolute", cfg: RawConfig{SecretFile: filepath.Join(dir, "secret.txt")}, want: "from-file"},
+		{name: "env", cfg: RawConfig{SecretEnv: "CLASH_TEST_SECRET"}, want: "from-env"},
+		{name: "secret and file", cfg: RawConfig{Secret: "plain", SecretFile: "secret.txt"}, wantErr: true},
+		{name: "file and env", cfg: RawConfig{SecretFile: "secret.txt", SecretEnv: "CLASH_TEST_SECRET"}, wantErr: true},
+		{name: "empty file", cfg: RawConfig{SecretFile: "empty.txt"}, wantErr: true},
+		{name: "missing file", cfg: RawConfig{SecretFile: "missing.txt"}, wantErr: true},
+		{name: "unset env", cfg: RawConfig{SecretEnv: "CLASH_TEST_SECRET_UNSET"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, err := parseSecret(&tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, secret)
+		})
+	}
+}
+
+func TestParseRules_ChainTarget(t *testing.T) {
+	proxies := map[string]C.Proxy{
+		"DIRECT": adapter.NewProxy(outbound.NewDirect()),
+		"REJECT": adapter.NewProxy(outbound.NewReject()),
+	}
+	chains := map[string]C.Proxy{}
+	cfg := &RawConfig{Rule: []string{"DOMAIN,a.com,[DIRECT, REJECT]", "MATCH,[DIRECT,REJECT]"}}
+
+	rules, err := parseRules(cfg, proxies, chains, &map[string][]C.Rule{})
+	assert.NoError(t, err)
+	assert.Len(t, rules, 2)
+	assert.Contains(t, chains, "[DIRECT,REJECT]")
+	assert.Len(t, chains, 1)
+	assert.NotContains(t, proxies, "[DIRECT,REJECT]")
+
+	_, err = parseRules(&RawConfig{Rule: []string{"MATCH,[DIRECT,missing]"}}, proxies, chains, &map[string][]C.Rule{})
+	assert.Error(t, err)
+}
+
+func TestParseNameServerPolicy_ClientSubnet(t *testing.T) {
+	policy, err := parseNameServerPolicy(map[string]string{
+		"+.qq.com":     "https://doh.pub/dns-query#ecs=114.114.114.0/24",
+		"+.google.com": "tls://8.8.8.8#ecs=1.0.0.0/24&ecs-override=true",
+		"+.proxy.com":  "https://1.1.1.1/dns-query#proxy&ecs=2001:db8::/56",
+		"+.plain.com":  "8.8.8.8",
+	}, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	qq := policy["+.qq.com"]
+	assert.Equal(t, netip.MustParsePrefix("114.114.114.0/24"), qq.ClientSubnet)
+	assert.False(t, qq.ClientSubnetOverride)
+	assert.NotContains(t, qq.Params, "ecs")
+
+	google := policy["+.google.com"]
+	assert.Equal(t, "8.8.8.8:853", google.Addr)
+	assert.Equal(t, netip.MustParsePrefix("1.0.0.0/24"), google.ClientSubnet)
+	assert.True(t, google.ClientSubnetOverride)
+	assert.Empty(t, google.ProxyAdapter)
+
+	proxy := policy["+.proxy.com"]
+	assert.Equal(t, "proxy", proxy.ProxyAdapter)
+	assert.Equal(t, netip.MustParsePrefix("2001:db8::/56"), proxy.ClientSubnet)
+	assert.Empty(t, proxy.Params)
+
+	assert.False(t, policy["+.plain.com"].ClientSubnet.IsValid())
+
+	_, err = parseNameServerPolicy(map[string]string{"+.bad.com": "tls://8.8.8.8#ecs=1.0.0.0"}, false)
+	assert.Error(t, err)
+}
+
+func TestParseNameServer_FragmentOnlyForHTTPS(t *testing.T) {
+	nameservers, err := parseNameServer([]string{"tls://8.8.8.8#proxy", "https://1.1.1.1/dns-query#proxy&h3=true"}, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Empty(t, nameservers[0].ProxyAdapter)
+	assert.Empty(t, nameservers[0].Params)
+	assert.Equal(t, "proxy", nameservers[1].ProxyAdapter)
+	assert.Equal(t, map[string]string{"h3": "true"}, nameservers[1].Params)
+}
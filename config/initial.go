@@ -109,7 +109,15 @@ func Init(dir string) error {
 		f.Close()
 	}
 	buf, _ := os.ReadFile(C.Path.Config())
-	rawCfg, err := UnmarshalRawConfig(buf)
+	var overrides [][]byte
+	for _, override := range C.Path.ConfigOverrides() {
+		data, err := os.ReadFile(override)
+		if err != nil {
+			return fmt.Errorf("can't read config override %s: %w", override, err)
+		}
+		overrides = append(overrides, data)
+	}
+	rawCfg, err := UnmarshalRawConfig(buf, overrides...)
 	if err != nil {
 		log.Errorln(err.Error())
 		fmt.Printf("configuration file %s test failed\n", C.Path.Config())
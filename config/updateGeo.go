@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/Dreamacro/clash/component/geodata"
 	_ "github.com/Dreamacro/clash/component/geodata/standard"
+	"github.com/Dreamacro/clash/component/mmdb"
 	C "github.com/Dreamacro/clash/constant"
 	"github.com/oschwald/geoip2-golang"
 	"io"
@@ -48,6 +49,10 @@ func UpdateGeoDatabases() error {
 		if saveFile(data, C.Path.MMDB()) != nil {
 			return fmt.Errorf("can't save MMDB database file: %w", err)
 		}
+
+		if err := mmdb.Reload(); err != nil {
+			return fmt.Errorf("can't reload MMDB database file: %w", err)
+		}
 	}
 
 	data, err := downloadForBytes(C.GeoSiteUrl)
@@ -66,6 +71,24 @@ func UpdateGeoDatabases() error {
 	return nil
 }
 
+// ReloadGeoDatabases checks the databases on disk and swaps in the MMDB,
+// GeoIP and GeoSite matchers are rebuilt when the config is applied again
+func ReloadGeoDatabases() error {
+	if C.GeodataMode {
+		if err := geodata.Verify(C.GeoipName); err != nil {
+			return fmt.Errorf("invalid GeoIP database file: %w", err)
+		}
+	} else if err := mmdb.Reload(); err != nil {
+		return fmt.Errorf("invalid MMDB database file: %w", err)
+	}
+
+	if err := geodata.Verify(C.GeositeName); err != nil {
+		return fmt.Errorf("invalid GeoSite database file: %w", err)
+	}
+
+	return nil
+}
+
 func downloadForBytes(url string) ([]byte, error) {
 	resp, err := http.Get(url)
 	if err != nil {
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/Dreamacro/clash/adapter/outboundgroup"
@@ -15,6 +16,39 @@ func trimArr(arr []string) (r []string) {
 	return
 }
 
+// splitRule splits a rule line by comma, an inline chain target like [A, B] stays one field in the form [A,B]
+func splitRule(line string) []string {
+	var fields []string
+	depth, start := 0, 0
+	for i, c := range line {
+		switch c {
+		case '[':
+			// only a field starting with [ is a chain, regex payloads keep their brackets
+			if depth > 0 || strings.TrimSpace(line[start:i]) == "" {
+				depth++
+			}
+		case ']':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				fields = append(fields, line[start:i])
+				start = i + 1
+			}
+		}
+	}
+	fields = append(fields, line[start:])
+
+	fields = trimArr(fields)
+	for i, field := range fields {
+		if strings.HasPrefix(field, "[") && strings.HasSuffix(field, "]") {
+			fields[i] = "[" + strings.Join(trimArr(strings.Split(field[1:len(field)-1], ",")), ",") + "]"
+		}
+	}
+	return fields
+}
+
 // Check if ProxyGroups form DAG(Directed Acyclic Graph), and sort all ProxyGroups by dependency order.
 // Meanwhile, record the original index in the config file.
 // If loop is detected, return an error with location of loop.
@@ -41,6 +75,11 @@ func proxyGroupsDagSort(groupsConfig []map[string]any) error {
 			return fmt.Errorf("ProxyGroup %s: %s", option.Name, err.Error())
 		}
 
+		// the group a fallback cascades to must be built first, like its members
+		if option.FallbackTo != "" {
+			option.Proxies = append(option.Proxies, option.FallbackTo)
+		}
+
 		groupName := option.Name
 		if node, ok := graph[groupName]; ok {
 			if node.data != nil {
@@ -142,7 +181,27 @@ func proxyGroupsDagSort(groupsConfig []map[string]any) error {
 	loopElements := make([]string, 0, len(graph))
 	for name := range graph {
 		loopElements = append(loopElements, name)
-		delete(graph, name)
 	}
-	return fmt.Errorf("loop is detected in ProxyGroup, please check following ProxyGroups: %v", loopElements)
+	sort.Strings(loopElements)
+
+	// Step 2.4 every remaining node points to another remaining one, walk from any of them to find a cycle
+	visited := make(map[string]int)
+	path := make([]string, 0, len(graph))
+	for name := loopElements[0]; ; {
+		if idx, ok := visited[name]; ok {
+			path = append(path[idx:], name)
+			break
+		}
+		visited[name] = len(path)
+		path = append(path, name)
+
+		for _, proxy := range graph[name].option.Proxies {
+			if _, ok := graph[proxy]; ok {
+				name = proxy
+				break
+			}
+		}
+	}
+
+	return fmt.Errorf("loop is detected in ProxyGroup: %s, please check following ProxyGroups: %v", strings.Join(path, " -> "), loopElements)
 }
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitRule(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{line: "MATCH,DIRECT", want: []string{"MATCH", "DIRECT"}},
+		{line: "DOMAIN-SUFFIX, google.com , proxy", want: []string{"DOMAIN-SUFFIX", "google.com", "proxy"}},
+		{line: "IP-CIDR,10.0.0.0/8,DIRECT,no-resolve", want: []string{"IP-CIDR", "10.0.0.0/8", "DIRECT", "no-resolve"}},
+		{line: "MATCH,[ss1,vmess1]", want: []string{"MATCH", "[ss1,vmess1]"}},
+		{line: "DOMAIN,example.com,[ ss1 , vmess1, trojan ],no-resolve", want: []string{"DOMAIN", "example.com", "[ss1,vmess1,trojan]", "no-resolve"}},
+		{line: "DOMAIN,example.com, [ss1,vmess1]", want: []string{"DOMAIN", "example.com", "[ss1,vmess1]"}},
+		// a bracket inside a payload is not a chain and keeps splitting on commas
+		{line: "DOMAIN-REGEX,^a[0-9]b$,DIRECT", want: []string{"DOMAIN-REGEX", "^a[0-9]b$", "DIRECT"}},
+		{line: "DOMAIN-REGEX,x[a,b],DIRECT", want: []string{"DOMAIN-REGEX", "x[a", "b]", "DIRECT"}},
+		{line: "AND,((DOMAIN,a.com),(NETWORK,UDP)),[ss1,vmess1]", want: []string{"AND", "((DOMAIN", "a.com)", "(NETWORK", "UDP))", "[ss1,vmess1]"}},
+		{line: "MATCH,[ss1", want: []string{"MATCH", "[ss1"}},
+		{line: "", want: []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitRule(tt.line))
+		})
+	}
+}
@@ -2,10 +2,12 @@ package constant
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"time"
 
+	"github.com/Dreamacro/clash/common/utils"
 	"github.com/Dreamacro/clash/component/dialer"
 )
 
@@ -31,6 +33,7 @@ const (
 	Vless
 	Trojan
 	Hysteria
+	Quic
 )
 
 const (
@@ -118,6 +121,8 @@ type Group interface {
 type DelayHistory struct {
 	Time  time.Time `json:"time"`
 	Delay uint16    `json:"delay"`
+	// Loss is the percentage of failed probes of a burst health check
+	Loss uint8 `json:"loss,omitempty"`
 }
 
 type Proxy interface {
@@ -125,7 +130,12 @@ type Proxy interface {
 	Alive() bool
 	DelayHistory() []DelayHistory
 	LastDelay() uint16
-	URLTest(ctx context.Context, url string) (uint16, error)
+	URLTest(ctx context.Context, url string, expectedStatus utils.IntRanges[uint16]) (uint16, error)
+	// TTFBTest is URLTest with a GET request, the delay lasts until the first byte of the body
+	TTFBTest(ctx context.Context, url string, expectedStatus utils.IntRanges[uint16]) (uint16, error)
+	// BurstTest runs count probes at once and records their average delay and loss
+	BurstTest(ctx context.Context, url string, expectedStatus utils.IntRanges[uint16], count int, ttfb bool) (uint16, uint8, error)
+	LastLoss() uint8
 
 	// Deprecated: use DialContext instead.
 	Dial(metadata *Metadata) (Conn, error)
@@ -165,6 +175,8 @@ func (at AdapterType) String() string {
 		return "Trojan"
 	case Hysteria:
 		return "Hysteria"
+	case Quic:
+		return "Quic"
 
 	case Relay:
 		return "Relay"
@@ -199,3 +211,13 @@ type UDPPacket interface {
 	// LocalAddr returns the source IP/Port of packet
 	LocalAddr() net.Addr
 }
+
+// ErrPortUnreachable is returned by a PacketConn write when the sender should be told
+// that the destination is unreachable, see UDPPacketUnreachable
+var ErrPortUnreachable = errors.New("port unreachable")
+
+// UDPPacketUnreachable is implemented by UDP packets of inbounds that can
+// notify the sender with ICMP port unreachable, e.g. TUN
+type UDPPacketUnreachable interface {
+	Unreachable() error
+}
@@ -26,6 +26,7 @@ const (
 	TPROXY
 	TUN
 	INNER
+	TUNNEL
 )
 
 type NetWork int
@@ -63,6 +64,8 @@ func (t Type) String() string {
 		return "Tun"
 	case INNER:
 		return "Inner"
+	case TUNNEL:
+		return "Tunnel"
 	default:
 		return "Unknown"
 	}
@@ -87,6 +90,8 @@ func ParseType(t string) (*Type, error) {
 		res = TUN
 	case "INNER":
 		res = INNER
+	case "TUNNEL":
+		res = TUNNEL
 	default:
 		return nil, fmt.Errorf("unknown type: %s", t)
 	}
@@ -112,6 +117,27 @@ type Metadata struct {
 	Process     string     `json:"process"`
 	ProcessPath string     `json:"processPath"`
 	RemoteDst   string     `json:"remoteDestination"`
+	InUser      string     `json:"inboundUser"`
+	// SpecialProxy bypasses the rules, it is set by inbounds bound to a proxy
+	SpecialProxy string `json:"specialProxy"`
+	// UserAgent is the User-Agent of the client, only set by the HTTP inbound
+	UserAgent string `json:"userAgent,omitempty"`
+	// DSCP is the DSCP of the client packets, only set by the TProxy inbound on Linux
+	DSCP *uint8 `json:"dscp,omitempty"`
+	// HTTPMethod and HTTPPath are only set by the HTTP sniffer
+	HTTPMethod string `json:"-"`
+	HTTPPath   string `json:"-"`
+	// JA3 and JA4 are only set by the TLS sniffer, see /connections for the values of an app
+	JA3 string `json:"ja3"`
+	JA4 string `json:"ja4"`
+	// RequestHost is the host asked for before the sniffer replaced it with SniffHost,
+	// it is empty when the sniffer did not run or the request carried no host
+	RequestHost string `json:"requestHost,omitempty"`
+	SniffHost   string `json:"sniffHost,omitempty"`
+	// ResolvedIPs are all the addresses Host resolved to while matching the rules, DstIP is one of them
+	ResolvedIPs []netip.Addr `json:"resolvedIPs,omitempty"`
+	// RuleProvider is the rule-provider of the RULE-SET that matched, see /connections
+	RuleProvider string `json:"-"`
 }
 
 func (m *Metadata) RemoteAddress() string {
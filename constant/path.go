@@ -28,6 +28,7 @@ var Path = func() *path {
 type path struct {
 	homeDir    string
 	configFile string
+	overrides  []string
 }
 
 // SetHomeDir is used to set the configuration path
@@ -40,6 +41,11 @@ func SetConfig(file string) {
 	Path.configFile = file
 }
 
+// SetConfigOverrides is used to set the files merged over the configuration file in order
+func SetConfigOverrides(files []string) {
+	Path.overrides = files
+}
+
 func (p *path) HomeDir() string {
 	return p.homeDir
 }
@@ -48,6 +54,10 @@ func (p *path) Config() string {
 	return p.configFile
 }
 
+func (p *path) ConfigOverrides() []string {
+	return p.overrides
+}
+
 // Resolve return a absolute path or a relative path with homedir
 func (p *path) Resolve(path string) string {
 	if !filepath.IsAbs(path) {
@@ -9,6 +9,7 @@ const (
 	File VehicleType = iota
 	HTTP
 	Compatible
+	Command
 )
 
 // VehicleType defined
@@ -22,6 +23,8 @@ func (v VehicleType) String() string {
 		return "HTTP"
 	case Compatible:
 		return "Compatible"
+	case Command:
+		return "Command"
 	default:
 		return "Unknown"
 	}
@@ -94,6 +97,28 @@ func (rt RuleType) String() string {
 	}
 }
 
+const (
+	YamlRule RuleFormat = iota
+	TextRule
+	PlistRule
+)
+
+// RuleFormat is the file format of a rule provider
+type RuleFormat int
+
+func (rf RuleFormat) String() string {
+	switch rf {
+	case YamlRule:
+		return "YamlRule"
+	case TextRule:
+		return "TextRule"
+	case PlistRule:
+		return "PlistRule"
+	default:
+		return "Unknown"
+	}
+}
+
 // RuleProvider interface
 type RuleProvider interface {
 	Provider
@@ -19,6 +19,14 @@ const (
 	Network
 	Uid
 	INTYPE
+	INUSER
+	HTTPMethod
+	HTTPPath
+	JA3
+	JA4
+	UserAgent
+	DSCP
+	DstConnections
 	SubRules
 	MATCH
 	AND
@@ -66,6 +74,22 @@ func (rt RuleType) String() string {
 		return "Uid"
 	case INTYPE:
 		return "InType"
+	case INUSER:
+		return "InUser"
+	case HTTPMethod:
+		return "HTTPMethod"
+	case HTTPPath:
+		return "HTTPPath"
+	case JA3:
+		return "JA3"
+	case JA4:
+		return "JA4"
+	case UserAgent:
+		return "UserAgent"
+	case DSCP:
+		return "DSCP"
+	case DstConnections:
+		return "DstConnections"
 	case SubRules:
 		return "SubRules"
 	case AND:
@@ -87,3 +111,8 @@ type Rule interface {
 	ShouldResolveIP() bool
 	ShouldFindProcess() bool
 }
+
+// DSCPMarker is implemented by rules carrying the dscp param, the dialer marks the egress with the value
+type DSCPMarker interface {
+	EgressDSCP(metadata *Metadata) (uint8, bool)
+}
@@ -95,6 +95,18 @@ func (c *client) ExchangeContext(ctx context.Context, m *D.Msg) (*D.Msg, error)
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	case ret := <-ch:
+		if ret.err == nil && ret.msg.Truncated && network == "udp" {
+			// the answer doesn't fit into UDP, retry over TCP
+			tc := *c
+			tc.Client = &D.Client{
+				Net:          "tcp",
+				TLSConfig:    c.Client.TLSConfig,
+				Timeout:      c.Client.Timeout,
+				TsigSecret:   c.Client.TsigSecret,
+				TsigProvider: c.Client.TsigProvider,
+			}
+			return tc.ExchangeContext(ctx, m)
+		}
 		return ret.msg, ret.err
 	}
 }
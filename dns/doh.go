@@ -11,20 +11,32 @@ import (
 	"github.com/lucas-clemente/quic-go"
 	"github.com/lucas-clemente/quic-go/http3"
 	D "github.com/miekg/dns"
+	"go.uber.org/atomic"
 	"io"
 	"net"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 )
 
 const (
 	// dotMimeType is the DoH mimetype that should be used.
 	dotMimeType = "application/dns-message"
+
+	// altSvcDefaultMaxAge is the default freshness of an Alt-Svc entry, RFC 7838 section 3.1
+	altSvcDefaultMaxAge = 24 * time.Hour
 )
 
 type dohClient struct {
 	url       string
+	port      string
 	transport http.RoundTripper
+
+	// h3Transport is used once the server advertises h3 with Alt-Svc, only when prefer-h3 is on
+	h3Transport http.RoundTripper
+	h3Expire    *atomic.Int64
 }
 
 func (dc *dohClient) Exchange(m *D.Msg) (msg *D.Msg, err error) {
@@ -36,19 +48,34 @@ func (dc *dohClient) ExchangeContext(ctx context.Context, m *D.Msg) (msg *D.Msg,
 	// In order to maximize cache friendliness, SHOULD use a DNS ID of 0 in every DNS request.
 	newM := *m
 	newM.Id = 0
-	req, err := dc.newRequest(&newM)
-	if err != nil {
-		return nil, err
+
+	if dc.altSvcH3() {
+		msg, err = dc.exchange(ctx, dc.h3Transport, &newM)
+		if err == nil {
+			msg.Id = m.Id
+			return
+		}
+
+		// h3 is broken on this path, forget it and use the original transport
+		dc.h3Expire.Store(0)
 	}
 
-	req = req.WithContext(ctx)
-	msg, err = dc.doRequest(req)
+	msg, err = dc.exchange(ctx, dc.transport, &newM)
 	if err == nil {
 		msg.Id = m.Id
 	}
 	return
 }
 
+func (dc *dohClient) exchange(ctx context.Context, transport http.RoundTripper, m *D.Msg) (*D.Msg, error) {
+	req, err := dc.newRequest(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return dc.doRequest(transport, req.WithContext(ctx))
+}
+
 // newRequest returns a new DoH request given a dns.Msg.
 func (dc *dohClient) newRequest(m *D.Msg) (*http.Request, error) {
 	buf, err := m.Pack()
@@ -66,8 +93,8 @@ func (dc *dohClient) newRequest(m *D.Msg) (*http.Request, error) {
 	return req, nil
 }
 
-func (dc *dohClient) doRequest(req *http.Request) (msg *D.Msg, err error) {
-	client := &http.Client{Transport: dc.transport}
+func (dc *dohClient) doRequest(transport http.RoundTripper, req *http.Request) (msg *D.Msg, err error) {
+	client := &http.Client{Transport: transport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -75,6 +102,12 @@ func (dc *dohClient) doRequest(req *http.Request) (msg *D.Msg, err error) {
 
 	defer resp.Body.Close()
 
+	if dc.h3Transport != nil && transport != dc.h3Transport {
+		if maxAge, ok := parseAltSvcH3(resp.Header.Get("Alt-Svc"), dc.port); ok {
+			dc.h3Expire.Store(time.Now().Add(maxAge).UnixNano())
+		}
+	}
+
 	buf, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
@@ -84,81 +117,134 @@ func (dc *dohClient) doRequest(req *http.Request) (msg *D.Msg, err error) {
 	return msg, err
 }
 
-func newDoHClient(url string, r *Resolver, params map[string]string, proxyAdapter string) *dohClient {
-	useH3 := params["h3"] == "true"
-	TLCConfig := tlsC.GetDefaultTLSConfig()
-	var transport http.RoundTripper
-	if useH3 {
-		transport = &http3.RoundTripper{
-			Dial: func(ctx context.Context, addr string, tlsCfg *tls.Config, cfg *quic.Config) (quic.EarlyConnection, error) {
-				host, port, err := net.SplitHostPort(addr)
-				if err != nil {
-					return nil, err
-				}
+func (dc *dohClient) altSvcH3() bool {
+	return dc.h3Transport != nil && time.Now().UnixNano() < dc.h3Expire.Load()
+}
 
-				ip, err := resolver.ResolveIPWithResolver(host, r)
-				if err != nil {
-					return nil, err
-				}
+// parseAltSvcH3 looks for an h3 alternative on the same authority and returns its max age
+func parseAltSvcH3(header string, port string) (time.Duration, bool) {
+	for _, entry := range strings.Split(header, ",") {
+		params := strings.Split(strings.TrimSpace(entry), ";")
+		protocol, authority, found := strings.Cut(params[0], "=")
+		if !found || strings.TrimSpace(protocol) != "h3" {
+			continue
+		}
 
-				portInt, err := strconv.Atoi(port)
-				if err != nil {
-					return nil, err
-				}
+		authority = strings.Trim(strings.TrimSpace(authority), `"`)
+		host, altPort, err := net.SplitHostPort(authority)
+		if err != nil || host != "" || altPort != port {
+			continue
+		}
 
-				udpAddr := net.UDPAddr{
-					IP:   net.ParseIP(ip.String()),
-					Port: portInt,
+		maxAge := altSvcDefaultMaxAge
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if key == "ma" {
+				if seconds, err := strconv.ParseUint(value, 10, 32); err == nil {
+					maxAge = time.Duration(seconds) * time.Second
 				}
+			}
+		}
+		return maxAge, maxAge > 0
+	}
 
-				var conn net.PacketConn
-				if proxyAdapter == "" {
-					conn, err = dialer.ListenPacket(ctx, "udp", "")
-					if err != nil {
-						return nil, err
-					}
-				} else {
-					if wrapConn, err := dialContextExtra(ctx, proxyAdapter, "udp", ip, port); err == nil {
-						if pc, ok := wrapConn.(*wrapPacketConn); ok {
-							conn = pc
-						} else {
-							return nil, fmt.Errorf("conn isn't wrapPacketConn")
-						}
-					} else {
-						return nil, err
-					}
-				}
+	return 0, false
+}
 
-				return quic.DialEarlyContext(ctx, conn, &udpAddr, host, tlsCfg, cfg)
-			},
-			TLSClientConfig: TLCConfig,
-		}
-	} else {
-		transport = &http.Transport{
-			ForceAttemptHTTP2: true,
-			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
-				host, port, err := net.SplitHostPort(addr)
+func newH3Transport(r *Resolver, proxyAdapter string, tlsConfig *tls.Config) http.RoundTripper {
+	return &http3.RoundTripper{
+		Dial: func(ctx context.Context, addr string, tlsCfg *tls.Config, cfg *quic.Config) (quic.EarlyConnection, error) {
+			host, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+
+			ip, err := resolver.ResolveIPWithResolver(host, r)
+			if err != nil {
+				return nil, err
+			}
+
+			portInt, err := strconv.Atoi(port)
+			if err != nil {
+				return nil, err
+			}
+
+			udpAddr := net.UDPAddr{
+				IP:   net.ParseIP(ip.String()),
+				Port: portInt,
+			}
+
+			var conn net.PacketConn
+			if proxyAdapter == "" {
+				conn, err = dialer.ListenPacket(ctx, "udp", "")
 				if err != nil {
 					return nil, err
 				}
-
-				ip, err := resolver.ResolveIPWithResolver(host, r)
-				if err != nil {
+			} else {
+				if wrapConn, err := dialContextExtra(ctx, proxyAdapter, "udp", ip, port); err == nil {
+					if pc, ok := wrapConn.(*wrapPacketConn); ok {
+						conn = pc
+					} else {
+						return nil, fmt.Errorf("conn isn't wrapPacketConn")
+					}
+				} else {
 					return nil, err
 				}
+			}
 
-				if proxyAdapter == "" {
-					return dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip.String(), port))
-				} else {
-					return dialContextExtra(ctx, proxyAdapter, "tcp", ip, port)
-				}
-			},
-			TLSClientConfig: TLCConfig,
-		}
+			return quic.DialEarlyContext(ctx, conn, &udpAddr, host, tlsCfg, cfg)
+		},
+		TLSClientConfig: tlsConfig,
+	}
+}
+
+func newH2Transport(r *Resolver, proxyAdapter string, tlsConfig *tls.Config) http.RoundTripper {
+	return &http.Transport{
+		ForceAttemptHTTP2: true,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+
+			ip, err := resolver.ResolveIPWithResolver(host, r)
+			if err != nil {
+				return nil, err
+			}
+
+			if proxyAdapter == "" {
+				return dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip.String(), port))
+			} else {
+				return dialContextExtra(ctx, proxyAdapter, "tcp", ip, port)
+			}
+		},
+		TLSClientConfig: tlsConfig,
+	}
+}
+
+func newDoHClient(urlString string, r *Resolver, preferH3 bool, params map[string]string, proxyAdapter string) *dohClient {
+	useH3 := params["h3"] == "true"
+	TLCConfig := tlsC.GetDefaultTLSConfig()
+
+	port := "443"
+	if u, err := url.Parse(urlString); err == nil && u.Port() != "" {
+		port = u.Port()
+	}
+
+	client := &dohClient{
+		url:      urlString,
+		port:     port,
+		h3Expire: atomic.NewInt64(0),
 	}
 
-	return &dohClient{
-		url:       url,
-		transport: transport,
+	if useH3 {
+		client.transport = newH3Transport(r, proxyAdapter, TLCConfig)
+	} else {
+		client.transport = newH2Transport(r, proxyAdapter, TLCConfig)
+		if preferH3 {
+			client.h3Transport = newH3Transport(r, proxyAdapter, TLCConfig)
+		}
 	}
+
+	return client
 }
@@ -0,0 +1,39 @@
+package dns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAltSvcH3(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		maxAge time.Duration
+		ok     bool
+	}{
+		{name: "empty", header: "", ok: false},
+		{name: "same port", header: `h3=":443"`, maxAge: altSvcDefaultMaxAge, ok: true},
+		{name: "max age", header: `h3=":443"; ma=3600`, maxAge: time.Hour, ok: true},
+		{name: "invalid max age", header: `h3=":443"; ma=abc`, maxAge: altSvcDefaultMaxAge, ok: true},
+		{name: "zero max age", header: `h3=":443"; ma=0`, ok: false},
+		{name: "other port", header: `h3=":8443"`, ok: false},
+		{name: "other host", header: `h3="alt.example.com:443"`, ok: false},
+		{name: "draft only", header: `h3-29=":443"`, ok: false},
+		{name: "clear", header: `clear`, ok: false},
+		{name: "second entry", header: `h2=":443", h3=":443"; ma=60; persist=1`, maxAge: time.Minute, ok: true},
+		{name: "unquoted", header: `h3=:443`, maxAge: altSvcDefaultMaxAge, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxAge, ok := parseAltSvcH3(tt.header, "443")
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.maxAge, maxAge)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package dns
+
+import (
+	"context"
+	"net/netip"
+
+	D "github.com/miekg/dns"
+)
+
+// ecsClient adds EDNS Client Subnet (RFC 7871) to the queries of a nameserver
+type ecsClient struct {
+	dnsClient
+	subnet   netip.Prefix
+	override bool
+}
+
+func newECSClient(c dnsClient, subnet netip.Prefix, override bool) *ecsClient {
+	return &ecsClient{
+		dnsClient: c,
+		subnet:    subnet.Masked(),
+		override:  override,
+	}
+}
+
+func (c *ecsClient) Exchange(m *D.Msg) (msg *D.Msg, err error) {
+	return c.ExchangeContext(context.Background(), m)
+}
+
+func (c *ecsClient) ExchangeContext(ctx context.Context, m *D.Msg) (msg *D.Msg, err error) {
+	return c.dnsClient.ExchangeContext(ctx, setClientSubnet(m, c.subnet, c.override))
+}
+
+// setClientSubnet returns a copy of m carrying subnet, the query is kept as is
+// if it already has a client subnet and override is false
+func setClientSubnet(m *D.Msg, subnet netip.Prefix, override bool) *D.Msg {
+	opt := m.IsEdns0()
+	if opt != nil {
+		for _, o := range opt.Option {
+			if o.Option() == D.EDNS0SUBNET && !override {
+				return m
+			}
+		}
+	}
+
+	m = m.Copy()
+	opt = m.IsEdns0()
+	if opt == nil {
+		m.SetEdns0(D.DefaultMsgSize, false)
+		opt = m.IsEdns0()
+	}
+
+	options := opt.Option[:0]
+	for _, o := range opt.Option {
+		if o.Option() != D.EDNS0SUBNET {
+			options = append(options, o)
+		}
+	}
+
+	ecs := &D.EDNS0_SUBNET{
+		Code:          D.EDNS0SUBNET,
+		SourceNetmask: uint8(subnet.Bits()),
+		Address:       subnet.Addr().AsSlice(),
+	}
+	if subnet.Addr().Is4() {
+		ecs.Family = 1
+	} else {
+		ecs.Family = 2
+	}
+	opt.Option = append(options, ecs)
+
+	return m
+}
@@ -0,0 +1,82 @@
+package dns
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	D "github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func clientSubnetOf(m *D.Msg) *D.EDNS0_SUBNET {
+	opt := m.IsEdns0()
+	if opt == nil {
+		return nil
+	}
+	for _, o := range opt.Option {
+		if ecs, ok := o.(*D.EDNS0_SUBNET); ok {
+			return ecs
+		}
+	}
+	return nil
+}
+
+func TestSetClientSubnet(t *testing.T) {
+	query := func(subnet string) *D.Msg {
+		m := new(D.Msg)
+		m.SetQuestion("example.com.", D.TypeA)
+		if subnet != "" {
+			prefix := netip.MustParsePrefix(subnet)
+			m.SetEdns0(D.DefaultMsgSize, false)
+			opt := m.IsEdns0()
+			opt.Option = append(opt.Option, &D.EDNS0_SUBNET{
+				Code:          D.EDNS0SUBNET,
+				Family:        1,
+				SourceNetmask: uint8(prefix.Bits()),
+				Address:       prefix.Addr().AsSlice(),
+			})
+		}
+		return m
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		subnet   string
+		override bool
+		family   uint16
+		mask     uint8
+		address  net.IP
+	}{
+		{name: "ipv4", subnet: "1.2.3.0/24", family: 1, mask: 24, address: net.IP{1, 2, 3, 0}},
+		{name: "ipv6", subnet: "2001:db8::/56", family: 2, mask: 56, address: net.ParseIP("2001:db8::")},
+		{name: "keeps the query subnet", query: "9.9.9.0/24", subnet: "1.2.3.0/24", family: 1, mask: 24, address: net.IP{9, 9, 9, 0}},
+		{name: "overrides the query subnet", query: "9.9.9.0/24", subnet: "1.2.3.0/24", override: true, family: 1, mask: 24, address: net.IP{1, 2, 3, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := query(tt.query)
+			before := m.String()
+
+			got := setClientSubnet(m, netip.MustParsePrefix(tt.subnet), tt.override)
+			assert.Equal(t, before, m.String(), "the original query is changed")
+
+			ecs := clientSubnetOf(got)
+			if assert.NotNil(t, ecs) {
+				assert.Equal(t, tt.family, ecs.Family)
+				assert.Equal(t, tt.mask, ecs.SourceNetmask)
+				assert.True(t, tt.address.Equal(ecs.Address), "address %s", ecs.Address)
+			}
+
+			subnets := 0
+			for _, o := range got.IsEdns0().Option {
+				if o.Option() == D.EDNS0SUBNET {
+					subnets++
+				}
+			}
+			assert.Equal(t, 1, subnets)
+		})
+	}
+}
@@ -40,6 +40,9 @@ func (h *ResolverEnhancer) IsFakeIP(ip netip.Addr) bool {
 	}
 
 	if pool := h.fakePool; pool != nil {
+		if v4, ok := pool.FromIPv6(ip); ok {
+			ip = v4
+		}
 		return pool.IPNet().Contains(ip) && ip != pool.Gateway() && ip != pool.Broadcast()
 	}
 
@@ -52,6 +55,9 @@ func (h *ResolverEnhancer) IsFakeBroadcastIP(ip netip.Addr) bool {
 	}
 
 	if pool := h.fakePool; pool != nil {
+		if v4, ok := pool.FromIPv6(ip); ok {
+			ip = v4
+		}
 		return pool.Broadcast() == ip
 	}
 
@@ -70,6 +70,88 @@ func withHosts(hosts *trie.DomainTrie[netip.Addr], mapping *cache.LruCache[netip
 	}
 }
 
+// maxAliasHops bounds following a chain of host aliases, an alias loop stops at the repeated host
+const maxAliasHops = 8
+
+// aliasChain follows the aliases of name, it returns name and the hosts it aliases in order
+func aliasChain(aliases *trie.DomainTrie[string], name string) []string {
+	chain := []string{name}
+	for len(chain) <= maxAliasHops {
+		record := aliases.Search(strings.TrimRight(chain[len(chain)-1], "."))
+		if record == nil {
+			break
+		}
+
+		target := D.Fqdn(record.Data)
+		for _, host := range chain {
+			if strings.EqualFold(host, target) {
+				return chain
+			}
+		}
+		chain = append(chain, target)
+	}
+	return chain
+}
+
+// aliasTTL is the ttl of the CNAMEs put in front of msg, the shortest of its answers or of its authority
+func aliasTTL(msg *D.Msg) uint32 {
+	rrs := msg.Answer
+	if len(rrs) == 0 {
+		rrs = msg.Ns
+	}
+	if len(rrs) == 0 {
+		return 10
+	}
+
+	ttl := rrs[0].Header().Ttl
+	for _, rr := range rrs[1:] {
+		if rr.Header().Ttl < ttl {
+			ttl = rr.Header().Ttl
+		}
+	}
+	return ttl
+}
+
+func withHostAliases(aliases *trie.DomainTrie[string]) middleware {
+	return func(next handler) handler {
+		return func(ctx *context.DNSContext, r *D.Msg) (*D.Msg, error) {
+			q := r.Question[0]
+
+			if !isIPRequest(q) {
+				return next(ctx, r)
+			}
+
+			chain := aliasChain(aliases, q.Name)
+			if len(chain) == 1 {
+				return next(ctx, r)
+			}
+
+			// resolve the last target through the rest of the chain, then answer with the CNAMEs in front
+			m := r.Copy()
+			m.Question[0].Name = chain[len(chain)-1]
+			msg, err := next(ctx, m)
+			if err != nil {
+				return nil, err
+			}
+
+			ttl := aliasTTL(msg)
+			cnames := make([]D.RR, 0, len(chain)-1+len(msg.Answer))
+			for i := 0; i < len(chain)-1; i++ {
+				rr := &D.CNAME{}
+				rr.Hdr = D.RR_Header{Name: chain[i], Rrtype: D.TypeCNAME, Class: D.ClassINET, Ttl: ttl}
+				rr.Target = chain[i+1]
+				cnames = append(cnames, rr)
+			}
+
+			msg = msg.Copy()
+			msg.Id = r.Id
+			msg.Question = r.Question
+			msg.Answer = append(cnames, msg.Answer...)
+			return msg, nil
+		}
+	}
+}
+
 func withMapping(mapping *cache.LruCache[netip.Addr, string]) middleware {
 	return func(next handler) handler {
 		return func(ctx *context.DNSContext, r *D.Msg) (*D.Msg, error) {
@@ -119,19 +201,28 @@ func withFakeIP(fakePool *fakeip.Pool) middleware {
 				return next(ctx, r)
 			}
 
+			var rr D.RR
 			switch q.Qtype {
-			case D.TypeAAAA, D.TypeSVCB, D.TypeHTTPS:
+			case D.TypeA:
+				rr = &D.A{
+					Hdr: D.RR_Header{Name: q.Name, Rrtype: D.TypeA, Class: D.ClassINET, Ttl: dnsDefaultTTL},
+					A:   fakePool.Lookup(host).AsSlice(),
+				}
+			case D.TypeAAAA:
+				// without fake-ip-range6 the empty answer lets clients fall back to A
+				if fakePool.IPNet6() == nil {
+					return handleMsgWithEmptyAnswer(r), nil
+				}
+				rr = &D.AAAA{
+					Hdr:  D.RR_Header{Name: q.Name, Rrtype: D.TypeAAAA, Class: D.ClassINET, Ttl: dnsDefaultTTL},
+					AAAA: fakePool.Lookup6(host).AsSlice(),
+				}
+			case D.TypeSVCB, D.TypeHTTPS:
 				return handleMsgWithEmptyAnswer(r), nil
-			}
-
-			if q.Qtype != D.TypeA {
+			default:
 				return next(ctx, r)
 			}
 
-			rr := &D.A{}
-			rr.Hdr = D.RR_Header{Name: q.Name, Rrtype: D.TypeA, Class: D.ClassINET, Ttl: dnsDefaultTTL}
-			ip := fakePool.Lookup(host)
-			rr.A = ip.AsSlice()
 			msg := r.Copy()
 			msg.Answer = []D.RR{rr}
 
@@ -183,6 +274,10 @@ func compose(middlewares []middleware, endpoint handler) handler {
 func NewHandler(resolver *Resolver, mapper *ResolverEnhancer) handler {
 	middlewares := []middleware{}
 
+	if resolver.hostAliases != nil {
+		middlewares = append(middlewares, withHostAliases(resolver.hostAliases))
+	}
+
 	if resolver.hosts != nil {
 		middlewares = append(middlewares, withHosts(resolver.hosts, mapper.mapping))
 	}
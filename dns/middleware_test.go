@@ -0,0 +1,123 @@
+package dns
+
+import (
+	"net"
+	"testing"
+
+	"github.com/Dreamacro/clash/component/trie"
+	"github.com/Dreamacro/clash/context"
+
+	D "github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAliases(t *testing.T, aliases map[string]string) *trie.DomainTrie[string] {
+	tree := trie.New[string]()
+	for host, target := range aliases {
+		if err := tree.Insert(host, target); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tree
+}
+
+func TestAliasChain(t *testing.T) {
+	aliases := newTestAliases(t, map[string]string{
+		"a.com":     "b.com",
+		"b.com":     "c.com",
+		"loop1.com": "loop2.com",
+		"loop2.com": "loop1.com",
+		"self.com":  "self.com",
+	})
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "none.com.", want: []string{"none.com."}},
+		{name: "b.com.", want: []string{"b.com.", "c.com."}},
+		{name: "a.com.", want: []string{"a.com.", "b.com.", "c.com."}},
+		{name: "loop1.com.", want: []string{"loop1.com.", "loop2.com."}},
+		{name: "self.com.", want: []string{"self.com."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, aliasChain(aliases, tt.name))
+		})
+	}
+}
+
+func TestAliasChain_MaxHops(t *testing.T) {
+	hosts := map[string]string{}
+	for i := 0; i < maxAliasHops+4; i++ {
+		hosts[string(rune('a'+i))+".com"] = string(rune('a'+i+1)) + ".com"
+	}
+	chain := aliasChain(newTestAliases(t, hosts), "a.com.")
+	assert.Len(t, chain, maxAliasHops+1)
+}
+
+func TestWithHostAliases(t *testing.T) {
+	aliases := newTestAliases(t, map[string]string{
+		"a.com": "b.com",
+		"b.com": "c.com",
+	})
+
+	var asked string
+	resolve := func(ctx *context.DNSContext, r *D.Msg) (*D.Msg, error) {
+		asked = r.Question[0].Name
+		msg := new(D.Msg)
+		msg.SetReply(r)
+		msg.Answer = []D.RR{
+			&D.A{Hdr: D.RR_Header{Name: asked, Rrtype: D.TypeA, Class: D.ClassINET, Ttl: 300}, A: net.IPv4(1, 1, 1, 1)},
+			&D.A{Hdr: D.RR_Header{Name: asked, Rrtype: D.TypeA, Class: D.ClassINET, Ttl: 120}, A: net.IPv4(1, 0, 0, 1)},
+		}
+		return msg, nil
+	}
+	h := withHostAliases(aliases)(resolve)
+
+	r := new(D.Msg)
+	r.SetQuestion("a.com.", D.TypeA)
+	msg, err := h(context.NewDNSContext(r), r)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "c.com.", asked)
+	assert.Equal(t, r.Id, msg.Id)
+	assert.Equal(t, r.Question, msg.Question)
+	if assert.Len(t, msg.Answer, 4) {
+		first, ok := msg.Answer[0].(*D.CNAME)
+		if assert.True(t, ok) {
+			assert.Equal(t, "a.com.", first.Hdr.Name)
+			assert.Equal(t, "b.com.", first.Target)
+			assert.Equal(t, uint32(120), first.Hdr.Ttl)
+		}
+		second, ok := msg.Answer[1].(*D.CNAME)
+		if assert.True(t, ok) {
+			assert.Equal(t, "b.com.", second.Hdr.Name)
+			assert.Equal(t, "c.com.", second.Target)
+			assert.Equal(t, uint32(120), second.Hdr.Ttl)
+		}
+		_, ok = msg.Answer[2].(*D.A)
+		assert.True(t, ok)
+	}
+
+	// a host without alias goes through untouched
+	r = new(D.Msg)
+	r.SetQuestion("other.com.", D.TypeA)
+	msg, err = h(context.NewDNSContext(r), r)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "other.com.", asked)
+		assert.Len(t, msg.Answer, 2)
+	}
+}
+
+func TestAliasTTL(t *testing.T) {
+	soa := &D.SOA{Hdr: D.RR_Header{Name: "com.", Rrtype: D.TypeSOA, Class: D.ClassINET, Ttl: 900}}
+	a := &D.A{Hdr: D.RR_Header{Name: "a.com.", Rrtype: D.TypeA, Class: D.ClassINET, Ttl: 60}}
+
+	assert.Equal(t, uint32(60), aliasTTL(&D.Msg{Answer: []D.RR{a}, Ns: []D.RR{soa}}))
+	assert.Equal(t, uint32(900), aliasTTL(&D.Msg{Ns: []D.RR{soa}}))
+	assert.Equal(t, uint32(10), aliasTTL(&D.Msg{}))
+}
@@ -1,5 +1,10 @@
 package dns
 
+import (
+	"sort"
+	"strings"
+)
+
 type Policy struct {
 	data []dnsClient
 }
@@ -28,3 +33,23 @@ func NewPolicy(data []dnsClient) *Policy {
 		data: data,
 	}
 }
+
+// sortPolicyDomains returns the policy domains in insert order. `+.` wildcards
+// also cover their root domain, so they are inserted first and an explicit
+// entry for the same name overrides them; the trie search then always picks
+// the most specific match regardless of map iteration order.
+func sortPolicyDomains(policy map[string]NameServer) []string {
+	domains := make([]string, 0, len(policy))
+	for domain := range policy {
+		domains = append(domains, domain)
+	}
+
+	sort.Slice(domains, func(i, j int) bool {
+		wi, wj := strings.HasPrefix(domains[i], "+."), strings.HasPrefix(domains[j], "+.")
+		if wi != wj {
+			return wi
+		}
+		return domains[i] < domains[j]
+	})
+	return domains
+}
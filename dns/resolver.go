@@ -34,6 +34,7 @@ type result struct {
 type Resolver struct {
 	ipv6                  bool
 	hosts                 *trie.DomainTrie[netip.Addr]
+	hostAliases           *trie.DomainTrie[string]
 	main                  []dnsClient
 	fallback              []dnsClient
 	fallbackDomainFilters []fallbackDomainFilter
@@ -151,7 +152,7 @@ func (r *Resolver) ExchangeContext(ctx context.Context, m *D.Msg) (msg *D.Msg, e
 	}
 
 	q := m.Question[0]
-	cacheM, expireTime, hit := r.lruCache.GetWithExpire(q.String())
+	cacheM, expireTime, hit := r.lruCache.GetWithExpire(questionKey(q))
 	if hit {
 		now := time.Now()
 		msg = cacheM.Copy()
@@ -169,8 +170,10 @@ func (r *Resolver) ExchangeContext(ctx context.Context, m *D.Msg) (msg *D.Msg, e
 // ExchangeWithoutCache a batch of dns request, and it do NOT GET from cache
 func (r *Resolver) exchangeWithoutCache(ctx context.Context, m *D.Msg) (msg *D.Msg, err error) {
 	q := m.Question[0]
+	key := questionKey(q)
 
-	ret, err, shared := r.group.Do(q.String(), func() (result any, err error) {
+	// identical queries in flight share one upstream exchange
+	ret, err, shared := r.group.Do(key, func() (result any, err error) {
 		defer func() {
 			if err != nil {
 				return
@@ -178,7 +181,7 @@ func (r *Resolver) exchangeWithoutCache(ctx context.Context, m *D.Msg) (msg *D.M
 
 			msg := result.(*D.Msg)
 
-			putMsgToCache(r.lruCache, q.String(), msg)
+			putMsgToCache(r.lruCache, key, msg)
 		}()
 
 		isIPReq := isIPRequest(q)
@@ -355,6 +358,11 @@ type NameServer struct {
 	Interface    *atomic.String
 	ProxyAdapter string
 	Params       map[string]string
+	PreferH3     bool
+
+	// ClientSubnet is sent as EDNS Client Subnet, ClientSubnetOverride replaces the one from the query
+	ClientSubnet         netip.Prefix
+	ClientSubnetOverride bool
 }
 
 type FallbackFilter struct {
@@ -374,6 +382,7 @@ type Config struct {
 	FallbackFilter FallbackFilter
 	Pool           *fakeip.Pool
 	Hosts          *trie.DomainTrie[netip.Addr]
+	HostAliases    *trie.DomainTrie[string]
 	Policy         map[string]NameServer
 }
 
@@ -384,10 +393,11 @@ func NewResolver(config Config) *Resolver {
 	}
 
 	r := &Resolver{
-		ipv6:     config.IPv6,
-		main:     transform(config.Main, defaultResolver),
-		lruCache: cache.NewLRUCache[string, *D.Msg](cache.WithSize[string, *D.Msg](4096), cache.WithStale[string, *D.Msg](true)),
-		hosts:    config.Hosts,
+		ipv6:        config.IPv6,
+		main:        transform(config.Main, defaultResolver),
+		lruCache:    cache.NewLRUCache[string, *D.Msg](cache.WithSize[string, *D.Msg](4096), cache.WithStale[string, *D.Msg](true)),
+		hosts:       config.Hosts,
+		hostAliases: config.HostAliases,
 	}
 
 	if len(config.Fallback) != 0 {
@@ -400,8 +410,8 @@ func NewResolver(config Config) *Resolver {
 
 	if len(config.Policy) != 0 {
 		r.policy = trie.New[*Policy]()
-		for domain, nameserver := range config.Policy {
-			_ = r.policy.Insert(domain, NewPolicy(transform([]NameServer{nameserver}, defaultResolver)))
+		for _, domain := range sortPolicyDomains(config.Policy) {
+			_ = r.policy.Insert(domain, NewPolicy(transform([]NameServer{config.Policy[domain]}, defaultResolver)))
 		}
 	}
 
@@ -433,11 +443,12 @@ func NewResolver(config Config) *Resolver {
 
 func NewProxyServerHostResolver(old *Resolver) *Resolver {
 	r := &Resolver{
-		ipv6:     old.ipv6,
-		main:     old.proxyServer,
-		lruCache: old.lruCache,
-		hosts:    old.hosts,
-		policy:   old.policy,
+		ipv6:        old.ipv6,
+		main:        old.proxyServer,
+		lruCache:    old.lruCache,
+		hosts:       old.hosts,
+		hostAliases: old.hostAliases,
+		policy:      old.policy,
 	}
 	return r
 }
@@ -30,10 +30,21 @@ func (s *Server) ServeDNS(w D.ResponseWriter, r *D.Msg) {
 		D.HandleFailed(w, r)
 		return
 	}
+	if _, ok := w.RemoteAddr().(*net.UDPAddr); ok {
+		msg.Truncate(UDPSize(r))
+	}
 	msg.Compress = true
 	w.WriteMsg(msg)
 }
 
+// UDPSize returns the response size the client accepts over UDP
+func UDPSize(msg *D.Msg) int {
+	if opt := msg.IsEdns0(); opt != nil && opt.UDPSize() > D.MinMsgSize {
+		return int(opt.UDPSize())
+	}
+	return D.MinMsgSize
+}
+
 func handlerWithContext(handler handler, msg *D.Msg) (*D.Msg, error) {
 	if len(msg.Question) == 0 {
 		return nil, errors.New("at least one question is required")
@@ -19,6 +19,13 @@ import (
 	D "github.com/miekg/dns"
 )
 
+// questionKey identifies q in the cache and among the in-flight queries, names are case-insensitive
+// so a client randomizing the case (dns-0x20) still shares the answer
+func questionKey(q D.Question) string {
+	q.Name = strings.ToLower(q.Name)
+	return q.String()
+}
+
 func putMsgToCache(c *cache.LruCache[string, *D.Msg], key string, msg *D.Msg) {
 	var ttl uint32
 	switch {
@@ -57,36 +64,41 @@ func isIPRequest(q D.Question) bool {
 func transform(servers []NameServer, resolver *Resolver) []dnsClient {
 	ret := []dnsClient{}
 	for _, s := range servers {
-		switch s.Net {
-		case "https":
-			ret = append(ret, newDoHClient(s.Addr, resolver, s.Params, s.ProxyAdapter))
-			continue
-		case "dhcp":
-			ret = append(ret, newDHCPClient(s.Addr))
-			continue
-		case "quic":
-			ret = append(ret, newDOQ(resolver, s.Addr, s.ProxyAdapter))
-			continue
+		c := newClient(s, resolver)
+		if s.ClientSubnet.IsValid() {
+			c = newECSClient(c, s.ClientSubnet, s.ClientSubnetOverride)
 		}
+		ret = append(ret, c)
+	}
+	return ret
+}
+
+func newClient(s NameServer, resolver *Resolver) dnsClient {
+	switch s.Net {
+	case "https":
+		return newDoHClient(s.Addr, resolver, s.PreferH3, s.Params, s.ProxyAdapter)
+	case "dhcp":
+		return newDHCPClient(s.Addr)
+	case "quic":
+		return newDOQ(resolver, s.Addr, s.ProxyAdapter)
+	}
 
-		host, port, _ := net.SplitHostPort(s.Addr)
-		ret = append(ret, &client{
-			Client: &D.Client{
-				Net: s.Net,
-				TLSConfig: &tls.Config{
-					ServerName: host,
-				},
-				UDPSize: 4096,
-				Timeout: 5 * time.Second,
+	host, port, _ := net.SplitHostPort(s.Addr)
+	return &client{
+		Client: &D.Client{
+			Net: s.Net,
+			TLSConfig: &tls.Config{
+				ServerName: host,
 			},
-			port:         port,
-			host:         host,
-			iface:        s.Interface,
-			r:            resolver,
-			proxyAdapter: s.ProxyAdapter,
-		})
+			UDPSize: 4096,
+			Timeout: 5 * time.Second,
+		},
+		port:         port,
+		host:         host,
+		iface:        s.Interface,
+		r:            resolver,
+		proxyAdapter: s.ProxyAdapter,
 	}
-	return ret
 }
 
 func handleMsgWithEmptyAnswer(r *D.Msg) *D.Msg {
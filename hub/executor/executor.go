@@ -1,6 +1,7 @@
 package executor
 
 import (
+	"errors"
 	"fmt"
 	"github.com/Dreamacro/clash/component/tls"
 	"github.com/Dreamacro/clash/listener/inner"
@@ -8,16 +9,21 @@ import (
 	"os"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/Dreamacro/clash/adapter"
 	"github.com/Dreamacro/clash/adapter/outboundgroup"
+	N "github.com/Dreamacro/clash/common/net"
+	"github.com/Dreamacro/clash/common/utils"
 	"github.com/Dreamacro/clash/component/auth"
 	"github.com/Dreamacro/clash/component/dialer"
 	G "github.com/Dreamacro/clash/component/geodata"
 	"github.com/Dreamacro/clash/component/iface"
+	"github.com/Dreamacro/clash/component/loopback"
 	"github.com/Dreamacro/clash/component/profile"
 	"github.com/Dreamacro/clash/component/profile/cachefile"
 	"github.com/Dreamacro/clash/component/resolver"
+	"github.com/Dreamacro/clash/component/resource"
 	SNI "github.com/Dreamacro/clash/component/sniffer"
 	"github.com/Dreamacro/clash/component/trie"
 	"github.com/Dreamacro/clash/config"
@@ -29,9 +35,17 @@ import (
 	"github.com/Dreamacro/clash/listener/tproxy"
 	"github.com/Dreamacro/clash/log"
 	"github.com/Dreamacro/clash/tunnel"
+
+	"go.uber.org/atomic"
 )
 
-var mux sync.Mutex
+var (
+	mux sync.Mutex
+
+	// configPath is empty when the running config came as a payload through the API
+	configPath = atomic.NewString("")
+	lastReload = atomic.NewTime(time.Time{})
+)
 
 func readConfig(path string) ([]byte, error) {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
@@ -54,19 +68,46 @@ func Parse() (*config.Config, error) {
 	return ParseWithPath(C.Path.Config())
 }
 
-// ParseWithPath parse config with custom config path
+// ParseWithPath parse config with custom config path, the overrides of the -f flag only apply to the default config path
 func ParseWithPath(path string) (*config.Config, error) {
 	buf, err := readConfig(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return ParseWithBytes(buf)
+	var overrides [][]byte
+	if path == C.Path.Config() {
+		for _, override := range C.Path.ConfigOverrides() {
+			data, err := readConfig(override)
+			if err != nil {
+				return nil, err
+			}
+			overrides = append(overrides, data)
+		}
+	}
+
+	cfg, err := config.Parse(buf, overrides...)
+	if err != nil {
+		return nil, err
+	}
+	// the API can point at any file, a provider download included
+	if cfg.General.AllowCommand && path != C.Path.Config() {
+		return nil, fmt.Errorf("allow-command-provider can only be set in the config file clash started with, not %s", path)
+	}
+	cfg.General.ConfigPath = path
+	return cfg, nil
 }
 
-// ParseWithBytes config with buffer
+// ParseWithBytes config with buffer, the buffer comes from the API so it can't enable the command vehicle
 func ParseWithBytes(buf []byte) (*config.Config, error) {
-	return config.Parse(buf)
+	cfg, err := config.Parse(buf)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.General.AllowCommand {
+		return nil, errors.New("allow-command-provider can't be set in a config payload")
+	}
+	return cfg, nil
 }
 
 // ApplyConfig dispatch configure to all parts
@@ -75,10 +116,11 @@ func ApplyConfig(cfg *config.Config, force bool) {
 	defer mux.Unlock()
 	preUpdateExperimental(cfg)
 	updateUsers(cfg.Users)
-	updateProxies(cfg.Proxies, cfg.Providers)
+	resource.SetAllowCommand(cfg.General.AllowCommand)
+	updateProxies(cfg.Proxies, cfg.Chains, cfg.Providers)
 	updateRules(cfg.Rules, cfg.RuleProviders)
 	updateSniffer(cfg.Sniffer)
-	updateHosts(cfg.Hosts)
+	updateHosts(cfg.Hosts, cfg.HostAliases)
 	initInnerTcp()
 	updateDNS(cfg.DNS, cfg.General.IPv6)
 	loadProxyProvider(cfg.Providers)
@@ -87,11 +129,19 @@ func ApplyConfig(cfg *config.Config, force bool) {
 	updateGeneral(cfg.General, force)
 	updateIPTables(cfg)
 	updateTun(cfg.Tun)
+	updateTunnels(cfg.Tunnels)
 	updateExperimental(cfg)
 
+	configPath.Store(cfg.General.ConfigPath)
+	lastReload.Store(time.Now())
+
 	log.SetLevel(cfg.General.LogLevel)
 }
 
+func updateTunnels(tunnels []config.Tunnel) {
+	P.PatchTunnel(tunnels, tunnel.TCPIn())
+}
+
 func initInnerTcp() {
 	inner.New(tunnel.TCPIn())
 }
@@ -113,20 +163,47 @@ func GetGeneral() *config.General {
 			Authentication: authenticator,
 			AllowLan:       P.AllowLan(),
 			BindAddress:    P.BindAddress(),
+			UDP:            P.InboundUDP(),
 		},
-		Mode:          tunnel.Mode(),
-		LogLevel:      log.Level(),
-		IPv6:          !resolver.DisableIPv6,
-		GeodataLoader: G.LoaderName(),
-		Tun:           P.GetTunConf(),
-		Interface:     dialer.DefaultInterface.Load(),
-		Sniffing:      tunnel.IsSniffing(),
-		TCPConcurrent: dialer.GetDial(),
+		Mode:             tunnel.Mode(),
+		LogLevel:         log.Level(),
+		IPv6:             !resolver.DisableIPv6,
+		GeodataLoader:    G.LoaderName(),
+		Tun:              P.GetTunConf(),
+		Interface:        dialer.DefaultInterface.Load(),
+		Sniffing:         tunnel.IsSniffing(),
+		TCPConcurrent:    dialer.GetDial(),
+		FailClosed:       outboundgroup.FailClosed.Load(),
+		FirstByteTimeout: int(tunnel.FirstByteTimeout.Load() / time.Second),
+		DialRetry:        int(tunnel.DialRetry.Load()),
+		RetryStrategy:    tunnel.RetryStrategy.Load(),
+		ConfigPath:       configPath.Load(),
+		LastReload:       lastReload.Load(),
+		ProviderURLs:     map[string]map[string]string{"proxy": {}, "rule": {}},
+	}
+
+	for name, pd := range tunnel.Providers() {
+		if url := providerURL(pd); url != "" {
+			general.ProviderURLs["proxy"][name] = url
+		}
+	}
+	for name, pd := range tunnel.RuleProviders() {
+		if url := providerURL(pd); url != "" {
+			general.ProviderURLs["rule"][name] = url
+		}
 	}
 
 	return general
 }
 
+// providerURL returns the url of a provider fetched over http
+func providerURL(pd any) string {
+	if v, ok := pd.(interface{ URL() string }); ok {
+		return v.URL()
+	}
+	return ""
+}
+
 func updateExperimental(c *config.Config) {
 	runtime.GC()
 }
@@ -158,6 +235,7 @@ func updateDNS(c *config.DNS, generalIPv6 bool) {
 		EnhancedMode: c.EnhancedMode,
 		Pool:         c.FakeIPRange,
 		Hosts:        c.Hosts,
+		HostAliases:  c.HostAliases,
 		FallbackFilter: dns.FallbackFilter{
 			GeoIP:     c.FallbackFilter.GeoIP,
 			GeoIPCode: c.FallbackFilter.GeoIPCode,
@@ -190,12 +268,13 @@ func updateDNS(c *config.DNS, generalIPv6 bool) {
 	dns.ReCreateServer(c.Listen, r, m)
 }
 
-func updateHosts(tree *trie.DomainTrie[netip.Addr]) {
+func updateHosts(tree *trie.DomainTrie[netip.Addr], aliases *trie.DomainTrie[string]) {
 	resolver.DefaultHosts = tree
+	resolver.DefaultHostAliases = aliases
 }
 
-func updateProxies(proxies map[string]C.Proxy, providers map[string]provider.ProxyProvider) {
-	tunnel.UpdateProxies(proxies, providers)
+func updateProxies(proxies map[string]C.Proxy, chains map[string]C.Proxy, providers map[string]provider.ProxyProvider) {
+	tunnel.UpdateProxies(proxies, chains, providers)
 }
 
 func updateRules(rules []C.Rule, ruleProviders map[string]provider.RuleProvider) {
@@ -267,7 +346,7 @@ func updateSniffer(sniffer *config.Sniffer) {
 	if sniffer.Enable {
 		dispatcher, err := SNI.NewSnifferDispatcher(
 			sniffer.Sniffers, sniffer.ForceDomain, sniffer.SkipDomain, sniffer.Ports,
-			sniffer.ForceDnsMapping, sniffer.ParsePureIp,
+			sniffer.ForceDnsMapping, sniffer.ParsePureIp, sniffer.PureIpOnly, sniffer.OverrideDestination,
 		)
 		if err != nil {
 			log.Warnln("initial sniffer failed, err:%v", err)
@@ -302,6 +381,11 @@ func updateGeneral(general *config.General, force bool) {
 	}
 
 	adapter.UnifiedDelay.Store(general.UnifiedDelay)
+	loopback.Enable.Store(general.LoopbackDetection)
+	outboundgroup.FailClosed.Store(general.FailClosed)
+	tunnel.FirstByteTimeout.Store(time.Duration(general.FirstByteTimeout) * time.Second)
+	tunnel.DialRetry.Store(int32(general.DialRetry))
+	tunnel.RetryStrategy.Store(general.RetryStrategy)
 	dialer.DefaultInterface.Store(general.Interface)
 
 	if dialer.DefaultInterface.Load() != "" {
@@ -309,6 +393,13 @@ func updateGeneral(general *config.General, force bool) {
 	}
 
 	dialer.DefaultRoutingMark.Store(int32(general.RoutingMark))
+	// an unchanged TUN keeps its auto-redirect rules, Clash's own dials must keep the mark they let through
+	if mark := P.AutoRedirectMark(); mark != 0 && mark != int32(general.RoutingMark) {
+		if general.RoutingMark != 0 {
+			log.Warnln("routing-mark %#x is ignored while auto-redirect uses %#x, restart the TUN to change it", general.RoutingMark, mark)
+		}
+		dialer.DefaultRoutingMark.Store(mark)
+	}
 	if general.RoutingMark > 0 {
 		log.Infoln("Use routing mark: %#x", general.RoutingMark)
 	}
@@ -329,6 +420,13 @@ func updateGeneral(general *config.General, force bool) {
 	P.SetBindAddress(bindAddress)
 
 	P.SetInboundTfo(general.InboundTfo)
+	P.SetInboundUDP(general.UDP)
+	P.SetInboundListenOption(N.ListenOption{
+		Backlog:        general.Backlog,
+		AcceptRate:     general.AcceptRate,
+		AcceptBurst:    general.AcceptBurst,
+		MaxConnections: general.MaxConn,
+	})
 
 	tcpIn := tunnel.TCPIn()
 	udpIn := tunnel.UDPIn()
@@ -355,6 +453,58 @@ func updateProfile(cfg *config.Config) {
 	profile.StoreSelected.Store(profileCfg.StoreSelected)
 	if profileCfg.StoreSelected {
 		patchSelectGroup(cfg.Proxies)
+		patchHealthCheck(cfg.Proxies)
+	}
+	patchRemark(cfg.Proxies)
+}
+
+// patchRemark restores the notes set through the API, the proxy providers restore their own on every fetch
+func patchRemark(proxies map[string]C.Proxy) {
+	mapping := cachefile.Cache().RemarkMap()
+	if mapping == nil {
+		return
+	}
+
+	for name, proxy := range proxies {
+		outbound, ok := proxy.(*adapter.Proxy)
+		if !ok {
+			continue
+		}
+
+		if remark, exist := mapping[name]; exist {
+			outbound.SetRemark(remark)
+		}
+	}
+}
+
+// patchHealthCheck restores the test options changed through the API
+func patchHealthCheck(proxies map[string]C.Proxy) {
+	mapping := cachefile.Cache().HealthCheckMap()
+	if mapping == nil {
+		return
+	}
+
+	for name, proxy := range proxies {
+		outbound, ok := proxy.(*adapter.Proxy)
+		if !ok {
+			continue
+		}
+
+		group, ok := outbound.ProxyAdapter.(outboundgroup.HealthCheckEditable)
+		if !ok {
+			continue
+		}
+
+		option, exist := mapping[name]
+		if !exist {
+			continue
+		}
+
+		expectedStatus, err := utils.NewIntRanges[uint16](option.ExpectedStatus)
+		if err != nil {
+			continue
+		}
+		_ = group.SetHealthCheckOption(option.URL, expectedStatus)
 	}
 }
 
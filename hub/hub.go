@@ -45,6 +45,10 @@ func Parse(options ...Option) error {
 		go route.Start(cfg.General.ExternalController, cfg.General.Secret)
 	}
 
+	if cfg.General.ControllerMixed {
+		route.StartMixed(cfg.General.Secret)
+	}
+
 	executor.ApplyConfig(cfg, true)
 	return nil
 }
@@ -1,6 +1,7 @@
 package route
 
 import (
+	"encoding/json"
 	"github.com/Dreamacro/clash/component/dialer"
 	"net/http"
 	"path/filepath"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
+	"github.com/gorilla/websocket"
 )
 
 var (
@@ -28,10 +30,78 @@ func configRouter() http.Handler {
 	r.Get("/", getConfigs)
 	r.Put("/", updateConfigs)
 	r.Post("/geo", updateGeoDatabases)
+	r.Post("/geo/reload", reloadGeoDatabases)
 	r.Patch("/", patchConfigs)
+	r.Get("/mode", getMode)
 	return r
 }
 
+// getMode returns the mode, a websocket receives it again on every change and
+// may send {"mode": "global"} to change it
+func getMode(w http.ResponseWriter, r *http.Request) {
+	if !websocket.IsWebSocketUpgrade(r) {
+		render.JSON(w, r, render.M{"mode": tunnel.Mode()})
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	sub := tunnel.SubscribeMode()
+	defer tunnel.UnSubscribeMode(sub)
+
+	// only this goroutine writes to conn, the reader hands its errors over
+	errCh := make(chan error, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			req := struct {
+				Mode *tunnel.TunnelMode `json:"mode"`
+			}{}
+			if err := json.Unmarshal(msg, &req); err != nil {
+				select {
+				case errCh <- err:
+				default:
+				}
+				continue
+			}
+			if req.Mode != nil {
+				tunnel.SetMode(*req.Mode)
+				log.Infoln("Mode changed to %s through the API", req.Mode.String())
+			}
+		}
+	}()
+
+	if err := conn.WriteJSON(render.M{"mode": tunnel.Mode()}); err != nil {
+		return
+	}
+	for {
+		select {
+		case m, ok := <-sub:
+			if !ok {
+				return
+			}
+			err = conn.WriteJSON(render.M{"mode": m})
+		case readErr := <-errCh:
+			err = conn.WriteJSON(render.M{"message": readErr.Error()})
+		case <-done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
 type configSchema struct {
 	Port          *int               `json:"port"`
 	SocksPort     *int               `json:"socks-port"`
@@ -158,10 +228,16 @@ func updateConfigs(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	executor.ApplyConfig(cfg, force)
+	applyConfig(cfg, force)
 	render.NoContent(w, r)
 }
 
+// applyConfig applies cfg and the parts of it served by the controller itself
+func applyConfig(cfg *config.Config, force bool) {
+	executor.ApplyConfig(cfg, force)
+	updateMixedController(cfg.General)
+}
+
 func updateGeoDatabases(w http.ResponseWriter, r *http.Request) {
 	updateGeoMux.Lock()
 
@@ -195,8 +271,37 @@ func updateGeoDatabases(w http.ResponseWriter, r *http.Request) {
 
 		log.Warnln("[REST-API] update GEO databases successful, apply config...")
 
-		executor.ApplyConfig(cfg, false)
+		applyConfig(cfg, false)
 	}()
 
 	render.NoContent(w, r)
 }
+
+func reloadGeoDatabases(w http.ResponseWriter, r *http.Request) {
+	updateGeoMux.Lock()
+	defer updateGeoMux.Unlock()
+
+	if updatingGeo {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, newError("updating..."))
+		return
+	}
+
+	if err := config.ReloadGeoDatabases(); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, newError(err.Error()))
+		return
+	}
+
+	cfg, err := executor.ParseWithPath(constant.Path.Config())
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, newError(err.Error()))
+		return
+	}
+
+	log.Warnln("[REST-API] reload GEO databases successful, apply config...")
+
+	applyConfig(cfg, false)
+	render.NoContent(w, r)
+}
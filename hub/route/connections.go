@@ -17,11 +17,19 @@ import (
 func connectionRouter() http.Handler {
 	r := chi.NewRouter()
 	r.Get("/", getConnections)
+	r.Get("/sources", getSources)
 	r.Delete("/", closeAllConnections)
 	r.Delete("/{id}", closeConnection)
 	return r
 }
 
+// getSources reports the traffic by source ip, totals start over with the connection statistic
+func getSources(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, render.M{
+		"sources": statistic.DefaultManager.Sources(),
+	})
+}
+
 func getConnections(w http.ResponseWriter, r *http.Request) {
 	if !websocket.IsWebSocketUpgrade(r) {
 		snapshot := statistic.DefaultManager.Snapshot()
@@ -1,13 +1,23 @@
 package route
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"github.com/Dreamacro/clash/adapter"
+	"github.com/Dreamacro/clash/adapter/outboundgroup"
+	"github.com/Dreamacro/clash/common/batch"
+	"github.com/Dreamacro/clash/common/utils"
+	"github.com/Dreamacro/clash/component/profile/cachefile"
 	C "github.com/Dreamacro/clash/constant"
 	"github.com/Dreamacro/clash/tunnel"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
+	"github.com/gorilla/websocket"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"time"
 )
@@ -15,20 +25,26 @@ import (
 func GroupRouter() http.Handler {
 	r := chi.NewRouter()
 	r.Get("/", getGroups)
+	r.Get("/report", getGroupsReport)
 
 	r.Route("/{name}", func(r chi.Router) {
 		r.Use(parseProxyName, findProxyByName)
 		r.Get("/", getGroup)
 		r.Get("/delay", getGroupDelay)
+		r.Get("/delay/stream", getGroupDelayStream)
+		r.Get("/dead", getGroupDead)
+		r.Get("/healthcheck", getGroupHealthCheck)
+		r.Put("/healthcheck", updateGroupHealthCheck)
+		r.Put("/healthcheck/lazy", updateGroupHealthCheckLazy)
 	})
 	return r
 }
 
 func getGroups(w http.ResponseWriter, r *http.Request) {
-	var gs []C.Proxy
+	var gs []any
 	for _, p := range tunnel.Proxies() {
 		if _, ok := p.(*adapter.Proxy).ProxyAdapter.(C.Group); ok {
-			gs = append(gs, p)
+			gs = append(gs, sortByDelay(r, p))
 		}
 	}
 	render.JSON(w, r, render.M{
@@ -36,16 +52,268 @@ func getGroups(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+type groupReport struct {
+	Now   string `json:"now"`
+	Pass  bool   `json:"pass"`
+	Delay uint16 `json:"delay,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// getGroupsReport tests the current selection of every group once against url,
+// a quick summary of whether the config reaches the internet
+func getGroupsReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	testURL := query.Get("url")
+	if testURL == "" {
+		testURL = "https://www.gstatic.com/generate_204"
+	}
+	timeout := int64(5000)
+	if t := query.Get("timeout"); t != "" {
+		var err error
+		if timeout, err = strconv.ParseInt(t, 10, 32); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, ErrBadRequest)
+			return
+		}
+	}
+	expectedStatus, err := utils.NewIntRanges[uint16](query.Get("expected"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, newError(err.Error()))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Millisecond*time.Duration(timeout))
+	defer cancel()
+
+	b, _ := batch.New[groupReport](ctx, batch.WithConcurrencyNum[groupReport](10))
+	for name, p := range tunnel.Proxies() {
+		if _, ok := p.(*adapter.Proxy).ProxyAdapter.(C.Group); !ok {
+			continue
+		}
+		group := p
+		b.Go(name, func() (groupReport, error) {
+			now := group.Unwrap(&C.Metadata{NetWork: C.TCP}, false)
+			// a relay has no single selection, test the whole chain instead
+			if now == nil && group.Type() == C.Relay {
+				now = group
+			}
+			if now == nil {
+				return groupReport{Error: "no proxy selected"}, nil
+			}
+			report := groupReport{Now: now.Name()}
+			delay, err := now.URLTest(ctx, testURL, expectedStatus)
+			if err != nil {
+				report.Error = err.Error()
+			} else {
+				report.Pass, report.Delay = true, delay
+			}
+			return report, nil
+		})
+	}
+	b.Wait()
+
+	groups := map[string]groupReport{}
+	pass := 0
+	for name, result := range b.Result() {
+		groups[name] = result.Value
+		if result.Value.Pass {
+			pass++
+		}
+	}
+	render.JSON(w, r, render.M{
+		"url":    testURL,
+		"pass":   pass,
+		"fail":   len(groups) - pass,
+		"groups": groups,
+	})
+}
+
 func getGroup(w http.ResponseWriter, r *http.Request) {
 	proxy := r.Context().Value(CtxKeyProxy).(C.Proxy)
 	if _, ok := proxy.(*adapter.Proxy).ProxyAdapter.(C.Group); ok {
-		render.JSON(w, r, proxy)
+		render.JSON(w, r, sortByDelay(r, proxy))
 		return
 	}
 	render.Status(r, http.StatusNotFound)
 	render.JSON(w, r, ErrNotFound)
 }
 
+// getGroupDead lists the members failing the health check, hidden ones included
+func getGroupDead(w http.ResponseWriter, r *http.Request) {
+	proxy := r.Context().Value(CtxKeyProxy).(C.Proxy)
+	group, ok := proxy.(*adapter.Proxy).ProxyAdapter.(C.Group)
+	if !ok {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, ErrNotFound)
+		return
+	}
+
+	dead := []C.Proxy{}
+	for _, p := range group.GetProxies(false) {
+		if !p.Alive() {
+			dead = append(dead, p)
+		}
+	}
+	render.JSON(w, r, render.M{
+		"proxies": dead,
+	})
+}
+
+func getGroupHealthCheck(w http.ResponseWriter, r *http.Request) {
+	proxy := r.Context().Value(CtxKeyProxy).(C.Proxy)
+	group, ok := proxy.(*adapter.Proxy).ProxyAdapter.(outboundgroup.HealthCheckEditable)
+	if !ok {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, ErrNotFound)
+		return
+	}
+
+	url, expectedStatus, err := group.HealthCheckOption()
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, newError(err.Error()))
+		return
+	}
+	lazy, _ := group.HealthCheckLazy()
+
+	render.JSON(w, r, render.M{
+		"url":             url,
+		"expected-status": expectedStatus.String(),
+		"lazy":            lazy,
+	})
+}
+
+// updateGroupHealthCheckLazy switches the periodic check of a group between lazy and eager
+// until the next reload, it isn't stored in the cache file
+func updateGroupHealthCheckLazy(w http.ResponseWriter, r *http.Request) {
+	req := struct {
+		Lazy *bool `json:"lazy"`
+	}{}
+	if err := render.DecodeJSON(r.Body, &req); err != nil || req.Lazy == nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, ErrBadRequest)
+		return
+	}
+
+	proxy := r.Context().Value(CtxKeyProxy).(C.Proxy)
+	group, ok := proxy.(*adapter.Proxy).ProxyAdapter.(outboundgroup.HealthCheckEditable)
+	if !ok {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, ErrNotFound)
+		return
+	}
+
+	if err := group.SetHealthCheckLazy(*req.Lazy); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, newError(err.Error()))
+		return
+	}
+	render.NoContent(w, r)
+}
+
+func updateGroupHealthCheck(w http.ResponseWriter, r *http.Request) {
+	req := cachefile.HealthCheckOption{}
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, ErrBadRequest)
+		return
+	}
+
+	proxy := r.Context().Value(CtxKeyProxy).(C.Proxy)
+	group, ok := proxy.(*adapter.Proxy).ProxyAdapter.(outboundgroup.HealthCheckEditable)
+	if !ok {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, ErrNotFound)
+		return
+	}
+
+	if _, err := url.ParseRequestURI(req.URL); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, newError(fmt.Sprintf("invalid url: %s", err.Error())))
+		return
+	}
+
+	expectedStatus, err := utils.NewIntRanges[uint16](req.ExpectedStatus)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, newError(err.Error()))
+		return
+	}
+
+	if err := group.SetHealthCheckOption(req.URL, expectedStatus); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, newError(err.Error()))
+		return
+	}
+
+	req.ExpectedStatus = expectedStatus.String()
+	cachefile.Cache().SetHealthCheck(proxy.Name(), req)
+	render.NoContent(w, r)
+}
+
+// getGroupDelayStream tests the group like getGroupDelay but writes the result of each proxy
+// as soon as it finishes, one JSON object per line or per websocket message
+func getGroupDelayStream(w http.ResponseWriter, r *http.Request) {
+	proxy := r.Context().Value(CtxKeyProxy).(C.Proxy)
+	group, ok := proxy.(*adapter.Proxy).ProxyAdapter.(outboundgroup.URLTestStreamer)
+	if !ok {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, ErrNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	url := query.Get("url")
+	timeout, err := strconv.ParseInt(query.Get("timeout"), 10, 32)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, ErrBadRequest)
+		return
+	}
+
+	var wsConn *websocket.Conn
+	if websocket.IsWebSocketUpgrade(r) {
+		wsConn, err = upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer wsConn.Close()
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		render.Status(r, http.StatusOK)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Millisecond*time.Duration(timeout))
+	defer cancel()
+
+	buf := &bytes.Buffer{}
+	group.URLTestEach(ctx, url, func(proxy C.Proxy, delay uint16, err error) {
+		result := render.M{"name": proxy.Name(), "delay": delay}
+		if err != nil {
+			result["delay"] = 0
+			result["error"] = err.Error()
+		}
+
+		buf.Reset()
+		if json.NewEncoder(buf).Encode(result) != nil {
+			return
+		}
+
+		var writeErr error
+		if wsConn == nil {
+			_, writeErr = w.Write(buf.Bytes())
+			w.(http.Flusher).Flush()
+		} else {
+			writeErr = wsConn.WriteMessage(websocket.TextMessage, buf.Bytes())
+		}
+		if writeErr != nil {
+			// the client is gone, stop the remaining tests
+			cancel()
+		}
+	})
+}
+
 func getGroupDelay(w http.ResponseWriter, r *http.Request) {
 	proxy := r.Context().Value(CtxKeyProxy).(C.Proxy)
 	group, ok := proxy.(*adapter.Proxy).ProxyAdapter.(C.Group)
@@ -75,5 +343,86 @@ func getGroupDelay(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if query.Get("sort") == "delay" {
+		render.JSON(w, r, sortGroupDelay(group, dm))
+		return
+	}
+
 	render.JSON(w, r, dm)
 }
+
+// delaySortedProxy marshals a group with "all" ordered by the last delay, failed and untested members last
+type delaySortedProxy struct {
+	C.Proxy
+}
+
+func (p delaySortedProxy) MarshalJSON() ([]byte, error) {
+	buf, err := json.Marshal(p.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	group, ok := p.Proxy.(*adapter.Proxy).ProxyAdapter.(C.Group)
+	if !ok {
+		return buf, nil
+	}
+
+	mapping := map[string]any{}
+	if err := json.Unmarshal(buf, &mapping); err != nil {
+		return nil, err
+	}
+	if _, ok := mapping["all"]; !ok {
+		return buf, nil
+	}
+
+	hidden := map[string]struct{}{}
+	if names, ok := mapping["hidden"].([]any); ok {
+		for _, name := range names {
+			hidden[fmt.Sprint(name)] = struct{}{}
+		}
+	}
+
+	// GetProxies may hand out the slice cached by the group
+	proxies := append([]C.Proxy{}, group.GetProxies(false)...)
+	sort.SliceStable(proxies, func(i, j int) bool {
+		return proxies[i].LastDelay() < proxies[j].LastDelay()
+	})
+
+	all := []string{}
+	for _, proxy := range proxies {
+		if _, ok := hidden[proxy.Name()]; !ok {
+			all = append(all, proxy.Name())
+		}
+	}
+	mapping["all"] = all
+	return json.Marshal(mapping)
+}
+
+// sortByDelay wraps proxy for ?sort=delay, GUIs use it to show the fastest members first
+func sortByDelay(r *http.Request, proxy C.Proxy) any {
+	if r.URL.Query().Get("sort") == "delay" {
+		return delaySortedProxy{proxy}
+	}
+	return proxy
+}
+
+type proxyDelay struct {
+	Name  string `json:"name"`
+	Delay uint16 `json:"delay"`
+}
+
+// sortGroupDelay lists every proxy of group by delay, timed out ones come last with delay 0
+func sortGroupDelay(group C.Group, dm map[string]uint16) []proxyDelay {
+	proxies := group.GetProxies(false)
+	delays := make([]proxyDelay, 0, len(proxies))
+	for _, proxy := range proxies {
+		delays = append(delays, proxyDelay{Name: proxy.Name(), Delay: dm[proxy.Name()]})
+	}
+
+	sort.SliceStable(delays, func(i, j int) bool {
+		if delays[i].Delay == 0 || delays[j].Delay == 0 {
+			return delays[j].Delay == 0 && delays[i].Delay != 0
+		}
+		return delays[i].Delay < delays[j].Delay
+	})
+	return delays
+}
@@ -0,0 +1,69 @@
+package route
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Dreamacro/clash/tunnel"
+	"github.com/Dreamacro/clash/tunnel/statistic"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+func pauseRouter() http.Handler {
+	r := chi.NewRouter()
+	r.Get("/", getPause)
+	r.Put("/", setPause)
+	r.Delete("/", resume)
+	return r
+}
+
+func getPause(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, render.M{
+		"paused": tunnel.Paused(),
+	})
+}
+
+func setPause(w http.ResponseWriter, r *http.Request) {
+	req := struct {
+		Proxy    string `json:"proxy"`
+		Duration int    `json:"duration"`
+	}{}
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, ErrBadRequest)
+		return
+	}
+
+	switch req.Proxy {
+	case "":
+		req.Proxy = "DIRECT"
+	case "DIRECT", "REJECT":
+	default:
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, newError("proxy must be DIRECT or REJECT"))
+		return
+	}
+
+	if req.Duration < 0 {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, newError("duration must not be negative"))
+		return
+	}
+
+	tunnel.Pause(req.Proxy, time.Duration(req.Duration)*time.Second)
+
+	// drop existing connections so nothing keeps going through a proxy
+	snapshot := statistic.DefaultManager.Snapshot()
+	for _, c := range snapshot.Connections {
+		c.Close()
+	}
+
+	render.NoContent(w, r)
+}
+
+func resume(w http.ResponseWriter, r *http.Request) {
+	tunnel.Resume()
+	render.NoContent(w, r)
+}
@@ -3,7 +3,10 @@ package route
 import (
 	"context"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/Dreamacro/clash/component/profile/cachefile"
 	"github.com/Dreamacro/clash/constant/provider"
 	"github.com/Dreamacro/clash/tunnel"
 
@@ -20,6 +23,7 @@ func proxyProviderRouter() http.Handler {
 		r.Get("/", getProvider)
 		r.Put("/", updateProvider)
 		r.Get("/healthcheck", healthCheckProvider)
+		r.Get("/subscription", getProviderSubscription)
 	})
 	return r
 }
@@ -46,12 +50,62 @@ func updateProvider(w http.ResponseWriter, r *http.Request) {
 	render.NoContent(w, r)
 }
 
+type updateIfOlder interface {
+	UpdateIfOlder(maxAge time.Duration) error
+}
+
 func healthCheckProvider(w http.ResponseWriter, r *http.Request) {
 	provider := r.Context().Value(CtxKeyProvider).(provider.ProxyProvider)
+
+	// refresh the subscription first when it is older than `refresh` seconds
+	if refresh := r.URL.Query().Get("refresh"); refresh != "" {
+		seconds, err := strconv.ParseUint(refresh, 10, 32)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, ErrBadRequest)
+			return
+		}
+
+		if pd, ok := provider.(updateIfOlder); ok {
+			if err := pd.UpdateIfOlder(time.Duration(seconds) * time.Second); err != nil {
+				render.Status(r, http.StatusServiceUnavailable)
+				render.JSON(w, r, newError(err.Error()))
+				return
+			}
+		}
+	}
+
 	provider.HealthCheck()
+
+	// the check is finished at this point, `result` reports the delay of every proxy
+	if result, _ := strconv.ParseBool(r.URL.Query().Get("result")); result {
+		delays := render.M{}
+		for _, proxy := range provider.Proxies() {
+			delays[proxy.Name()] = proxy.LastDelay()
+		}
+		render.JSON(w, r, render.M{
+			"delay": delays,
+		})
+		return
+	}
 	render.NoContent(w, r)
 }
 
+// getProviderSubscription returns the node count and traffic recorded on every download of the provider
+func getProviderSubscription(w http.ResponseWriter, r *http.Request) {
+	provider := r.Context().Value(CtxKeyProvider).(provider.ProxyProvider)
+	history := cachefile.Cache().SubscriptionHistory(provider.Name())
+
+	var info any
+	if len(history) != 0 {
+		info = history[len(history)-1]
+	}
+	render.JSON(w, r, render.M{
+		"info":    info,
+		"history": history,
+	})
+}
+
 func parseProviderName(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		name := getEscapeParam(r, "name")
@@ -81,6 +135,7 @@ func ruleProviderRouter() http.Handler {
 	r.Get("/", getRuleProviders)
 	r.Route("/{name}", func(r chi.Router) {
 		r.Use(parseRuleProviderName, findRuleProviderByName)
+		r.Get("/", getRuleProvider)
 		r.Put("/", updateRuleProvider)
 	})
 	return r
@@ -93,6 +148,11 @@ func getRuleProviders(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func getRuleProvider(w http.ResponseWriter, r *http.Request) {
+	provider := r.Context().Value(CtxKeyProvider).(provider.RuleProvider)
+	render.JSON(w, r, provider)
+}
+
 func updateRuleProvider(w http.ResponseWriter, r *http.Request) {
 	provider := r.Context().Value(CtxKeyProvider).(provider.RuleProvider)
 	if err := provider.Update(); err != nil {
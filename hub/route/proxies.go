@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Dreamacro/clash/adapter"
 	"github.com/Dreamacro/clash/adapter/outboundgroup"
+	"github.com/Dreamacro/clash/common/utils"
 	"github.com/Dreamacro/clash/component/profile/cachefile"
 	C "github.com/Dreamacro/clash/constant"
 	"github.com/Dreamacro/clash/tunnel"
@@ -29,7 +32,9 @@ func proxyRouter() http.Handler {
 		r.Use(parseProxyName, findProxyByName)
 		r.Get("/", getProxy)
 		r.Get("/delay", getProxyDelay)
+		r.Get("/history", getProxyHistory)
 		r.Put("/", updateProxy)
+		r.Put("/remark", updateProxyRemark)
 	})
 	return r
 }
@@ -47,6 +52,9 @@ func findProxyByName(next http.Handler) http.Handler {
 		name := r.Context().Value(CtxKeyProxyName).(string)
 		proxies := tunnel.Proxies()
 		proxy, exist := proxies[name]
+		if !exist {
+			proxy, exist = findProviderProxy(name)
+		}
 		if !exist {
 			render.Status(r, http.StatusNotFound)
 			render.JSON(w, r, ErrNotFound)
@@ -58,16 +66,109 @@ func findProxyByName(next http.Handler) http.Handler {
 	})
 }
 
+// findProviderProxy looks name up in the proxy providers for the proxies not listed in the config
+func findProviderProxy(name string) (C.Proxy, bool) {
+	for _, pd := range tunnel.Providers() {
+		for _, proxy := range pd.Proxies() {
+			if proxy.Name() == name {
+				return proxy, true
+			}
+		}
+	}
+	return nil, false
+}
+
 func getProxies(w http.ResponseWriter, r *http.Request) {
 	proxies := tunnel.Proxies()
+	query := r.URL.Query()
+	if len(query) == 0 {
+		render.JSON(w, r, render.M{
+			"proxies": proxies,
+		})
+		return
+	}
+
+	filter, err := parseProxiesFilter(query)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, newError(err.Error()))
+		return
+	}
+
+	filtered := map[string]any{}
+	for name, proxy := range proxies {
+		if filter.match(proxy) {
+			filtered[name] = sortByDelay(r, proxy)
+		}
+	}
 	render.JSON(w, r, render.M{
-		"proxies": proxies,
+		"proxies": filtered,
 	})
 }
 
+// proxiesFilter is built from the query of GET /proxies,
+// e.g. ?group=true&selectable=true or ?type=Shadowsocks,Vmess
+type proxiesFilter struct {
+	types      map[string]struct{}
+	group      *bool
+	selectable bool
+}
+
+func parseProxiesFilter(query url.Values) (*proxiesFilter, error) {
+	filter := &proxiesFilter{}
+
+	if types := query.Get("type"); types != "" {
+		filter.types = map[string]struct{}{}
+		for _, tp := range strings.Split(types, ",") {
+			filter.types[strings.ToLower(strings.TrimSpace(tp))] = struct{}{}
+		}
+	}
+
+	if group := query.Get("group"); group != "" {
+		isGroup, err := strconv.ParseBool(group)
+		if err != nil {
+			return nil, fmt.Errorf("invalid group: %s", group)
+		}
+		filter.group = &isGroup
+	}
+
+	if selectable := query.Get("selectable"); selectable != "" {
+		isSelectable, err := strconv.ParseBool(selectable)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selectable: %s", selectable)
+		}
+		filter.selectable = isSelectable
+	}
+
+	return filter, nil
+}
+
+func (f *proxiesFilter) match(proxy C.Proxy) bool {
+	if f.types != nil {
+		if _, ok := f.types[strings.ToLower(proxy.Type().String())]; !ok {
+			return false
+		}
+	}
+
+	pa := proxy.(*adapter.Proxy).ProxyAdapter
+	if f.group != nil {
+		if _, isGroup := pa.(C.Group); isGroup != *f.group {
+			return false
+		}
+	}
+
+	if f.selectable {
+		if _, ok := pa.(outboundgroup.SelectAble); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
 func getProxy(w http.ResponseWriter, r *http.Request) {
 	proxy := r.Context().Value(CtxKeyProxy).(C.Proxy)
-	render.JSON(w, r, proxy)
+	render.JSON(w, r, sortByDelay(r, proxy))
 }
 
 type UpdateProxyRequest struct {
@@ -104,6 +205,46 @@ func updateProxy(w http.ResponseWriter, r *http.Request) {
 	render.NoContent(w, r)
 }
 
+type UpdateRemarkRequest struct {
+	Remark string `json:"remark"`
+}
+
+func updateProxyRemark(w http.ResponseWriter, r *http.Request) {
+	req := UpdateRemarkRequest{}
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, ErrBadRequest)
+		return
+	}
+
+	proxy := r.Context().Value(CtxKeyProxy).(*adapter.Proxy)
+	proxy.SetRemark(req.Remark)
+	cachefile.Cache().SetRemark(proxy.Name(), req.Remark)
+	render.NoContent(w, r)
+}
+
+// getProxyHistory returns up to the latest 100 delay records, or the latest limit ones
+func getProxyHistory(w http.ResponseWriter, r *http.Request) {
+	proxy := r.Context().Value(CtxKeyProxy).(*adapter.Proxy)
+	history := proxy.FullDelayHistory()
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, ErrBadRequest)
+			return
+		}
+		if limit < len(history) {
+			history = history[len(history)-limit:]
+		}
+	}
+
+	render.JSON(w, r, render.M{
+		"history": history,
+	})
+}
+
 func getProxyDelay(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 	url := query.Get("url")
@@ -114,12 +255,24 @@ func getProxyDelay(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	expectedStatus, err := utils.NewIntRanges[uint16](query.Get("expected"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, newError(err.Error()))
+		return
+	}
+
 	proxy := r.Context().Value(CtxKeyProxy).(C.Proxy)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(timeout))
 	defer cancel()
 
-	delay, err := proxy.URLTest(ctx, url)
+	test := proxy.URLTest
+	if query.Get("mode") == "ttfb" {
+		test = proxy.TTFBTest
+	}
+
+	delay, err := test(ctx, url, expectedStatus)
 	if ctx.Err() != nil {
 		render.Status(r, http.StatusGatewayTimeout)
 		render.JSON(w, r, ErrRequestTimeout)
@@ -33,8 +33,8 @@ func getRules(w http.ResponseWriter, r *http.Request) {
 			Proxy:   rule.Adapter(),
 			Size:    -1,
 		}
-		if rule.RuleType() == constant.GEOIP || rule.RuleType() == constant.GEOSITE {
-			r.Size = rule.(constant.RuleGroup).GetRecodeSize()
+		if group, ok := rule.(constant.RuleGroup); ok {
+			r.Size = group.GetRecodeSize()
 		}
 		rules = append(rules, r)
 
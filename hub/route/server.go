@@ -3,13 +3,18 @@ package route
 import (
 	"bytes"
 	"encoding/json"
+	"math"
 	"net"
 	"net/http"
+	"runtime"
+	"runtime/debug"
 	"strings"
 	"time"
 
+	"github.com/Dreamacro/clash/config"
 	C "github.com/Dreamacro/clash/constant"
 	_ "github.com/Dreamacro/clash/constant/mime"
+	"github.com/Dreamacro/clash/listener/mixed"
 	"github.com/Dreamacro/clash/log"
 	"github.com/Dreamacro/clash/tunnel/statistic"
 
@@ -20,8 +25,7 @@ import (
 )
 
 var (
-	serverSecret = ""
-	serverAddr   = ""
+	serverAddr = ""
 
 	uiPath = ""
 
@@ -37,6 +41,11 @@ type Traffic struct {
 	Down int64 `json:"down"`
 }
 
+type Memory struct {
+	Inuse   uint64 `json:"inuse"`
+	OSLimit uint64 `json:"oslimit"` // 0 means no limit
+}
+
 func SetUIPath(path string) {
 	uiPath = C.Path.Resolve(path)
 }
@@ -47,8 +56,41 @@ func Start(addr string, secret string) {
 	}
 
 	serverAddr = addr
-	serverSecret = secret
 
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Errorln("External controller listen error: %s", err)
+		return
+	}
+	serverAddr = l.Addr().String()
+	log.Infoln("RESTful API listening at: %s", serverAddr)
+	if err = http.Serve(l, router(secret)); err != nil {
+		log.Errorln("External controller serve error: %s", err)
+	}
+}
+
+// StartMixed serves the controller on the mixed port too, next to the proxy. The mixed port
+// can be reachable from the LAN, so the controller is only served there with a secret
+func StartMixed(secret string) {
+	if secret == "" {
+		mixed.SetController(nil)
+		log.Errorln("RESTful API is not served on the mixed port without a secret")
+		return
+	}
+	mixed.SetController(router(secret))
+	log.Infoln("RESTful API is served on the mixed port")
+}
+
+// updateMixedController serves the controller on the mixed port as the applied config says
+func updateMixedController(general *config.General) {
+	if general.ControllerMixed {
+		StartMixed(general.Secret)
+		return
+	}
+	mixed.SetController(nil)
+}
+
+func router(secret string) http.Handler {
 	r := chi.NewRouter()
 
 	corsM := cors.New(cors.Options{
@@ -60,11 +102,12 @@ func Start(addr string, secret string) {
 
 	r.Use(corsM.Handler)
 	r.Group(func(r chi.Router) {
-		r.Use(authentication)
+		r.Use(authentication(secret))
 
 		r.Get("/", hello)
 		r.Get("/logs", getLogs)
 		r.Get("/traffic", traffic)
+		r.Get("/memory", memory)
 		r.Get("/version", version)
 		r.Mount("/configs", configRouter())
 		r.Mount("/proxies", proxyRouter())
@@ -74,6 +117,7 @@ func Start(addr string, secret string) {
 		r.Mount("/providers/proxies", proxyProviderRouter())
 		r.Mount("/providers/rules", ruleProviderRouter())
 		r.Mount("/cache", cacheRouter())
+		r.Mount("/pause", pauseRouter())
 	})
 
 	if uiPath != "" {
@@ -86,50 +130,43 @@ func Start(addr string, secret string) {
 		})
 	}
 
-	l, err := net.Listen("tcp", addr)
-	if err != nil {
-		log.Errorln("External controller listen error: %s", err)
-		return
-	}
-	serverAddr = l.Addr().String()
-	log.Infoln("RESTful API listening at: %s", serverAddr)
-	if err = http.Serve(l, r); err != nil {
-		log.Errorln("External controller serve error: %s", err)
-	}
+	return r
 }
 
-func authentication(next http.Handler) http.Handler {
-	fn := func(w http.ResponseWriter, r *http.Request) {
-		if serverSecret == "" {
-			next.ServeHTTP(w, r)
-			return
-		}
+func authentication(secret string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if secret == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Browser websocket not support custom header
+			if websocket.IsWebSocketUpgrade(r) && r.URL.Query().Get("token") != "" {
+				token := r.URL.Query().Get("token")
+				if token != secret {
+					render.Status(r, http.StatusUnauthorized)
+					render.JSON(w, r, ErrUnauthorized)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
 
-		// Browser websocket not support custom header
-		if websocket.IsWebSocketUpgrade(r) && r.URL.Query().Get("token") != "" {
-			token := r.URL.Query().Get("token")
-			if token != serverSecret {
+			header := r.Header.Get("Authorization")
+			bearer, token, found := strings.Cut(header, " ")
+
+			hasInvalidHeader := bearer != "Bearer"
+			hasInvalidSecret := !found || token != secret
+			if hasInvalidHeader || hasInvalidSecret {
 				render.Status(r, http.StatusUnauthorized)
 				render.JSON(w, r, ErrUnauthorized)
 				return
 			}
 			next.ServeHTTP(w, r)
-			return
-		}
-
-		header := r.Header.Get("Authorization")
-		bearer, token, found := strings.Cut(header, " ")
-
-		hasInvalidHeader := bearer != "Bearer"
-		hasInvalidSecret := !found || token != serverSecret
-		if hasInvalidHeader || hasInvalidSecret {
-			render.Status(r, http.StatusUnauthorized)
-			render.JSON(w, r, ErrUnauthorized)
-			return
 		}
-		next.ServeHTTP(w, r)
+		return http.HandlerFunc(fn)
 	}
-	return http.HandlerFunc(fn)
 }
 
 func hello(w http.ResponseWriter, r *http.Request) {
@@ -179,6 +216,60 @@ func traffic(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func memory(w http.ResponseWriter, r *http.Request) {
+	var wsConn *websocket.Conn
+	if websocket.IsWebSocketUpgrade(r) {
+		var err error
+		wsConn, err = upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+	}
+
+	if wsConn == nil {
+		w.Header().Set("Content-Type", "application/json")
+		render.Status(r, http.StatusOK)
+	}
+
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+	buf := &bytes.Buffer{}
+	var err error
+	for range tick.C {
+		buf.Reset()
+		if err := json.NewEncoder(buf).Encode(currentMemory()); err != nil {
+			break
+		}
+
+		if wsConn == nil {
+			_, err = w.Write(buf.Bytes())
+			w.(http.Flusher).Flush()
+		} else {
+			err = wsConn.WriteMessage(websocket.TextMessage, buf.Bytes())
+		}
+
+		if err != nil {
+			break
+		}
+	}
+}
+
+// currentMemory reports the memory obtained from the OS and not yet returned to it
+func currentMemory() Memory {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	var limit uint64
+	if l := debug.SetMemoryLimit(-1); l != math.MaxInt64 {
+		limit = uint64(l)
+	}
+
+	return Memory{
+		Inuse:   stats.Sys - stats.HeapReleased,
+		OSLimit: limit,
+	}
+}
+
 type Log struct {
 	Type    string `json:"type"`
 	Payload string `json:"payload"`
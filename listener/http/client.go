@@ -12,6 +12,13 @@ import (
 	"github.com/Dreamacro/clash/transport/socks5"
 )
 
+type userAgentKey struct{}
+
+// withUserAgent makes the connection dialed for request carry its User-Agent
+func withUserAgent(request *http.Request) *http.Request {
+	return request.WithContext(context.WithValue(request.Context(), userAgentKey{}, request.UserAgent()))
+}
+
 func newClient(source net.Addr, in chan<- C.ConnContext) *http.Client {
 	return &http.Client{
 		Transport: &http.Transport{
@@ -20,7 +27,7 @@ func newClient(source net.Addr, in chan<- C.ConnContext) *http.Client {
 			IdleConnTimeout:       90 * time.Second,
 			TLSHandshakeTimeout:   10 * time.Second,
 			ExpectContinueTimeout: 1 * time.Second,
-			DialContext: func(context context.Context, network, address string) (net.Conn, error) {
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
 				if network != "tcp" && network != "tcp4" && network != "tcp6" {
 					return nil, errors.New("unsupported network " + network)
 				}
@@ -32,7 +39,9 @@ func newClient(source net.Addr, in chan<- C.ConnContext) *http.Client {
 
 				left, right := net.Pipe()
 
-				in <- inbound.NewHTTP(dstAddr, source, right)
+				connCtx := inbound.NewHTTP(dstAddr, source, right)
+				connCtx.Metadata().UserAgent, _ = ctx.Value(userAgentKey{}).(string)
+				in <- connCtx
 
 				return left, nil
 			},
@@ -23,6 +23,7 @@ func HandleConn(c net.Conn, in chan<- C.ConnContext, cache *cache.Cache[string,
 
 	keepAlive := true
 	trusted := cache == nil // disable authenticate if cache is nil
+	userAgent := ""
 
 	for keepAlive {
 		request, err := ReadRequest(conn.Reader())
@@ -73,7 +74,12 @@ func HandleConn(c net.Conn, in chan<- C.ConnContext, cache *cache.Cache[string,
 			if request.URL.Scheme == "" || request.URL.Host == "" {
 				resp = responseWith(request, http.StatusBadRequest)
 			} else {
-				resp, err = client.Do(request)
+				// the pooled connections carry the User-Agent they were dialed with, don't reuse them for another
+				if ua := request.UserAgent(); ua != userAgent {
+					client.CloseIdleConnections()
+					userAgent = ua
+				}
+				resp, err = client.Do(withUserAgent(request))
 				if err != nil {
 					resp = responseWith(request, http.StatusBadGateway)
 				}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Dreamacro/clash/common/cache"
+	N "github.com/Dreamacro/clash/common/net"
 	C "github.com/Dreamacro/clash/constant"
 )
 
@@ -32,11 +33,11 @@ func (l *Listener) Close() error {
 	return l.listener.Close()
 }
 
-func New(addr string, inboundTfo bool, in chan<- C.ConnContext) (*Listener, error) {
-	return NewWithAuthenticate(addr, in, true, inboundTfo)
+func New(addr string, inboundTfo bool, listenOpt N.ListenOption, in chan<- C.ConnContext) (*Listener, error) {
+	return NewWithAuthenticate(addr, in, true, inboundTfo, listenOpt)
 }
 
-func NewWithAuthenticate(addr string, in chan<- C.ConnContext, authenticate bool, inboundTfo bool) (*Listener, error) {
+func NewWithAuthenticate(addr string, in chan<- C.ConnContext, authenticate bool, inboundTfo bool, listenOpt N.ListenOption) (*Listener, error) {
 	lc := tfo.ListenConfig{
 		DisableTFO: !inboundTfo,
 	}
@@ -45,6 +46,7 @@ func NewWithAuthenticate(addr string, in chan<- C.ConnContext, authenticate bool
 	if err != nil {
 		return nil, err
 	}
+	l = listenOpt.Apply(l)
 
 	var c *cache.Cache[string, bool]
 	if authenticate {
@@ -46,7 +46,9 @@ func handleUpgrade(localConn net.Conn, source net.Addr, request *http.Request, i
 
 	left, right := net.Pipe()
 
-	in <- inbound.NewHTTP(dstAddr, source, right)
+	connCtx := inbound.NewHTTP(dstAddr, source, right)
+	connCtx.Metadata().UserAgent = request.UserAgent()
+	in <- connCtx
 
 	var remoteServer *N.BufferedConn
 	if request.TLS != nil {
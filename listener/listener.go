@@ -10,6 +10,7 @@ import (
 	"sync"
 
 	"github.com/Dreamacro/clash/adapter/inbound"
+	N "github.com/Dreamacro/clash/common/net"
 	"github.com/Dreamacro/clash/component/ebpf"
 	"github.com/Dreamacro/clash/config"
 	C "github.com/Dreamacro/clash/constant"
@@ -20,6 +21,7 @@ import (
 	"github.com/Dreamacro/clash/listener/redir"
 	"github.com/Dreamacro/clash/listener/socks"
 	"github.com/Dreamacro/clash/listener/tproxy"
+	LT "github.com/Dreamacro/clash/listener/tunnel"
 	"github.com/Dreamacro/clash/log"
 )
 
@@ -28,6 +30,9 @@ var (
 	bindAddress = "*"
 	lastTunConf *config.Tun
 	inboundTfo  = false
+	listenOpt   N.ListenOption
+	inboundUDP  map[string]bool
+	udpMux      sync.RWMutex
 
 	socksListener     *socks.Listener
 	socksUDPListener  *socks.UDPListener
@@ -42,6 +47,7 @@ var (
 	autoRedirListener *autoredir.Listener
 	autoRedirProgram  *ebpf.TcEBpfProgram
 	tcProgram         *ebpf.TcEBpfProgram
+	tunnelListeners   = map[tunnelKey]*LT.Listener{}
 
 	// lock for recreate function
 	socksMux     sync.Mutex
@@ -52,6 +58,7 @@ var (
 	tunMux       sync.Mutex
 	autoRedirMux sync.Mutex
 	tcMux        sync.Mutex
+	tunnelMux    sync.Mutex
 )
 
 type Ports struct {
@@ -87,10 +94,80 @@ func SetBindAddress(host string) {
 	bindAddress = host
 }
 
+// SetInboundUDP turns the UDP relay of the socks, mixed, redir and tproxy inbounds
+// on or off by name, inbounds missing from udp keep UDP on
+func SetInboundUDP(udp map[string]bool) {
+	udpMux.Lock()
+	defer udpMux.Unlock()
+	inboundUDP = udp
+}
+
+// InboundUDP returns the settings of SetInboundUDP
+func InboundUDP() map[string]bool {
+	udpMux.RLock()
+	defer udpMux.RUnlock()
+	udp := make(map[string]bool, len(inboundUDP))
+	for name, enable := range inboundUDP {
+		udp[name] = enable
+	}
+	return udp
+}
+
+// UDPEnabled reports whether the named inbound relays UDP
+func UDPEnabled(name string) bool {
+	udpMux.RLock()
+	defer udpMux.RUnlock()
+	enable, ok := inboundUDP[name]
+	return !ok || enable
+}
+
 func SetInboundTfo(itfo bool) {
+	if inboundTfo != itfo {
+		closeTCPInbounds()
+	}
 	inboundTfo = itfo
 }
 
+func SetInboundListenOption(opt N.ListenOption) {
+	if listenOpt != opt {
+		closeTCPInbounds()
+	}
+	listenOpt = opt
+}
+
+// closeTCPInbounds closes the listeners built with inboundTfo and listenOpt,
+// so the next ReCreate call applies the new socket options
+func closeTCPInbounds() {
+	httpMux.Lock()
+	if httpListener != nil {
+		httpListener.Close()
+		httpListener = nil
+	}
+	httpMux.Unlock()
+
+	socksMux.Lock()
+	if socksListener != nil {
+		socksListener.Close()
+		socksListener = nil
+	}
+	if socksUDPListener != nil {
+		socksUDPListener.Close()
+		socksUDPListener = nil
+	}
+	socksMux.Unlock()
+
+	mixedMux.Lock()
+	if mixedListener != nil {
+		mixedListener.Close()
+		mixedListener = nil
+	}
+	if mixedUDPLister != nil {
+		mixedUDPLister.Close()
+		mixedUDPLister = nil
+	}
+	mixedMux.Unlock()
+}
+
 func NewInner(tcpIn chan<- C.ConnContext) {
 	inner.New(tcpIn)
 }
@@ -120,7 +197,7 @@ func ReCreateHTTP(port int, tcpIn chan<- C.ConnContext) {
 		return
 	}
 
-	httpListener, err = http.New(addr, inboundTfo, tcpIn)
+	httpListener, err = http.New(addr, inboundTfo, listenOpt, tcpIn)
 	if err != nil {
 		log.Errorln("Start HTTP server error: %s", err.Error())
 		return
@@ -155,13 +232,16 @@ func ReCreateSocks(port int, tcpIn chan<- C.ConnContext, udpIn chan<- *inbound.P
 	}
 
 	if socksUDPListener != nil {
-		if socksUDPListener.RawAddress() != addr {
+		if socksUDPListener.RawAddress() != addr || !UDPEnabled("socks") {
 			socksUDPListener.Close()
 			socksUDPListener = nil
 		} else {
 			shouldUDPIgnore = true
 		}
 	}
+	if !UDPEnabled("socks") {
+		shouldUDPIgnore = true
+	}
 
 	if shouldTCPIgnore && shouldUDPIgnore {
 		return
@@ -171,20 +251,23 @@ func ReCreateSocks(port int, tcpIn chan<- C.ConnContext, udpIn chan<- *inbound.P
 		return
 	}
 
-	tcpListener, err := socks.New(addr, inboundTfo, tcpIn)
-	if err != nil {
-		return
+	if !shouldTCPIgnore {
+		if socksListener, err = socks.New(addr, inboundTfo, listenOpt, func() bool { return UDPEnabled("socks") }, tcpIn); err != nil {
+			return
+		}
 	}
 
-	udpListener, err := socks.NewUDP(addr, udpIn)
-	if err != nil {
-		tcpListener.Close()
-		return
+	if !shouldUDPIgnore {
+		if socksUDPListener, err = socks.NewUDP(addr, udpIn); err != nil {
+			// keep the TCP listener running when it wasn't created here
+			if !shouldTCPIgnore {
+				socksListener.Close()
+				socksListener = nil
+			}
+			return
+		}
 	}
 
-	socksListener = tcpListener
-	socksUDPListener = udpListener
-
 	log.Infoln("SOCKS proxy listening at: %s", socksListener.Address())
 }
 
@@ -201,34 +284,46 @@ func ReCreateRedir(port int, tcpIn chan<- C.ConnContext, udpIn chan<- *inbound.P
 
 	addr := genAddr(bindAddress, port, allowLan)
 
+	shouldTCPIgnore := false
+	shouldUDPIgnore := !UDPEnabled("redir")
+
 	if redirListener != nil {
 		if redirListener.RawAddress() == addr {
-			return
+			shouldTCPIgnore = true
+		} else {
+			redirListener.Close()
+			redirListener = nil
 		}
-		redirListener.Close()
-		redirListener = nil
 	}
 
 	if redirUDPListener != nil {
-		if redirUDPListener.RawAddress() == addr {
-			return
+		if redirUDPListener.RawAddress() == addr && !shouldUDPIgnore {
+			shouldUDPIgnore = true
+		} else {
+			redirUDPListener.Close()
+			redirUDPListener = nil
 		}
-		redirUDPListener.Close()
-		redirUDPListener = nil
 	}
 
-	if portIsZero(addr) {
+	if shouldTCPIgnore && shouldUDPIgnore {
 		return
 	}
 
-	redirListener, err = redir.New(addr, tcpIn)
-	if err != nil {
+	if portIsZero(addr) {
 		return
 	}
 
-	redirUDPListener, err = tproxy.NewUDP(addr, udpIn)
-	if err != nil {
-		log.Warnln("Failed to start Redir UDP Listener: %s", err)
+	if !shouldTCPIgnore {
+		if redirListener, err = redir.New(addr, tcpIn); err != nil {
+			return
+		}
+	}
+
+	if !shouldUDPIgnore {
+		var udpErr error
+		if redirUDPListener, udpErr = tproxy.NewUDP(addr, udpIn); udpErr != nil {
+			log.Warnln("Failed to start Redir UDP Listener: %s", udpErr)
+		}
 	}
 
 	log.Infoln("Redirect proxy listening at: %s", redirListener.Address())
@@ -247,34 +342,46 @@ func ReCreateTProxy(port int, tcpIn chan<- C.ConnContext, udpIn chan<- *inbound.
 
 	addr := genAddr(bindAddress, port, allowLan)
 
+	shouldTCPIgnore := false
+	shouldUDPIgnore := !UDPEnabled("tproxy")
+
 	if tproxyListener != nil {
 		if tproxyListener.RawAddress() == addr {
-			return
+			shouldTCPIgnore = true
+		} else {
+			tproxyListener.Close()
+			tproxyListener = nil
 		}
-		tproxyListener.Close()
-		tproxyListener = nil
 	}
 
 	if tproxyUDPListener != nil {
-		if tproxyUDPListener.RawAddress() == addr {
-			return
+		if tproxyUDPListener.RawAddress() == addr && !shouldUDPIgnore {
+			shouldUDPIgnore = true
+		} else {
+			tproxyUDPListener.Close()
+			tproxyUDPListener = nil
 		}
-		tproxyUDPListener.Close()
-		tproxyUDPListener = nil
 	}
 
-	if portIsZero(addr) {
+	if shouldTCPIgnore && shouldUDPIgnore {
 		return
 	}
 
-	tproxyListener, err = tproxy.New(addr, tcpIn)
-	if err != nil {
+	if portIsZero(addr) {
 		return
 	}
 
-	tproxyUDPListener, err = tproxy.NewUDP(addr, udpIn)
-	if err != nil {
-		log.Warnln("Failed to start TProxy UDP Listener: %s", err)
+	if !shouldTCPIgnore {
+		if tproxyListener, err = tproxy.New(addr, tcpIn); err != nil {
+			return
+		}
+	}
+
+	if !shouldUDPIgnore {
+		var udpErr error
+		if tproxyUDPListener, udpErr = tproxy.NewUDP(addr, udpIn); udpErr != nil {
+			log.Warnln("Failed to start TProxy UDP Listener: %s", udpErr)
+		}
 	}
 
 	log.Infoln("TProxy server listening at: %s", tproxyListener.Address())
@@ -305,13 +412,16 @@ func ReCreateMixed(port int, tcpIn chan<- C.ConnContext, udpIn chan<- *inbound.P
 		}
 	}
 	if mixedUDPLister != nil {
-		if mixedUDPLister.RawAddress() != addr {
+		if mixedUDPLister.RawAddress() != addr || !UDPEnabled("mixed") {
 			mixedUDPLister.Close()
 			mixedUDPLister = nil
 		} else {
 			shouldUDPIgnore = true
 		}
 	}
+	if !UDPEnabled("mixed") {
+		shouldUDPIgnore = true
+	}
 
 	if shouldTCPIgnore && shouldUDPIgnore {
 		return
@@ -321,20 +431,36 @@ func ReCreateMixed(port int, tcpIn chan<- C.ConnContext, udpIn chan<- *inbound.P
 		return
 	}
 
-	mixedListener, err = mixed.New(addr, inboundTfo, tcpIn)
-	if err != nil {
-		return
+	if !shouldTCPIgnore {
+		if mixedListener, err = mixed.New(addr, inboundTfo, listenOpt, func() bool { return UDPEnabled("mixed") }, tcpIn); err != nil {
+			return
+		}
 	}
 
-	mixedUDPLister, err = socks.NewUDP(addr, udpIn)
-	if err != nil {
-		mixedListener.Close()
-		return
+	if !shouldUDPIgnore {
+		if mixedUDPLister, err = socks.NewUDP(addr, udpIn); err != nil {
+			// keep the TCP listener running when it wasn't created here
+			if !shouldTCPIgnore {
+				mixedListener.Close()
+				mixedListener = nil
+			}
+			return
+		}
 	}
 
 	log.Infoln("Mixed(http+socks) proxy listening at: %s", mixedListener.Address())
 }
 
+// AutoRedirectMark returns the routing mark the auto-redirect rules of the running TUN skip, 0 without them
+func AutoRedirectMark() int32 {
+	tunMux.Lock()
+	defer tunMux.Unlock()
+	if tunLister == nil {
+		return 0
+	}
+	return tunLister.AutoRedirectMark()
+}
+
 func ReCreateTun(tunConf *config.Tun, tcpIn chan<- C.ConnContext, udpIn chan<- *inbound.PacketAdapter) {
 	tunMux.Lock()
 	defer tunMux.Unlock()
@@ -452,6 +578,47 @@ func ReCreateAutoRedir(ifaceNames []string, tcpIn chan<- C.ConnContext, _ chan<-
 	log.Infoln("Auto redirect proxy listening at: %s, attached tc ebpf program to interfaces %v", autoRedirListener.Address(), autoRedirProgram.RawNICs())
 }
 
+// tunnelKey identifies a tunnel listener, config.Tunnel itself holds a slice
+type tunnelKey struct {
+	address string
+	target  string
+	proxy   string
+}
+
+// PatchTunnel closes the tunnels no longer configured and starts the new ones,
+// the tunnels must come from config parsing with TargetAddr set
+func PatchTunnel(tunnels []config.Tunnel, tcpIn chan<- C.ConnContext) {
+	tunnelMux.Lock()
+	defer tunnelMux.Unlock()
+
+	wanted := make(map[tunnelKey]config.Tunnel, len(tunnels))
+	for _, tunnel := range tunnels {
+		wanted[tunnelKey{address: tunnel.Address, target: tunnel.Target, proxy: tunnel.Proxy}] = tunnel
+	}
+
+	for tunnel, l := range tunnelListeners {
+		if _, ok := wanted[tunnel]; !ok {
+			_ = l.Close()
+			delete(tunnelListeners, tunnel)
+		}
+	}
+
+	for key, tunnel := range wanted {
+		if _, ok := tunnelListeners[key]; ok {
+			continue
+		}
+
+		l, err := LT.New(tunnel.Address, tunnel.TargetAddr, tunnel.Proxy, tcpIn)
+		if err != nil {
+			log.Errorln("Start Tunnel server error: %s", err.Error())
+			continue
+		}
+		tunnelListeners[key] = l
+
+		log.Infoln("Tunnel(%s/%s) proxy listening at: %s", tunnel.Target, tunnel.Proxy, l.Address())
+	}
+}
+
 // GetPorts return the ports of proxy servers
 func GetPorts() *Ports {
 	ports := &Ports{}
@@ -535,6 +702,8 @@ func hasTunConfigChange(tunConf *config.Tun) bool {
 		lastTunConf.Device != tunConf.Device ||
 		lastTunConf.Stack != tunConf.Stack ||
 		lastTunConf.AutoRoute != tunConf.AutoRoute ||
+		lastTunConf.StrictRoute != tunConf.StrictRoute ||
+		lastTunConf.AutoRedirect != tunConf.AutoRedirect ||
 		lastTunConf.AutoDetectInterface != tunConf.AutoDetectInterface {
 		return true
 	}
@@ -0,0 +1,76 @@
+package mixed
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	N "github.com/Dreamacro/clash/common/net"
+)
+
+// controller serves the origin-form requests when the RESTful API shares the mixed port
+var controller atomic.Value
+
+type controllerHandler struct {
+	http.Handler
+}
+
+// SetController serves handler on the mixed port, nil stops serving the controller there
+func SetController(handler http.Handler) {
+	controller.Store(controllerHandler{handler})
+}
+
+// isControllerRequest peeks the request line, a proxy request is always CONNECT or absolute-form,
+// so a path right after the method means a request to the controller
+func isControllerRequest(conn *N.BufferedConn) bool {
+	// the longest method is OPTIONS, plus the separator and the leading slash
+	const maxPeek = len(http.MethodOptions) + 2
+
+	for n := 2; n <= maxPeek; n++ {
+		buf, err := conn.Peek(n)
+		if err != nil {
+			return false
+		}
+
+		if buf[n-2] == ' ' {
+			return buf[n-1] == '/' && !bytes.Equal(buf[:n-2], []byte(http.MethodConnect))
+		}
+	}
+
+	return false
+}
+
+func handleController(conn *N.BufferedConn) bool {
+	handler, _ := controller.Load().(controllerHandler)
+	if handler.Handler == nil || !isControllerRequest(conn) {
+		return false
+	}
+
+	_ = http.Serve(&singleConnListener{conn: conn}, handler)
+	return true
+}
+
+// singleConnListener hands a connection that already came from the mixed listener to http.Serve
+type singleConnListener struct {
+	conn net.Conn
+	once sync.Once
+}
+
+func (l *singleConnListener) Accept() (conn net.Conn, err error) {
+	err = io.EOF
+	l.once.Do(func() {
+		conn, err = l.conn, nil
+	})
+	return
+}
+
+func (l *singleConnListener) Close() error {
+	return nil
+}
+
+func (l *singleConnListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
@@ -38,7 +38,7 @@ func (l *Listener) Close() error {
 	return l.listener.Close()
 }
 
-func New(addr string, inboundTfo bool, in chan<- C.ConnContext) (*Listener, error) {
+func New(addr string, inboundTfo bool, listenOpt N.ListenOption, udp socks.UDPEnabled, in chan<- C.ConnContext) (*Listener, error) {
 	lc := tfo.ListenConfig{
 		DisableTFO: !inboundTfo,
 	}
@@ -46,6 +46,7 @@ func New(addr string, inboundTfo bool, in chan<- C.ConnContext) (*Listener, erro
 	if err != nil {
 		return nil, err
 	}
+	l = listenOpt.Apply(l)
 
 	ml := &Listener{
 		listener: l,
@@ -61,15 +62,17 @@ func New(addr string, inboundTfo bool, in chan<- C.ConnContext) (*Listener, erro
 				}
 				continue
 			}
-			go handleConn(c, in, ml.cache)
+			go handleConn(c, udp, in, ml.cache)
 		}
 	}()
 
 	return ml, nil
 }
 
-func handleConn(conn net.Conn, in chan<- C.ConnContext, cache *cache.Cache[string, bool]) {
-	conn.(*net.TCPConn).SetKeepAlive(true)
+func handleConn(conn net.Conn, udp socks.UDPEnabled, in chan<- C.ConnContext, cache *cache.Cache[string, bool]) {
+	if c, ok := conn.(*net.TCPConn); ok {
+		_ = c.SetKeepAlive(true)
+	}
 
 	bufConn := N.NewBufferedConn(conn)
 	head, err := bufConn.Peek(1)
@@ -81,8 +84,11 @@ func handleConn(conn net.Conn, in chan<- C.ConnContext, cache *cache.Cache[strin
 	case socks4.Version:
 		socks.HandleSocks4(bufConn, in)
 	case socks5.Version:
-		socks.HandleSocks5(bufConn, in)
+		socks.HandleSocks5(bufConn, udp(), in)
 	default:
+		if handleController(bufConn) {
+			return
+		}
 		http.HandleConn(bufConn, in, cache)
 	}
 }
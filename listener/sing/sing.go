@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net"
+	"net/netip"
 	"sync"
 	"time"
 
@@ -26,6 +27,9 @@ type ListenerHandler struct {
 	TcpIn chan<- C.ConnContext
 	UdpIn chan<- *inbound.PacketAdapter
 	Type  C.Type
+
+	// UDPUnreachable notifies source that destination is unreachable, optional
+	UDPUnreachable func(source, destination netip.AddrPort, payloadLen int) error
 }
 
 type waitCloseConn struct {
@@ -83,11 +87,14 @@ func (h *ListenerHandler) NewPacketConnection(ctx context.Context, conn network.
 		}
 		target := socks5.ParseAddr(dest.String())
 		packet := &packet{
-			conn:  &conn2,
-			mutex: &mutex,
-			rAddr: metadata.Source.UDPAddr(),
-			lAddr: conn.LocalAddr(),
-			buff:  buff,
+			conn:        &conn2,
+			mutex:       &mutex,
+			rAddr:       metadata.Source.UDPAddr(),
+			lAddr:       conn.LocalAddr(),
+			buff:        buff,
+			source:      metadata.Source.AddrPort(),
+			destination: dest.AddrPort(),
+			unreachable: h.UDPUnreachable,
 		}
 		select {
 		case h.UdpIn <- inbound.NewPacket(target, packet, h.Type):
@@ -107,6 +114,10 @@ type packet struct {
 	rAddr net.Addr
 	lAddr net.Addr
 	buff  *buf.Buffer
+
+	source      netip.AddrPort
+	destination netip.AddrPort
+	unreachable func(source, destination netip.AddrPort, payloadLen int) error
 }
 
 func (c *packet) Data() []byte {
@@ -142,6 +153,14 @@ func (c *packet) LocalAddr() net.Addr {
 	return c.rAddr
 }
 
+// Unreachable implements C.UDPPacketUnreachable
+func (c *packet) Unreachable() error {
+	if c.unreachable == nil {
+		return errors.New("unreachable is not supported by this inbound")
+	}
+	return c.unreachable(c.source, c.destination, c.buff.Len())
+}
+
 func (c *packet) Drop() {
 	c.buff.Release()
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/Dreamacro/clash/common/pool"
 	"github.com/Dreamacro/clash/component/resolver"
+	"github.com/Dreamacro/clash/dns"
 	"github.com/Dreamacro/clash/listener/sing"
 	"github.com/Dreamacro/clash/log"
 
@@ -44,7 +45,7 @@ func (h *ListenerHandler) ShouldHijackDns(targetAddr netip.AddrPort) bool {
 func (h *ListenerHandler) NewConnection(ctx context.Context, conn net.Conn, metadata M.Metadata) error {
 	if h.ShouldHijackDns(metadata.Destination.AddrPort()) {
 		log.Debugln("[DNS] hijack tcp:%s", metadata.Destination.String())
-		buff := pool.Get(pool.UDPBufferSize)
+		buff := pool.Get(pool.MaxUDPBufferSize)
 		defer func() {
 			_ = pool.Put(buff)
 			_ = conn.Close()
@@ -70,7 +71,7 @@ func (h *ListenerHandler) NewConnection(ctx context.Context, conn net.Conn, meta
 
 			err = func() error {
 				inData := buff[:n]
-				msg, err := RelayDnsPacket(inData)
+				msg, err := RelayDnsPacket(inData, D.MaxMsgSize)
 				if err != nil {
 					return err
 				}
@@ -118,7 +119,7 @@ func (h *ListenerHandler) NewPacketConnection(ctx context.Context, conn network.
 			}
 			go func() {
 				inData := buff.Bytes()
-				msg, err := RelayDnsPacket(inData)
+				msg, err := RelayDnsPacket(inData, 0)
 				if err != nil {
 					buff.Release()
 					return
@@ -146,7 +147,9 @@ func (h *ListenerHandler) NewPacketConnection(ctx context.Context, conn network.
 	return h.ListenerHandler.NewPacketConnection(ctx, conn, metadata)
 }
 
-func RelayDnsPacket(payload []byte) ([]byte, error) {
+// RelayDnsPacket resolves payload and packs a response no larger than maxSize,
+// zero means the UDP size advertised by the query
+func RelayDnsPacket(payload []byte, maxSize int) ([]byte, error) {
 	msg := &D.Msg{}
 	if err := msg.Unpack(payload); err != nil {
 		return nil, err
@@ -160,6 +163,11 @@ func RelayDnsPacket(payload []byte) ([]byte, error) {
 	}
 
 	r.SetRcode(msg, r.Rcode)
+	if maxSize == 0 {
+		maxSize = dns.UDPSize(msg)
+	}
+	// set TC so that the client retries over TCP
+	r.Truncate(maxSize)
 	r.Compress = true
 	return r.Pack()
 }
@@ -0,0 +1,132 @@
+package sing_tun
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/netip"
+)
+
+const (
+	icmpTypeDestinationUnreachable   = 3
+	icmpCodePortUnreachable          = 3
+	icmpv6TypeDestinationUnreachable = 1
+	icmpv6CodePortUnreachable        = 4
+
+	protocolICMP   = 1
+	protocolUDP    = 17
+	protocolICMPv6 = 58
+
+	ipv4HeaderLen = 20
+	ipv6HeaderLen = 40
+	udpHeaderLen  = 8
+	icmpHeaderLen = 8
+)
+
+// writePortUnreachable writes an ICMP port unreachable for the UDP datagram
+// source -> destination into the tun device, so the sender fails fast
+func writePortUnreachable(w io.Writer, source, destination netip.AddrPort, payloadLen int) error {
+	src, dst := source.Addr().Unmap(), destination.Addr().Unmap()
+	if !src.IsValid() || !dst.IsValid() || src.Is4() != dst.Is4() {
+		return errors.New("invalid udp address")
+	}
+
+	var packet []byte
+	if src.Is4() {
+		packet = buildICMPv4PortUnreachable(src, dst, source.Port(), destination.Port(), payloadLen)
+	} else {
+		packet = buildICMPv6PortUnreachable(src, dst, source.Port(), destination.Port(), payloadLen)
+	}
+
+	_, err := w.Write(packet)
+	return err
+}
+
+func buildICMPv4PortUnreachable(src, dst netip.Addr, srcPort, dstPort uint16, payloadLen int) []byte {
+	quoteLen := ipv4HeaderLen + udpHeaderLen
+	packet := make([]byte, ipv4HeaderLen+icmpHeaderLen+quoteLen)
+
+	// the ICMP message comes from the rejected destination
+	putIPv4Header(packet, dst, src, protocolICMP, len(packet))
+
+	icmp := packet[ipv4HeaderLen:]
+	icmp[0] = icmpTypeDestinationUnreachable
+	icmp[1] = icmpCodePortUnreachable
+
+	quote := icmp[icmpHeaderLen:]
+	putIPv4Header(quote, src, dst, protocolUDP, ipv4HeaderLen+udpHeaderLen+payloadLen)
+	putUDPHeader(quote[ipv4HeaderLen:], srcPort, dstPort, payloadLen)
+
+	binary.BigEndian.PutUint16(icmp[2:], checksum(icmp, 0))
+	return packet
+}
+
+func buildICMPv6PortUnreachable(src, dst netip.Addr, srcPort, dstPort uint16, payloadLen int) []byte {
+	quoteLen := ipv6HeaderLen + udpHeaderLen
+	packet := make([]byte, ipv6HeaderLen+icmpHeaderLen+quoteLen)
+
+	putIPv6Header(packet, dst, src, protocolICMPv6, icmpHeaderLen+quoteLen)
+
+	icmp := packet[ipv6HeaderLen:]
+	icmp[0] = icmpv6TypeDestinationUnreachable
+	icmp[1] = icmpv6CodePortUnreachable
+
+	quote := icmp[icmpHeaderLen:]
+	putIPv6Header(quote, src, dst, protocolUDP, udpHeaderLen+payloadLen)
+	putUDPHeader(quote[ipv6HeaderLen:], srcPort, dstPort, payloadLen)
+
+	// pseudo header: source, destination, upper-layer length and next header
+	var sum uint32
+	sum = sumBytes(packet[8:40], sum)
+	sum += uint32(len(icmp))
+	sum += protocolICMPv6
+	binary.BigEndian.PutUint16(icmp[2:], checksum(icmp, sum))
+	return packet
+}
+
+func putIPv4Header(b []byte, src, dst netip.Addr, protocol byte, totalLen int) {
+	b[0] = 0x45
+	binary.BigEndian.PutUint16(b[2:], uint16(totalLen))
+	b[8] = 64
+	b[9] = protocol
+	src4, dst4 := src.As4(), dst.As4()
+	copy(b[12:16], src4[:])
+	copy(b[16:20], dst4[:])
+	binary.BigEndian.PutUint16(b[10:], checksum(b[:ipv4HeaderLen], 0))
+}
+
+func putIPv6Header(b []byte, src, dst netip.Addr, nextHeader byte, payloadLen int) {
+	b[0] = 0x60
+	binary.BigEndian.PutUint16(b[4:], uint16(payloadLen))
+	b[6] = nextHeader
+	b[7] = 64
+	src16, dst16 := src.As16(), dst.As16()
+	copy(b[8:24], src16[:])
+	copy(b[24:40], dst16[:])
+}
+
+// putUDPHeader writes the quoted UDP header, the checksum is left zero
+// because the payload is not part of the ICMP message
+func putUDPHeader(b []byte, srcPort, dstPort uint16, payloadLen int) {
+	binary.BigEndian.PutUint16(b[0:], srcPort)
+	binary.BigEndian.PutUint16(b[2:], dstPort)
+	binary.BigEndian.PutUint16(b[4:], uint16(udpHeaderLen+payloadLen))
+}
+
+func sumBytes(b []byte, sum uint32) uint32 {
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(b[i:]))
+	}
+	if len(b)%2 == 1 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	return sum
+}
+
+func checksum(b []byte, initial uint32) uint16 {
+	sum := sumBytes(b, initial)
+	for sum>>16 != 0 {
+		sum = (sum & 0xffff) + (sum >> 16)
+	}
+	return ^uint16(sum)
+}
@@ -0,0 +1,141 @@
+package sing_tun
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecksum(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want uint16
+	}{
+		{name: "empty", data: nil, want: 0xffff},
+		{name: "rfc 1071", data: []byte{0x00, 0x01, 0xf2, 0x03, 0xf4, 0xf5, 0xf6, 0xf7}, want: 0x220d},
+		{name: "odd length", data: []byte{0x01}, want: 0xfeff},
+		{name: "carry", data: []byte{0xff, 0xff, 0x00, 0x01}, want: 0xfffe},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, checksum(tt.data, 0))
+		})
+	}
+}
+
+func TestBuildPortUnreachable(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		destination string
+		payloadLen  int
+		header      int
+		icmpType    byte
+		icmpCode    byte
+	}{
+		{
+			name:        "ipv4",
+			source:      "10.0.0.1:5353",
+			destination: "1.1.1.1:53",
+			payloadLen:  12,
+			header:      ipv4HeaderLen,
+			icmpType:    icmpTypeDestinationUnreachable,
+			icmpCode:    icmpCodePortUnreachable,
+		},
+		{
+			name:        "ipv4 mapped",
+			source:      "[::ffff:10.0.0.1]:5353",
+			destination: "[::ffff:1.1.1.1]:53",
+			payloadLen:  0,
+			header:      ipv4HeaderLen,
+			icmpType:    icmpTypeDestinationUnreachable,
+			icmpCode:    icmpCodePortUnreachable,
+		},
+		{
+			name:        "ipv6",
+			source:      "[fd00::1]:5353",
+			destination: "[2001:db8::1]:53",
+			payloadLen:  12,
+			header:      ipv6HeaderLen,
+			icmpType:    icmpv6TypeDestinationUnreachable,
+			icmpCode:    icmpv6CodePortUnreachable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, destination := netip.MustParseAddrPort(tt.source), netip.MustParseAddrPort(tt.destination)
+			var buf bytes.Buffer
+			if err := writePortUnreachable(&buf, source, destination, tt.payloadLen); err != nil {
+				t.Fatal(err)
+			}
+			packet := buf.Bytes()
+			src, dst := source.Addr().Unmap(), destination.Addr().Unmap()
+
+			icmp := packet[tt.header:]
+			quote := icmp[icmpHeaderLen:]
+			assert.Equal(t, tt.header+icmpHeaderLen+tt.header+udpHeaderLen, len(packet))
+			assert.Equal(t, tt.icmpType, icmp[0])
+			assert.Equal(t, tt.icmpCode, icmp[1])
+
+			var sum uint32
+			if src.Is4() {
+				// the reply goes from the destination back to the source
+				assert.Equal(t, dst.AsSlice(), packet[12:16])
+				assert.Equal(t, src.AsSlice(), packet[16:20])
+				assert.Equal(t, byte(protocolICMP), packet[9])
+				assert.Equal(t, len(packet), int(binary.BigEndian.Uint16(packet[2:])))
+				assert.Zero(t, checksum(packet[:ipv4HeaderLen], 0))
+
+				assert.Equal(t, src.AsSlice(), quote[12:16])
+				assert.Equal(t, dst.AsSlice(), quote[16:20])
+				assert.Equal(t, byte(protocolUDP), quote[9])
+				assert.Equal(t, ipv4HeaderLen+udpHeaderLen+tt.payloadLen, int(binary.BigEndian.Uint16(quote[2:])))
+				assert.Zero(t, checksum(quote[:ipv4HeaderLen], 0))
+			} else {
+				assert.Equal(t, dst.AsSlice(), packet[8:24])
+				assert.Equal(t, src.AsSlice(), packet[24:40])
+				assert.Equal(t, byte(protocolICMPv6), packet[6])
+				assert.Equal(t, len(icmp), int(binary.BigEndian.Uint16(packet[4:])))
+
+				assert.Equal(t, src.AsSlice(), quote[8:24])
+				assert.Equal(t, dst.AsSlice(), quote[24:40])
+				assert.Equal(t, byte(protocolUDP), quote[6])
+				assert.Equal(t, udpHeaderLen+tt.payloadLen, int(binary.BigEndian.Uint16(quote[4:])))
+
+				sum = sumBytes(packet[8:40], 0) + uint32(len(icmp)) + protocolICMPv6
+			}
+			// a valid checksum sums to zero over the message
+			assert.Zero(t, checksum(icmp, sum))
+
+			udp := quote[tt.header:]
+			assert.Equal(t, source.Port(), binary.BigEndian.Uint16(udp[0:]))
+			assert.Equal(t, destination.Port(), binary.BigEndian.Uint16(udp[2:]))
+			assert.Equal(t, udpHeaderLen+tt.payloadLen, int(binary.BigEndian.Uint16(udp[4:])))
+		})
+	}
+}
+
+func TestWritePortUnreachable_InvalidAddress(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      netip.AddrPort
+		destination netip.AddrPort
+	}{
+		{name: "invalid source", source: netip.AddrPort{}, destination: netip.MustParseAddrPort("1.1.1.1:53")},
+		{name: "mixed families", source: netip.MustParseAddrPort("10.0.0.1:5353"), destination: netip.MustParseAddrPort("[2001:db8::1]:53")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.Error(t, writePortUnreachable(&buf, tt.source, tt.destination, 0))
+			assert.Zero(t, buf.Len())
+		})
+	}
+}
@@ -0,0 +1,93 @@
+//go:build linux && !android
+
+package sing_tun
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/Dreamacro/clash/common/cmd"
+	"github.com/Dreamacro/clash/component/dialer"
+	C "github.com/Dreamacro/clash/constant"
+	"github.com/Dreamacro/clash/listener/redir"
+	"github.com/Dreamacro/clash/log"
+)
+
+const autoRedirectTable = "clash_tun"
+
+// startAutoRedirect redirects the IPv4 TCP connections of the host and of the forwarded
+// traffic to a redir listener with nftables, the redirect happens before routing so even
+// sockets bound to an address or interface are captured. Clash's own connections carry the
+// routing mark and are left alone, UDP and IPv6 still go through the tun.
+func (l *Listener) startAutoRedirect(tcpIn chan<- C.ConnContext) error {
+	if _, err := cmd.ExecCmd("nft --version"); err != nil {
+		return fmt.Errorf("command nft does not exist: %w", err)
+	}
+
+	rl, err := redir.New("127.0.0.1:0", tcpIn)
+	if err != nil {
+		return err
+	}
+	_, port, _ := net.SplitHostPort(rl.Address())
+	l.autoRedirect = rl
+
+	if dialer.DefaultRoutingMark.Load() == 0 {
+		dialer.DefaultRoutingMark.Store(2158)
+	}
+	mark := dialer.DefaultRoutingMark.Load()
+	l.autoRedirectMark = mark
+
+	// a table left by a crash would redirect to a closed port
+	cleanupAutoRedirect()
+
+	rules := []string{
+		fmt.Sprintf("add table inet %s", autoRedirectTable),
+		fmt.Sprintf("add chain inet %s output { type nat hook output priority -100 ; policy accept ; }", autoRedirectTable),
+		fmt.Sprintf("add chain inet %s prerouting { type nat hook prerouting priority -100 ; policy accept ; }", autoRedirectTable),
+		fmt.Sprintf("add rule inet %s prerouting iifname %s return", autoRedirectTable, l.tunName),
+	}
+	for _, chain := range []string{"output", "prerouting"} {
+		for _, rule := range []string{
+			fmt.Sprintf("meta mark %#x return", mark),
+			"meta nfproto != ipv4 return",
+			"meta l4proto != tcp return",
+			"tcp dport 53 return", // left to the dns-hijack of the tun
+			"fib daddr type local return",
+			"ip daddr { 0.0.0.0/8, 10.0.0.0/8, 100.64.0.0/10, 127.0.0.0/8, 169.254.0.0/16, 172.16.0.0/12, 192.168.0.0/16, 224.0.0.0/4, 240.0.0.0/4 } return",
+			fmt.Sprintf("redirect to :%s", port),
+		} {
+			rules = append(rules, fmt.Sprintf("add rule inet %s %s %s", autoRedirectTable, chain, rule))
+		}
+	}
+
+	for _, rule := range rules {
+		log.Debugln("[TUN] nft %s", rule)
+		if _, err := cmd.ExecCmd("nft " + rule); err != nil {
+			cleanupAutoRedirect()
+			l.autoRedirectMark = 0
+			return err
+		}
+	}
+
+	log.Infoln("[TUN] auto-redirect TCP to %s", rl.Address())
+	return nil
+}
+
+func (l *Listener) stopAutoRedirect() {
+	if l.autoRedirect == nil {
+		return
+	}
+	cleanupAutoRedirect()
+	_ = l.autoRedirect.Close()
+	l.autoRedirect = nil
+	l.autoRedirectMark = 0
+}
+
+func cleanupAutoRedirect() {
+	if _, err := cmd.ExecCmd(fmt.Sprintf("nft list table inet %s", autoRedirectTable)); err != nil {
+		return
+	}
+	if _, err := cmd.ExecCmd(fmt.Sprintf("nft delete table inet %s", autoRedirectTable)); err != nil {
+		log.Warnln("[TUN] remove auto-redirect rules: %s", err)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !linux || android
+
+package sing_tun
+
+import (
+	"errors"
+
+	C "github.com/Dreamacro/clash/constant"
+)
+
+func (l *Listener) startAutoRedirect(tcpIn chan<- C.ConnContext) error {
+	return errors.New("only supported on Linux")
+}
+
+func (l *Listener) stopAutoRedirect() {}
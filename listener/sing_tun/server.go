@@ -2,6 +2,7 @@ package sing_tun
 
 import (
 	"context"
+	"io"
 	"net"
 	"net/netip"
 	"runtime"
@@ -37,6 +38,11 @@ type Listener struct {
 	networkUpdateMonitor    tun.NetworkUpdateMonitor
 	defaultInterfaceMonitor tun.DefaultInterfaceMonitor
 	packageManager          tun.PackageManager
+
+	// autoRedirect is the redir listener the nftables rules of auto-redirect point to
+	autoRedirect io.Closer
+	// autoRedirectMark is the routing mark the nftables rules let through, 0 without auto-redirect
+	autoRedirectMark int32
 }
 
 func CalculateInterfaceName(name string) (tunName string) {
@@ -123,6 +129,7 @@ func New(options config.Tun, tcpIn chan<- C.ConnContext, udpIn chan<- *inbound.P
 		closed:  false,
 		options: options,
 		handler: handler,
+		tunName: tunName,
 	}
 	defer func() {
 		if err != nil {
@@ -188,6 +195,9 @@ func New(options config.Tun, tcpIn chan<- C.ConnContext, udpIn chan<- *inbound.P
 		return
 	}
 	l.tunIf = tunIf
+	handler.UDPUnreachable = func(source, destination netip.AddrPort, payloadLen int) error {
+		return writePortUnreachable(tunIf, source, destination, payloadLen)
+	}
 	l.tunStack, err = tun.NewStack(strings.ToLower(options.Stack.String()), tun.StackOptions{
 		Context:                context.TODO(),
 		Tun:                    tunIf,
@@ -207,11 +217,28 @@ func New(options config.Tun, tcpIn chan<- C.ConnContext, udpIn chan<- *inbound.P
 	if err != nil {
 		return
 	}
+	if options.AutoRedirect {
+		if !options.AutoRoute {
+			err = E.New("auto-redirect requires auto-route")
+			return
+		}
+		err = l.startAutoRedirect(tcpIn)
+		if err != nil {
+			err = E.Cause(err, "auto-redirect")
+			return
+		}
+	}
 	log.Infoln("Tun adapter listening at: %s(%s,%s), mtu: %d, auto route: %v, ip stack: %s",
 		tunName, tunOptions.Inet4Address, tunOptions.Inet6Address, tunMTU, options.AutoRoute, options.Stack)
 	return
 }
 
+// AutoRedirectMark returns the routing mark of Clash's own connections the auto-redirect rules skip,
+// 0 when auto-redirect is off
+func (l *Listener) AutoRedirectMark() int32 {
+	return l.autoRedirectMark
+}
+
 func (l *Listener) FlushDefaultInterface() {
 	if l.options.AutoDetectInterface {
 		targetInterface := dialer.DefaultInterface.Load()
@@ -270,6 +297,7 @@ func parseRange(uidRanges []ranges.Range[uint32], rangeList []string) ([]ranges.
 
 func (l *Listener) Close() {
 	l.closed = true
+	l.stopAutoRedirect()
 	_ = common.Close(
 		l.tunStack,
 		l.tunIf,
@@ -20,6 +20,9 @@ type Listener struct {
 	closed   bool
 }
 
+// UDPEnabled reports whether the inbound relays UDP, UDP ASSOCIATE is refused when it doesn't
+type UDPEnabled func() bool
+
 // RawAddress implements C.Listener
 func (l *Listener) RawAddress() string {
 	return l.addr
@@ -36,7 +39,7 @@ func (l *Listener) Close() error {
 	return l.listener.Close()
 }
 
-func New(addr string, inboundTfo bool, in chan<- C.ConnContext) (*Listener, error) {
+func New(addr string, inboundTfo bool, listenOpt N.ListenOption, udp UDPEnabled, in chan<- C.ConnContext) (*Listener, error) {
 	lc := tfo.ListenConfig{
 		DisableTFO: !inboundTfo,
 	}
@@ -44,6 +47,7 @@ func New(addr string, inboundTfo bool, in chan<- C.ConnContext) (*Listener, erro
 	if err != nil {
 		return nil, err
 	}
+	l = listenOpt.Apply(l)
 
 	sl := &Listener{
 		listener: l,
@@ -58,15 +62,17 @@ func New(addr string, inboundTfo bool, in chan<- C.ConnContext) (*Listener, erro
 				}
 				continue
 			}
-			go handleSocks(c, in)
+			go handleSocks(c, udp, in)
 		}
 	}()
 
 	return sl, nil
 }
 
-func handleSocks(conn net.Conn, in chan<- C.ConnContext) {
-	conn.(*net.TCPConn).SetKeepAlive(true)
+func handleSocks(conn net.Conn, udp UDPEnabled, in chan<- C.ConnContext) {
+	if c, ok := conn.(*net.TCPConn); ok {
+		_ = c.SetKeepAlive(true)
+	}
 	bufConn := N.NewBufferedConn(conn)
 	head, err := bufConn.Peek(1)
 	if err != nil {
@@ -78,7 +84,7 @@ func handleSocks(conn net.Conn, in chan<- C.ConnContext) {
 	case socks4.Version:
 		HandleSocks4(bufConn, in)
 	case socks5.Version:
-		HandleSocks5(bufConn, in)
+		HandleSocks5(bufConn, udp(), in)
 	default:
 		conn.Close()
 	}
@@ -93,8 +99,9 @@ func HandleSocks4(conn net.Conn, in chan<- C.ConnContext) {
 	in <- inbound.NewSocket(socks5.ParseAddr(addr), conn, C.SOCKS4)
 }
 
-func HandleSocks5(conn net.Conn, in chan<- C.ConnContext) {
-	target, command, err := socks5.ServerHandshake(conn, authStore.Authenticator())
+// HandleSocks5 serves a SOCKS5 connection, UDP ASSOCIATE gets "command not supported" unless udp is true
+func HandleSocks5(conn net.Conn, udp bool, in chan<- C.ConnContext) {
+	target, command, user, err := socks5.ServerHandshakeWithUDP(conn, authStore.Authenticator(), udp)
 	if err != nil {
 		conn.Close()
 		return
@@ -104,5 +111,7 @@ func HandleSocks5(conn net.Conn, in chan<- C.ConnContext) {
 		io.Copy(io.Discard, conn)
 		return
 	}
-	in <- inbound.NewSocket(target, conn, C.SOCKS5)
+	connCtx := inbound.NewSocket(target, conn, C.SOCKS5)
+	connCtx.Metadata().InUser = user
+	in <- connCtx
 }
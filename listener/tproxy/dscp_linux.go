@@ -0,0 +1,74 @@
+//go:build linux
+
+package tproxy
+
+import (
+	"net"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// setRecvTOS asks the kernel for the traffic class of the received packets, failing only loses the DSCP rule
+func setRecvTOS(fd int, isIPv6 bool) {
+	_ = syscall.SetsockoptInt(fd, syscall.SOL_IP, syscall.IP_RECVTOS, 1)
+	if isIPv6 {
+		_ = syscall.SetsockoptInt(fd, syscall.SOL_IPV6, syscall.IPV6_RECVTCLASS, 1)
+	}
+}
+
+// getDSCP reads the IP_TOS or IPV6_TCLASS control message of a received packet
+func getDSCP(oob []byte, oobn int) *uint8 {
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil {
+		return nil
+	}
+	return parseTOS(msgs)
+}
+
+// getConnDSCP reads the traffic class of the SYN of an accepted connection with IP_PKTOPTIONS,
+// it only works for IPv4 and IPv4-mapped connections
+func getConnDSCP(conn net.Conn) *uint8 {
+	tc, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+	rc, err := tc.SyscallConn()
+	if err != nil {
+		return nil
+	}
+
+	var dscp *uint8
+	_ = rc.Control(func(fd uintptr) {
+		buf := make([]byte, 64)
+		l := uint32(len(buf))
+		_, _, errno := unix.Syscall6(unix.SYS_GETSOCKOPT, fd, unix.SOL_IP, unix.IP_PKTOPTIONS, uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&l)), 0)
+		if errno != 0 {
+			return
+		}
+		dscp = getDSCP(buf, int(l))
+	})
+	return dscp
+}
+
+func parseTOS(msgs []syscall.SocketControlMessage) *uint8 {
+	for _, msg := range msgs {
+		if len(msg.Data) == 0 {
+			continue
+		}
+		if (msg.Header.Level == syscall.SOL_IP && msg.Header.Type == syscall.IP_TOS) ||
+			(msg.Header.Level == syscall.SOL_IPV6 && msg.Header.Type == syscall.IPV6_TCLASS) {
+			// the value is a byte for IP_TOS of udp and a native int otherwise, the tos fits in the low byte either way
+			var tos uint8
+			if len(msg.Data) >= 4 {
+				tos = uint8(*(*int32)(unsafe.Pointer(&msg.Data[0])))
+			} else {
+				tos = msg.Data[0]
+			}
+			dscp := tos >> 2
+			return &dscp
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package tproxy
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func controlMessage(level, typ int32, data []byte) syscall.SocketControlMessage {
+	return syscall.SocketControlMessage{
+		Header: syscall.Cmsghdr{Level: level, Type: typ},
+		Data:   data,
+	}
+}
+
+// nativeInt encodes v as a little endian int, the byte order of the platforms tproxy runs on
+func nativeInt(v byte) []byte {
+	return []byte{v, 0, 0, 0}
+}
+
+func TestParseTOS(t *testing.T) {
+	tests := []struct {
+		name string
+		msgs []syscall.SocketControlMessage
+		want *uint8
+	}{
+		{name: "none", msgs: nil, want: nil},
+		{name: "ipv4 udp byte", msgs: []syscall.SocketControlMessage{controlMessage(syscall.SOL_IP, syscall.IP_TOS, []byte{0xb8})}, want: dscpPtr(46)},
+		{name: "ipv4 tcp int", msgs: []syscall.SocketControlMessage{controlMessage(syscall.SOL_IP, syscall.IP_TOS, nativeInt(0x28))}, want: dscpPtr(10)},
+		{name: "ipv6 tclass", msgs: []syscall.SocketControlMessage{controlMessage(syscall.SOL_IPV6, syscall.IPV6_TCLASS, nativeInt(0xb9))}, want: dscpPtr(46)},
+		{name: "empty data", msgs: []syscall.SocketControlMessage{controlMessage(syscall.SOL_IP, syscall.IP_TOS, nil)}, want: nil},
+		{name: "other message", msgs: []syscall.SocketControlMessage{controlMessage(syscall.SOL_IP, syscall.IP_TTL, nativeInt(64))}, want: nil},
+		{
+			name: "after other message",
+			msgs: []syscall.SocketControlMessage{
+				controlMessage(syscall.SOL_IP, syscall.IP_TTL, nativeInt(64)),
+				controlMessage(syscall.SOL_IP, syscall.IP_TOS, []byte{0x04}),
+			},
+			want: dscpPtr(1),
+		},
+		{name: "tos type at the ipv6 level", msgs: []syscall.SocketControlMessage{controlMessage(syscall.SOL_IPV6, syscall.IP_TOS, []byte{0xb8})}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseTOS(tt.msgs))
+		})
+	}
+}
+
+func dscpPtr(dscp uint8) *uint8 {
+	return &dscp
+}
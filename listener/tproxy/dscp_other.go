@@ -0,0 +1,13 @@
+//go:build !linux
+
+package tproxy
+
+import "net"
+
+func getDSCP(oob []byte, oobn int) *uint8 {
+	return nil
+}
+
+func getConnDSCP(conn net.Conn) *uint8 {
+	return nil
+}
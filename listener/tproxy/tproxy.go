@@ -33,7 +33,9 @@ func (l *Listener) Close() error {
 func (l *Listener) handleTProxy(conn net.Conn, in chan<- C.ConnContext) {
 	target := socks5.ParseAddrToSocksAddr(conn.LocalAddr())
 	conn.(*net.TCPConn).SetKeepAlive(true)
-	in <- inbound.NewSocket(target, conn, C.TPROXY)
+	connCtx := inbound.NewSocket(target, conn, C.TPROXY)
+	connCtx.Metadata().DSCP = getConnDSCP(conn)
+	in <- connCtx
 }
 
 func New(addr string, in chan<- C.ConnContext) (*Listener, error) {
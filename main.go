@@ -11,6 +11,7 @@ import (
 	"strings"
 	"syscall"
 
+	"github.com/Dreamacro/clash/component/resource"
 	"github.com/Dreamacro/clash/config"
 	C "github.com/Dreamacro/clash/constant"
 	"github.com/Dreamacro/clash/hub"
@@ -24,23 +25,37 @@ var (
 	flagset            map[string]bool
 	version            bool
 	testConfig         bool
+	allowCommand       bool
 	geodataMode        bool
 	homeDir            string
-	configFile         string
+	configFiles        stringSlice
 	externalUI         string
 	externalController string
 	secret             string
 )
 
+// stringSlice collects the values of a flag given more than once
+type stringSlice []string
+
+func (s *stringSlice) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSlice) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
 func init() {
 	flag.StringVar(&homeDir, "d", "", "set configuration directory")
-	flag.StringVar(&configFile, "f", "", "specify configuration file")
+	flag.Var(&configFiles, "f", "specify configuration file, repeat it to merge more files over the first in order")
 	flag.StringVar(&externalUI, "ext-ui", "", "override external ui directory")
 	flag.StringVar(&externalController, "ext-ctl", "", "override external controller address")
 	flag.StringVar(&secret, "secret", "", "override secret for RESTful API")
 	flag.BoolVar(&geodataMode, "m", false, "set geodata mode")
 	flag.BoolVar(&version, "v", false, "show current version of clash")
 	flag.BoolVar(&testConfig, "t", false, "test configuration and exit")
+	flag.BoolVar(&allowCommand, "allow-command", false, "permit command providers to run local programs, also set by CLASH_ALLOW_COMMAND=1")
 	flag.Parse()
 
 	flagset = map[string]bool{}
@@ -69,21 +84,27 @@ func main() {
 		C.SetHomeDir(homeDir)
 	}
 
-	if configFile != "" {
-		if !filepath.IsAbs(configFile) {
-			currentDir, _ := os.Getwd()
-			configFile = filepath.Join(currentDir, configFile)
+	if len(configFiles) != 0 {
+		currentDir, _ := os.Getwd()
+		for i, file := range configFiles {
+			if !filepath.IsAbs(file) {
+				configFiles[i] = filepath.Join(currentDir, file)
+			}
 		}
-		C.SetConfig(configFile)
+		C.SetConfig(configFiles[0])
+		C.SetConfigOverrides(configFiles[1:])
 	} else {
-		configFile = filepath.Join(C.Path.HomeDir(), C.Path.Config())
-		C.SetConfig(configFile)
+		C.SetConfig(filepath.Join(C.Path.HomeDir(), C.Path.Config()))
 	}
 
 	if geodataMode {
 		C.GeodataMode = true
 	}
 
+	if allowCommand || os.Getenv("CLASH_ALLOW_COMMAND") == "1" {
+		resource.PermitCommand()
+	}
+
 	if err := config.Init(C.Path.HomeDir()); err != nil {
 		log.Fatalln("Initial configuration directory error: %s", err.Error())
 	}
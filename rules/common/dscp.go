@@ -0,0 +1,156 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Dreamacro/clash/common/utils"
+	C "github.com/Dreamacro/clash/constant"
+)
+
+const (
+	dscpParam = "dscp="
+	dscpKeep  = "keep"
+	maxDSCP   = 63
+)
+
+// DSCP matches the DSCP of the inbound packets, see C.Metadata.DSCP for the inbounds setting it
+type DSCP struct {
+	*Base
+	ranges  utils.IntRanges[uint8]
+	adapter string
+	payload string
+}
+
+func (d *DSCP) RuleType() C.RuleType {
+	return C.DSCP
+}
+
+func (d *DSCP) Match(metadata *C.Metadata) (bool, string) {
+	if metadata.DSCP == nil {
+		return false, d.adapter
+	}
+	return d.ranges.Check(*metadata.DSCP), d.adapter
+}
+
+func (d *DSCP) Adapter() string {
+	return d.adapter
+}
+
+func (d *DSCP) Payload() string {
+	return d.payload
+}
+
+func NewDSCP(payload, adapter string) (*DSCP, error) {
+	ranges, err := utils.NewIntRanges[int](payload)
+	if err != nil || len(ranges) == 0 {
+		return nil, fmt.Errorf("%w, invalid dscp %s", errPayload, payload)
+	}
+
+	dscpRanges := make(utils.IntRanges[uint8], 0, len(ranges))
+	for _, r := range ranges {
+		if r.Start() < 0 || r.End() > maxDSCP || r.Start() > r.End() {
+			return nil, fmt.Errorf("%w, dscp %s out of range 0-%d", errPayload, payload, maxDSCP)
+		}
+		dscpRanges = append(dscpRanges, *utils.NewRange(uint8(r.Start()), uint8(r.End())))
+	}
+
+	return &DSCP{
+		Base:    &Base{},
+		ranges:  dscpRanges,
+		adapter: adapter,
+		payload: payload,
+	}, nil
+}
+
+// dscpRule marks the egress of the connections matched by the wrapped rule
+type dscpRule struct {
+	C.Rule
+	dscp uint8
+	keep bool
+}
+
+// EgressDSCP implements C.DSCPMarker
+func (d *dscpRule) EgressDSCP(metadata *C.Metadata) (uint8, bool) {
+	if !d.keep {
+		return d.dscp, true
+	}
+	if metadata.DSCP == nil {
+		return 0, false
+	}
+	return *metadata.DSCP, true
+}
+
+// dscpGroupRule keeps C.RuleGroup of the wrapped rule for the API
+type dscpGroupRule struct {
+	*dscpRule
+	C.RuleGroup
+}
+
+// dscpGeoSiteRule keeps C.RuleGeoSite of a wrapped GEOSITE for the GEOSITE dns fallback filter
+type dscpGeoSiteRule struct {
+	*dscpGroupRule
+	C.RuleGeoSite
+}
+
+// dscpGeoIPRule keeps C.RuleGeoIP of a wrapped GEOIP
+type dscpGeoIPRule struct {
+	*dscpGroupRule
+	C.RuleGeoIP
+}
+
+// wrapDSCP returns d with the optional interfaces of the rule it wraps
+func wrapDSCP(d *dscpRule) C.Rule {
+	group, ok := d.Rule.(C.RuleGroup)
+	if !ok {
+		return d
+	}
+	g := &dscpGroupRule{dscpRule: d, RuleGroup: group}
+
+	switch r := d.Rule.(type) {
+	case C.RuleGeoSite:
+		return &dscpGeoSiteRule{dscpGroupRule: g, RuleGeoSite: r}
+	case C.RuleGeoIP:
+		return &dscpGeoIPRule{dscpGroupRule: g, RuleGeoIP: r}
+	}
+	return g
+}
+
+// HasDSCPParam reports whether params carry dscp=, it only takes effect on the top level rules
+// since the egress is marked by the matched one of them
+func HasDSCPParam(params []string) bool {
+	for _, p := range params {
+		if strings.HasPrefix(p, dscpParam) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithDSCPParam wraps rule when params carry dscp=N or dscp=keep, keep copies the inbound DSCP to the egress
+func WithDSCPParam(rule C.Rule, params []string) (C.Rule, error) {
+	for _, p := range params {
+		if !strings.HasPrefix(p, dscpParam) {
+			continue
+		}
+
+		value := strings.TrimPrefix(p, dscpParam)
+
+		if value == dscpKeep {
+			return wrapDSCP(&dscpRule{Rule: rule, keep: true}), nil
+		}
+
+		dscp, err := strconv.ParseUint(value, 10, 8)
+		if err != nil || dscp > maxDSCP {
+			return nil, fmt.Errorf("invalid dscp param %s, expect 0-%d or %s", value, maxDSCP, dscpKeep)
+		}
+		return wrapDSCP(&dscpRule{Rule: rule, dscp: uint8(dscp)}), nil
+	}
+	return rule, nil
+}
+
+var _ C.Rule = (*DSCP)(nil)
+var _ C.DSCPMarker = (*dscpRule)(nil)
+var _ C.RuleGeoSite = (*dscpGeoSiteRule)(nil)
+var _ C.RuleGeoIP = (*dscpGeoIPRule)(nil)
@@ -0,0 +1,167 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/Dreamacro/clash/component/geodata/router"
+	C "github.com/Dreamacro/clash/constant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func dscpPtr(dscp uint8) *uint8 {
+	return &dscp
+}
+
+func TestNewDSCP(t *testing.T) {
+	tests := []struct {
+		payload string
+		wantErr bool
+		match   []uint8
+		miss    []uint8
+	}{
+		{payload: "46", match: []uint8{46}, miss: []uint8{0, 45, 47}},
+		{payload: "0-7", match: []uint8{0, 3, 7}, miss: []uint8{8}},
+		{payload: "10/46-48", match: []uint8{10, 46, 48}, miss: []uint8{11, 45, 49}},
+		{payload: "63", match: []uint8{63}},
+		{payload: "64", wantErr: true},
+		{payload: "60-70", wantErr: true},
+		{payload: "abc", wantErr: true},
+		{payload: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.payload, func(t *testing.T) {
+			rule, err := NewDSCP(tt.payload, "DIRECT")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			for _, dscp := range tt.match {
+				matched, adapter := rule.Match(&C.Metadata{DSCP: dscpPtr(dscp)})
+				assert.True(t, matched, "dscp %d", dscp)
+				assert.Equal(t, "DIRECT", adapter)
+			}
+			for _, dscp := range tt.miss {
+				matched, _ := rule.Match(&C.Metadata{DSCP: dscpPtr(dscp)})
+				assert.False(t, matched, "dscp %d", dscp)
+			}
+			matched, _ := rule.Match(&C.Metadata{})
+			assert.False(t, matched, "no dscp")
+		})
+	}
+}
+
+func TestWithDSCPParam(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   []string
+		wantErr  bool
+		wrapped  bool
+		metadata *C.Metadata
+		dscp     uint8
+		marked   bool
+	}{
+		{name: "no param", params: []string{"no-resolve"}},
+		{name: "value", params: []string{"no-resolve", "dscp=46"}, wrapped: true, metadata: &C.Metadata{}, dscp: 46, marked: true},
+		{name: "zero", params: []string{"dscp=0"}, wrapped: true, metadata: &C.Metadata{DSCP: dscpPtr(10)}, dscp: 0, marked: true},
+		{name: "keep", params: []string{"dscp=keep"}, wrapped: true, metadata: &C.Metadata{DSCP: dscpPtr(10)}, dscp: 10, marked: true},
+		{name: "keep without inbound dscp", params: []string{"dscp=keep"}, wrapped: true, metadata: &C.Metadata{}, marked: false},
+		{name: "out of range", params: []string{"dscp=64"}, wantErr: true},
+		{name: "not a number", params: []string{"dscp=ef"}, wantErr: true},
+		{name: "empty", params: []string{"dscp="}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := NewDomain("example.com", "DIRECT")
+			rule, err := WithDSCPParam(inner, tt.params)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			marker, ok := rule.(C.DSCPMarker)
+			assert.Equal(t, tt.wrapped, ok)
+			if !ok {
+				assert.Equal(t, C.Rule(inner), rule)
+				return
+			}
+			dscp, marked := marker.EgressDSCP(tt.metadata)
+			assert.Equal(t, tt.marked, marked)
+			assert.Equal(t, tt.dscp, dscp)
+
+			// the wrapper still matches like the rule it wraps
+			assert.Equal(t, inner.Payload(), rule.Payload())
+			matched, adapter := rule.Match(&C.Metadata{Host: "example.com", AddrType: C.AtypDomainName})
+			assert.True(t, matched)
+			assert.Equal(t, "DIRECT", adapter)
+		})
+	}
+}
+
+func TestHasDSCPParam(t *testing.T) {
+	assert.True(t, HasDSCPParam([]string{"no-resolve", "dscp=1"}))
+	assert.True(t, HasDSCPParam([]string{"dscp=keep"}))
+	assert.False(t, HasDSCPParam([]string{"no-resolve"}))
+	assert.False(t, HasDSCPParam(nil))
+}
+
+type testGroupRule struct {
+	*Domain
+}
+
+func (r *testGroupRule) GetRecodeSize() int { return 1 }
+
+type testGeoSiteRule struct {
+	*testGroupRule
+}
+
+func (r *testGeoSiteRule) GetDomainMatcher() *router.DomainMatcher { return nil }
+
+type testGeoIPRule struct {
+	*testGroupRule
+}
+
+func (r *testGeoIPRule) GetIPMatcher() *router.GeoIPMatcher { return nil }
+
+func TestWithDSCPParam_KeepsInterfaces(t *testing.T) {
+	group := &testGroupRule{NewDomain("example.com", "DIRECT")}
+
+	tests := []struct {
+		name    string
+		rule    C.Rule
+		group   bool
+		geoSite bool
+		geoIP   bool
+	}{
+		{name: "plain", rule: group.Domain},
+		{name: "group", rule: group, group: true},
+		{name: "geosite", rule: &testGeoSiteRule{group}, group: true, geoSite: true},
+		{name: "geoip", rule: &testGeoIPRule{group}, group: true, geoIP: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := WithDSCPParam(tt.rule, []string{"dscp=46"})
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			_, ok := rule.(C.DSCPMarker)
+			assert.True(t, ok)
+			_, ok = rule.(C.RuleGroup)
+			assert.Equal(t, tt.group, ok)
+			_, ok = rule.(C.RuleGeoSite)
+			assert.Equal(t, tt.geoSite, ok)
+			_, ok = rule.(C.RuleGeoIP)
+			assert.Equal(t, tt.geoIP, ok)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+
+	C "github.com/Dreamacro/clash/constant"
+)
+
+// ConnectionCounter counts the active connections to the destination of metadata
+type ConnectionCounter interface {
+	DestinationConnections(metadata *C.Metadata) int64
+}
+
+var connectionCounter ConnectionCounter
+
+// SetConnectionCounter sets what DstConnections counts with, the tunnel sets its statistic manager
+func SetConnectionCounter(counter ConnectionCounter) {
+	connectionCounter = counter
+}
+
+// DstConnections matches once the destination host already has limit active connections
+type DstConnections struct {
+	*Base
+	limit   int64
+	adapter string
+	payload string
+}
+
+func (d *DstConnections) RuleType() C.RuleType {
+	return C.DstConnections
+}
+
+func (d *DstConnections) Match(metadata *C.Metadata) (bool, string) {
+	if connectionCounter == nil {
+		return false, d.adapter
+	}
+	return connectionCounter.DestinationConnections(metadata) >= d.limit, d.adapter
+}
+
+func (d *DstConnections) Adapter() string {
+	return d.adapter
+}
+
+func (d *DstConnections) Payload() string {
+	return d.payload
+}
+
+func NewDstConnections(limit string, adapter string) (*DstConnections, error) {
+	l, err := strconv.ParseInt(limit, 10, 64)
+	if err != nil || l <= 0 {
+		return nil, fmt.Errorf("%w, %s is not a positive number", errPayload, limit)
+	}
+
+	return &DstConnections{
+		Base:    &Base{},
+		limit:   l,
+		adapter: adapter,
+		payload: limit,
+	}, nil
+}
+
+var _ C.Rule = (*DstConnections)(nil)
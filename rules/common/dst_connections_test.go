@@ -0,0 +1,62 @@
+package common
+
+import (
+	"testing"
+
+	C "github.com/Dreamacro/clash/constant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testConnectionCounter map[string]int64
+
+func (c testConnectionCounter) DestinationConnections(metadata *C.Metadata) int64 {
+	return c[metadata.Host]
+}
+
+func TestNewDstConnections(t *testing.T) {
+	for _, limit := range []string{"0", "-1", "abc", ""} {
+		_, err := NewDstConnections(limit, "DIRECT")
+		assert.Error(t, err, limit)
+	}
+}
+
+func TestDstConnections_Match(t *testing.T) {
+	defer SetConnectionCounter(connectionCounter)
+	SetConnectionCounter(testConnectionCounter{"busy.com": 5, "idle.com": 1})
+
+	tests := []struct {
+		limit string
+		host  string
+		want  bool
+	}{
+		{limit: "5", host: "busy.com", want: true},
+		{limit: "6", host: "busy.com", want: false},
+		{limit: "1", host: "idle.com", want: true},
+		{limit: "2", host: "idle.com", want: false},
+		{limit: "1", host: "new.com", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.limit+" "+tt.host, func(t *testing.T) {
+			rule, err := NewDstConnections(tt.limit, "REJECT")
+			if !assert.NoError(t, err) {
+				return
+			}
+			matched, adapter := rule.Match(&C.Metadata{Host: tt.host})
+			assert.Equal(t, tt.want, matched)
+			assert.Equal(t, "REJECT", adapter)
+		})
+	}
+}
+
+func TestDstConnections_NoCounter(t *testing.T) {
+	defer SetConnectionCounter(connectionCounter)
+	SetConnectionCounter(nil)
+
+	rule, err := NewDstConnections("1", "REJECT")
+	if assert.NoError(t, err) {
+		matched, _ := rule.Match(&C.Metadata{Host: "a.com"})
+		assert.False(t, matched)
+	}
+}
@@ -0,0 +1,89 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	C "github.com/Dreamacro/clash/constant"
+)
+
+// HTTPMethod and HTTPPath only match plaintext HTTP requests seen by the sniffer
+
+type HTTPMethod struct {
+	*Base
+	methods []string
+	adapter string
+	payload string
+}
+
+func (h *HTTPMethod) RuleType() C.RuleType {
+	return C.HTTPMethod
+}
+
+func (h *HTTPMethod) Match(metadata *C.Metadata) (bool, string) {
+	for _, method := range h.methods {
+		if strings.EqualFold(metadata.HTTPMethod, method) {
+			return true, h.adapter
+		}
+	}
+	return false, ""
+}
+
+func (h *HTTPMethod) Adapter() string {
+	return h.adapter
+}
+
+func (h *HTTPMethod) Payload() string {
+	return h.payload
+}
+
+func NewHTTPMethod(methods string, adapter string) (*HTTPMethod, error) {
+	if methods == "" {
+		return nil, fmt.Errorf("http method could not be empty")
+	}
+
+	return &HTTPMethod{
+		Base:    &Base{},
+		methods: strings.Split(methods, "/"),
+		adapter: adapter,
+		payload: strings.ToUpper(methods),
+	}, nil
+}
+
+type HTTPPath struct {
+	*Base
+	regexp  *regexp.Regexp
+	adapter string
+	payload string
+}
+
+func (h *HTTPPath) RuleType() C.RuleType {
+	return C.HTTPPath
+}
+
+func (h *HTTPPath) Match(metadata *C.Metadata) (bool, string) {
+	return metadata.HTTPPath != "" && h.regexp.MatchString(metadata.HTTPPath), h.adapter
+}
+
+func (h *HTTPPath) Adapter() string {
+	return h.adapter
+}
+
+func (h *HTTPPath) Payload() string {
+	return h.payload
+}
+
+func NewHTTPPath(path string, adapter string) (*HTTPPath, error) {
+	r, err := regexp.Compile(path)
+	if err != nil {
+		return nil, fmt.Errorf("http path %s error: %w", path, err)
+	}
+
+	return &HTTPPath{
+		Base:    &Base{},
+		regexp:  r,
+		adapter: adapter,
+		payload: path,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	C "github.com/Dreamacro/clash/constant"
+)
+
+// TLSFingerprint matches the JA3 or JA4 of a ClientHello seen by the sniffer
+type TLSFingerprint struct {
+	*Base
+	ruleType     C.RuleType
+	fingerprints []string
+	adapter      string
+	payload      string
+}
+
+func (t *TLSFingerprint) RuleType() C.RuleType {
+	return t.ruleType
+}
+
+func (t *TLSFingerprint) Match(metadata *C.Metadata) (bool, string) {
+	fingerprint := metadata.JA3
+	if t.ruleType == C.JA4 {
+		fingerprint = metadata.JA4
+	}
+	if fingerprint == "" {
+		return false, ""
+	}
+
+	for _, f := range t.fingerprints {
+		if f == fingerprint {
+			return true, t.adapter
+		}
+	}
+	return false, ""
+}
+
+func (t *TLSFingerprint) Adapter() string {
+	return t.adapter
+}
+
+func (t *TLSFingerprint) Payload() string {
+	return t.payload
+}
+
+func NewTLSFingerprint(fingerprints string, adapter string, ruleType C.RuleType) (*TLSFingerprint, error) {
+	if fingerprints == "" {
+		return nil, fmt.Errorf("%s could not be empty", ruleType)
+	}
+
+	payload := strings.ToLower(fingerprints)
+	return &TLSFingerprint{
+		Base:         &Base{},
+		ruleType:     ruleType,
+		fingerprints: strings.Split(payload, "/"),
+		adapter:      adapter,
+		payload:      payload,
+	}, nil
+}
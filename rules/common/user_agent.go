@@ -0,0 +1,46 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+
+	C "github.com/Dreamacro/clash/constant"
+)
+
+// UserAgent matches the User-Agent of the clients of the HTTP inbound
+type UserAgent struct {
+	*Base
+	regexp  *regexp.Regexp
+	adapter string
+	payload string
+}
+
+func (u *UserAgent) RuleType() C.RuleType {
+	return C.UserAgent
+}
+
+func (u *UserAgent) Match(metadata *C.Metadata) (bool, string) {
+	return metadata.UserAgent != "" && u.regexp.MatchString(metadata.UserAgent), u.adapter
+}
+
+func (u *UserAgent) Adapter() string {
+	return u.adapter
+}
+
+func (u *UserAgent) Payload() string {
+	return u.payload
+}
+
+func NewUserAgent(ua string, adapter string) (*UserAgent, error) {
+	r, err := regexp.Compile(ua)
+	if err != nil {
+		return nil, fmt.Errorf("user agent %s error: %w", ua, err)
+	}
+
+	return &UserAgent{
+		Base:    &Base{},
+		regexp:  r,
+		adapter: adapter,
+		payload: ua,
+	}, nil
+}
@@ -33,7 +33,6 @@ func NewAND(payload string, adapter string,
 		payloads = append(payloads, fmt.Sprintf("(%s,%s)", rule.RuleType().String(), rule.Payload()))
 		if rule.ShouldResolveIP() {
 			and.needIP = true
-			break
 		}
 	}
 
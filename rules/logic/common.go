@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/Dreamacro/clash/common/collections"
 	C "github.com/Dreamacro/clash/constant"
+	"github.com/Dreamacro/clash/rules/common"
 	"regexp"
 	"strings"
 	_ "unsafe"
@@ -50,12 +51,16 @@ func payloadToRule(subPayload string, parseRule func(tp, payload, target string,
 		return nil, fmt.Errorf("[%s] format is error", subPayload)
 	}
 
-	tp := splitStr[0]
-	payload := splitStr[1]
+	// sub rules are written like top level rules, case and spaces around the fields don't matter
+	tp := strings.ToUpper(strings.TrimSpace(splitStr[0]))
+	payload := strings.TrimSpace(splitStr[1])
 	if tp == "NOT" || tp == "OR" || tp == "AND" {
 		return parseRule(tp, payload, "", nil)
 	}
 	param := strings.Split(payload, ",")
+	for i := range param {
+		param[i] = strings.TrimSpace(param[i])
+	}
 	return parseRule(tp, param[0], "", param[1:])
 }
 
@@ -65,6 +70,9 @@ func parseLogicSubRule(parseRule func(tp, payload, target string, params []strin
 		case "MATCH", "SUB-RULE":
 			return nil, fmt.Errorf("unsupported rule type [%s] on logic rule", tp)
 		default:
+			if common.HasDSCPParam(params) {
+				return nil, fmt.Errorf("dscp param of [%s] takes no effect inside a logic rule", tp)
+			}
 			return parseRule(tp, payload, target, params, nil)
 		}
 	}
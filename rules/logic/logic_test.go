@@ -111,3 +111,25 @@ func TestOR(t *testing.T) {
 	assert.Equal(t, true, m)
 	assert.Equal(t, false, or.ShouldResolveIP())
 }
+
+func TestPortRange(t *testing.T) {
+	and, err := NewAND("((dst-port, 1000-2000 ),(NETWORK,TCP))", "DIRECT", ParseRule)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "((DstPort,1000-2000) && (Network,tcp))", and.Payload())
+	m, _ := and.Match(&C.Metadata{NetWork: C.TCP, DstPort: "1500"})
+	assert.Equal(t, true, m)
+	m, _ = and.Match(&C.Metadata{NetWork: C.TCP, DstPort: "2001"})
+	assert.Equal(t, false, m)
+
+	not, err := NewNOT("(( DST-PORT,1000-2000/3000))", "REJECT", ParseRule)
+	assert.Equal(t, nil, err)
+	m, _ = not.Match(&C.Metadata{DstPort: "3000"})
+	assert.Equal(t, false, m)
+	m, _ = not.Match(&C.Metadata{DstPort: "2500"})
+	assert.Equal(t, true, m)
+
+	or, err := NewOR("((DOMAIN,baidu.com),(OR,((DST-PORT,1-10),(SRC-PORT,1000-2000))))", "DIRECT", ParseRule)
+	assert.Equal(t, nil, err)
+	m, _ = or.Match(&C.Metadata{SrcPort: "1999", DstPort: "443"})
+	assert.Equal(t, true, m)
+}
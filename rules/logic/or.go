@@ -58,7 +58,6 @@ func NewOR(payload string, adapter string, parse func(tp, payload, target string
 		payloads = append(payloads, fmt.Sprintf("(%s,%s)", rule.RuleType(), rule.Payload()))
 		if rule.ShouldResolveIP() {
 			or.needIP = true
-			break
 		}
 	}
 
@@ -47,6 +47,7 @@ func (r *SubRule) Match(metadata *C.Metadata) (bool, string) {
 
 func match(metadata *C.Metadata, name string, subRules *map[string][]C.Rule) (bool, string) {
 	for _, rule := range (*subRules)[name] {
+		metadata.RuleProvider = ""
 		if m, a := rule.Match(metadata); m {
 			if rule.RuleType() == C.SubRules {
 				match(metadata, rule.Adapter(), subRules)
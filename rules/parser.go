@@ -45,6 +45,22 @@ func ParseRule(tp, payload, target string, params []string, subRules *map[string
 		parsed, parseErr = RC.NewUid(payload, target)
 	case "IN-TYPE":
 		parsed, parseErr = RC.NewInType(payload, target)
+	case "IN-USER":
+		parsed, parseErr = RC.NewInUser(payload, target)
+	case "HTTP-METHOD":
+		parsed, parseErr = RC.NewHTTPMethod(payload, target)
+	case "HTTP-PATH":
+		parsed, parseErr = RC.NewHTTPPath(payload, target)
+	case "JA3":
+		parsed, parseErr = RC.NewTLSFingerprint(payload, target, C.JA3)
+	case "JA4":
+		parsed, parseErr = RC.NewTLSFingerprint(payload, target, C.JA4)
+	case "USER-AGENT":
+		parsed, parseErr = RC.NewUserAgent(payload, target)
+	case "DSCP":
+		parsed, parseErr = RC.NewDSCP(payload, target)
+	case "DST-CONNECTIONS":
+		parsed, parseErr = RC.NewDstConnections(payload, target)
 	case "SUB-RULE":
 		parsed, parseErr = logic.NewSubRule(payload, target, subRules, ParseRule)
 	case "AND":
@@ -67,5 +83,5 @@ func ParseRule(tp, payload, target string, params []string, subRules *map[string
 		return nil, parseErr
 	}
 
-	return
+	return RC.WithDSCPParam(parsed, params)
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	C "github.com/Dreamacro/clash/constant"
 	"github.com/Dreamacro/clash/log"
+	RC "github.com/Dreamacro/clash/rules/common"
 	"strings"
 )
 
@@ -72,6 +73,9 @@ func NewClassicalStrategy(parse func(tp, payload, target string, params []string
 		case "MATCH", "SUB-RULE":
 			return nil, fmt.Errorf("unsupported rule type on rule-set")
 		default:
+			if RC.HasDSCPParam(params) {
+				return nil, fmt.Errorf("dscp param takes no effect inside a rule-set")
+			}
 			return parse(tp, payload, target, params, nil)
 		}
 	}}
@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+
+	P "github.com/Dreamacro/clash/constant/provider"
+)
+
+func newRulesParser(behavior P.RuleType, format P.RuleFormat) func(buf []byte) (any, error) {
+	switch format {
+	case P.TextRule:
+		return func(buf []byte) (any, error) {
+			return normalizeDomainSet(behavior, textRulesParse(buf)), nil
+		}
+	case P.PlistRule:
+		return func(buf []byte) (any, error) {
+			rules, err := plistRulesParse(buf)
+			if err != nil {
+				return nil, err
+			}
+			return normalizeDomainSet(behavior, rules), nil
+		}
+	default:
+		return rulesParse
+	}
+}
+
+// textRulesParse reads one rule per line, blank lines and lines starting with # or // are skipped
+func textRulesParse(buf []byte) []string {
+	rules := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		rules = append(rules, line)
+	}
+	return rules
+}
+
+// plistRulesParse collects every <string> of a property list, wherever it is nested
+func plistRulesParse(buf []byte) ([]string, error) {
+	rules := []string{}
+	decoder := xml.NewDecoder(bytes.NewReader(buf))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "string" {
+			continue
+		}
+
+		var value string
+		if err := decoder.DecodeElement(&value, &start); err != nil {
+			return nil, err
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			rules = append(rules, value)
+		}
+	}
+	return rules, nil
+}
+
+// normalizeDomainSet turns the domain set syntax ".example.com", the domain and all its subdomains, into "+.example.com"
+func normalizeDomainSet(behavior P.RuleType, rules []string) []string {
+	if behavior != P.Domain {
+		return rules
+	}
+
+	for i, rule := range rules {
+		if strings.HasPrefix(rule, ".") {
+			rules[i] = "+" + rule
+		}
+	}
+	return rules
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"testing"
+
+	P "github.com/Dreamacro/clash/constant/provider"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextRulesParse(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  string
+		want []string
+	}{
+		{name: "empty", buf: "", want: []string{}},
+		{name: "one per line", buf: "example.com\n+.example.org\n", want: []string{"example.com", "+.example.org"}},
+		{name: "comments and blanks", buf: "# comment\n\n// another\n  example.com  \n", want: []string{"example.com"}},
+		{name: "crlf", buf: "1.1.1.1/32\r\n2.2.2.0/24\r\n", want: []string{"1.1.1.1/32", "2.2.2.0/24"}},
+		{name: "classical", buf: "DOMAIN-SUFFIX,example.com\nIP-CIDR,1.1.1.1/32,no-resolve", want: []string{"DOMAIN-SUFFIX,example.com", "IP-CIDR,1.1.1.1/32,no-resolve"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, textRulesParse([]byte(tt.buf)))
+		})
+	}
+}
+
+func TestPlistRulesParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		buf     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "array",
+			buf: `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0"><array><string>example.com</string><string> .example.org </string></array></plist>`,
+			want: []string{"example.com", ".example.org"},
+		},
+		{
+			name: "nested",
+			buf: `<plist version="1.0"><dict><key>rules</key><array><string>example.com</string>
+<array><string>example.net</string></array></array><string></string></dict></plist>`,
+			want: []string{"example.com", "example.net"},
+		},
+		{name: "empty", buf: `<plist version="1.0"><array/></plist>`, want: []string{}},
+		{name: "malformed", buf: `<plist><array><string>example.com</array></plist>`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := plistRulesParse([]byte(tt.buf))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, rules)
+		})
+	}
+}
+
+func TestNormalizeDomainSet(t *testing.T) {
+	tests := []struct {
+		name     string
+		behavior P.RuleType
+		rules    []string
+		want     []string
+	}{
+		{name: "domain", behavior: P.Domain, rules: []string{".example.com", "example.org", "+.example.net"}, want: []string{"+.example.com", "example.org", "+.example.net"}},
+		{name: "ipcidr untouched", behavior: P.IPCIDR, rules: []string{".example.com"}, want: []string{".example.com"}},
+		{name: "classical untouched", behavior: P.Classical, rules: []string{".example.com"}, want: []string{".example.com"}},
+		{name: "empty", behavior: P.Domain, rules: []string{}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, normalizeDomainSet(tt.behavior, tt.rules))
+		})
+	}
+}
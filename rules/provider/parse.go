@@ -10,11 +10,13 @@ import (
 )
 
 type ruleProviderSchema struct {
-	Type     string `provider:"type"`
-	Behavior string `provider:"behavior"`
-	Path     string `provider:"path"`
-	URL      string `provider:"url,omitempty"`
-	Interval int    `provider:"interval,omitempty"`
+	Type     string   `provider:"type"`
+	Behavior string   `provider:"behavior"`
+	Format   string   `provider:"format,omitempty"`
+	Path     string   `provider:"path"`
+	URL      string   `provider:"url,omitempty"`
+	Command  []string `provider:"command,omitempty"`
+	Interval int      `provider:"interval,omitempty"`
 }
 
 func ParseRuleProvider(name string, mapping map[string]interface{}, parse func(tp, payload, target string, params []string, subRules *map[string][]C.Rule) (parsed C.Rule, parseErr error)) (P.RuleProvider, error) {
@@ -36,6 +38,19 @@ func ParseRuleProvider(name string, mapping map[string]interface{}, parse func(t
 		return nil, fmt.Errorf("unsupported behavior type: %s", schema.Behavior)
 	}
 
+	var format P.RuleFormat
+
+	switch schema.Format {
+	case "", "yaml":
+		format = P.YamlRule
+	case "text":
+		format = P.TextRule
+	case "plist":
+		format = P.PlistRule
+	default:
+		return nil, fmt.Errorf("unsupported format type: %s", schema.Format)
+	}
+
 	path := C.Path.Resolve(schema.Path)
 	var vehicle P.Vehicle
 	switch schema.Type {
@@ -43,9 +58,15 @@ func ParseRuleProvider(name string, mapping map[string]interface{}, parse func(t
 		vehicle = resource.NewFileVehicle(path)
 	case "http":
 		vehicle = resource.NewHTTPVehicle(schema.URL, path)
+	case "command":
+		v, err := resource.NewCommandVehicle(schema.Command, path)
+		if err != nil {
+			return nil, err
+		}
+		vehicle = v
 	default:
 		return nil, fmt.Errorf("unsupported vehicle type: %s", schema.Type)
 	}
 
-	return NewRuleSetProvider(name, behavior, time.Duration(uint(schema.Interval))*time.Second, vehicle, parse), nil
+	return NewRuleSetProvider(name, behavior, format, time.Duration(uint(schema.Interval))*time.Second, vehicle, parse), nil
 }
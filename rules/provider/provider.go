@@ -17,6 +17,7 @@ var (
 type ruleSetProvider struct {
 	*resource.Fetcher[any]
 	behavior P.RuleType
+	format   P.RuleFormat
 	strategy ruleStrategy
 }
 
@@ -94,18 +95,22 @@ func (rp *ruleSetProvider) MarshalJSON() ([]byte, error) {
 	return json.Marshal(
 		map[string]interface{}{
 			"behavior":    rp.behavior.String(),
+			"format":      rp.format.String(),
 			"name":        rp.Name(),
 			"ruleCount":   rp.strategy.Count(),
 			"type":        rp.Type().String(),
 			"updatedAt":   rp.UpdatedAt,
+			"interval":    int64(rp.Interval() / time.Second),
+			"lastError":   rp.LastError(),
 			"vehicleType": rp.VehicleType().String(),
 		})
 }
 
-func NewRuleSetProvider(name string, behavior P.RuleType, interval time.Duration, vehicle P.Vehicle,
+func NewRuleSetProvider(name string, behavior P.RuleType, format P.RuleFormat, interval time.Duration, vehicle P.Vehicle,
 	parse func(tp, payload, target string, params []string, subRules *map[string][]C.Rule) (parsed C.Rule, parseErr error)) P.RuleProvider {
 	rp := &ruleSetProvider{
 		behavior: behavior,
+		format:   format,
 	}
 
 	onUpdate := func(elm interface{}) {
@@ -113,7 +118,7 @@ func NewRuleSetProvider(name string, behavior P.RuleType, interval time.Duration
 		rp.strategy.OnUpdate(rulesRaw)
 	}
 
-	fetcher := resource.NewFetcher(name, interval, vehicle, rulesParse, onUpdate)
+	fetcher := resource.NewFetcher(name, interval, vehicle, newRulesParser(behavior, format), onUpdate)
 	rp.Fetcher = fetcher
 	rp.strategy = newStrategy(behavior, parse)
 
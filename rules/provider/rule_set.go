@@ -24,7 +24,11 @@ func (rs *RuleSet) RuleType() C.RuleType {
 }
 
 func (rs *RuleSet) Match(metadata *C.Metadata) (bool, string) {
-	return rs.getProviders().Match(metadata), rs.adapter
+	if rs.getProviders().Match(metadata) {
+		metadata.RuleProvider = rs.ruleProviderName
+		return true, rs.adapter
+	}
+	return false, rs.adapter
 }
 
 func (rs *RuleSet) Adapter() string {
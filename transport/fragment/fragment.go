@@ -0,0 +1,133 @@
+// Package fragment splits the first packet of a connection into small TCP
+// segments, so middleboxes reading the TLS ClientHello or the HTTP Host header
+// at once can't match it.
+package fragment
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// PacketsTLSHello only splits a first write holding a TLS handshake record
+	PacketsTLSHello = "tlshello"
+	// PacketsFirst splits the first write whatever it contains
+	PacketsFirst = "first"
+)
+
+type Config struct {
+	Packets string
+	// MinLength and MaxLength bound the size of a segment in bytes
+	MinLength, MaxLength int
+	// MinInterval and MaxInterval bound the pause between two segments
+	MinInterval, MaxInterval time.Duration
+}
+
+// ParseConfig parses the length and interval ranges like "10-30", the interval in ms
+func ParseConfig(packets, length, interval string) (*Config, error) {
+	switch packets {
+	case "":
+		packets = PacketsTLSHello
+	case PacketsTLSHello, PacketsFirst:
+	default:
+		return nil, fmt.Errorf("fragment packets error: %s", packets)
+	}
+
+	minLength, maxLength, err := parseRange(length, 10, 30)
+	if err != nil {
+		return nil, fmt.Errorf("fragment length error: %w", err)
+	}
+	if minLength <= 0 {
+		return nil, errors.New("fragment length should be positive")
+	}
+	minInterval, maxInterval, err := parseRange(interval, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("fragment interval error: %w", err)
+	}
+
+	return &Config{
+		Packets:     packets,
+		MinLength:   minLength,
+		MaxLength:   maxLength,
+		MinInterval: time.Duration(minInterval) * time.Millisecond,
+		MaxInterval: time.Duration(maxInterval) * time.Millisecond,
+	}, nil
+}
+
+func parseRange(s string, defMin, defMax int) (int, int, error) {
+	if s == "" {
+		return defMin, defMax, nil
+	}
+	minStr, maxStr, found := strings.Cut(s, "-")
+	if !found {
+		maxStr = minStr
+	}
+	min, err := strconv.Atoi(strings.TrimSpace(minStr))
+	if err != nil {
+		return 0, 0, err
+	}
+	max, err := strconv.Atoi(strings.TrimSpace(maxStr))
+	if err != nil {
+		return 0, 0, err
+	}
+	if min < 0 || max < min {
+		return 0, 0, fmt.Errorf("invalid range %s", s)
+	}
+	return min, max, nil
+}
+
+type Conn struct {
+	net.Conn
+	cfg   *Config
+	first bool
+}
+
+func (c *Conn) Write(b []byte) (int, error) {
+	if !c.first {
+		return c.Conn.Write(b)
+	}
+	c.first = false
+
+	if c.cfg.Packets == PacketsTLSHello && (len(b) == 0 || b[0] != 0x16) {
+		return c.Conn.Write(b)
+	}
+
+	written := 0
+	for written < len(b) {
+		if written > 0 {
+			time.Sleep(time.Duration(between(int64(c.cfg.MinInterval), int64(c.cfg.MaxInterval))))
+		}
+		end := written + int(between(int64(c.cfg.MinLength), int64(c.cfg.MaxLength)))
+		if end > len(b) {
+			end = len(b)
+		}
+		n, err := c.Conn.Write(b[written:end])
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// between returns a random number in [min, max]
+func between(min, max int64) int64 {
+	if max <= min {
+		return min
+	}
+	return min + rand.Int63n(max-min+1)
+}
+
+// NewConn fragments the first write to conn as cfg says
+func NewConn(conn net.Conn, cfg *Config) net.Conn {
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		// every segment must leave on its own
+		_ = tcp.SetNoDelay(true)
+	}
+	return &Conn{Conn: conn, cfg: cfg, first: true}
+}
@@ -0,0 +1,106 @@
+package fragment
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		packets  string
+		length   string
+		interval string
+		want     *Config
+		wantErr  bool
+	}{
+		{name: "defaults", want: &Config{Packets: PacketsTLSHello, MinLength: 10, MaxLength: 30}},
+		{
+			name: "ranges", packets: PacketsFirst, length: "5-20", interval: "10-50",
+			want: &Config{Packets: PacketsFirst, MinLength: 5, MaxLength: 20, MinInterval: 10 * time.Millisecond, MaxInterval: 50 * time.Millisecond},
+		},
+		{
+			name: "single values with spaces", packets: PacketsTLSHello, length: " 8 ", interval: "1 - 2",
+			want: &Config{Packets: PacketsTLSHello, MinLength: 8, MaxLength: 8, MinInterval: time.Millisecond, MaxInterval: 2 * time.Millisecond},
+		},
+		{name: "unknown packets", packets: "all", wantErr: true},
+		{name: "zero length", length: "0-10", wantErr: true},
+		{name: "reversed length", length: "30-10", wantErr: true},
+		{name: "negative interval", interval: "-1", wantErr: true},
+		{name: "bad interval", interval: "a-b", wantErr: true},
+		{name: "reversed interval", interval: "10-5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseConfig(tt.packets, tt.length, tt.interval)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, cfg)
+		})
+	}
+}
+
+// recordConn keeps every write apart
+type recordConn struct {
+	net.Conn
+	writes [][]byte
+}
+
+func (c *recordConn) Write(b []byte) (int, error) {
+	c.writes = append(c.writes, append([]byte(nil), b...))
+	return len(b), nil
+}
+
+func TestConn_Write(t *testing.T) {
+	hello := append([]byte{0x16, 0x03, 0x01}, bytes.Repeat([]byte{0xaa}, 97)...)
+	plain := bytes.Repeat([]byte{'G'}, 100)
+
+	tests := []struct {
+		name      string
+		cfg       Config
+		first     []byte
+		segmented bool
+	}{
+		{name: "tls hello split", cfg: Config{Packets: PacketsTLSHello, MinLength: 10, MaxLength: 30}, first: hello, segmented: true},
+		{name: "tls hello skips plain", cfg: Config{Packets: PacketsTLSHello, MinLength: 10, MaxLength: 30}, first: plain, segmented: false},
+		{name: "first split", cfg: Config{Packets: PacketsFirst, MinLength: 7, MaxLength: 7}, first: plain, segmented: true},
+		{name: "segment larger than write", cfg: Config{Packets: PacketsFirst, MinLength: 200, MaxLength: 300}, first: plain, segmented: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &recordConn{}
+			conn := NewConn(inner, &tt.cfg)
+
+			n, err := conn.Write(tt.first)
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.first), n)
+
+			assert.Equal(t, tt.first, bytes.Join(inner.writes, nil))
+			if tt.segmented {
+				assert.Greater(t, len(inner.writes), 1)
+				for i, w := range inner.writes {
+					assert.LessOrEqual(t, len(w), tt.cfg.MaxLength)
+					if i < len(inner.writes)-1 {
+						assert.GreaterOrEqual(t, len(w), tt.cfg.MinLength)
+					}
+				}
+			} else {
+				assert.Len(t, inner.writes, 1)
+			}
+
+			// only the first write is split
+			count := len(inner.writes)
+			_, _ = conn.Write(tt.first)
+			assert.Len(t, inner.writes, count+1)
+		})
+	}
+}
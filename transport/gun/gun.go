@@ -36,6 +36,9 @@ var defaultHeader = http.Header{
 
 type DialFn = func(network, addr string) (net.Conn, error)
 
+// idleTimeout is how long the shared connection may stay silent before a PING checks it
+const idleTimeout = 30 * time.Second
+
 type Conn struct {
 	response  *http.Response
 	request   *http.Request
@@ -192,12 +195,15 @@ func NewHTTP2Client(dialFn DialFn, tlsConfig *tls.Config) *TransportWrap {
 		return cn, nil
 	}
 
+	// the connection is shared by every stream of the proxy, health checks included,
+	// so ping it when idle and drop it once the server stops answering
 	wrap.Transport = &http2.Transport{
 		DialTLS:            dialFunc,
 		TLSClientConfig:    tlsConfig,
 		AllowHTTP:          false,
 		DisableCompression: true,
-		PingTimeout:        0,
+		ReadIdleTimeout:    idleTimeout,
+		PingTimeout:        C.DefaultTLSTimeout,
 	}
 
 	return &wrap
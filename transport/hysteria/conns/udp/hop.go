@@ -0,0 +1,101 @@
+package udp
+
+import (
+	"math/rand"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+)
+
+const DefaultHopInterval = 10 * time.Second
+
+// HopPacketConn sends to a random port of the server every hop interval,
+// while the QUIC connection only sees the original server address
+type HopPacketConn struct {
+	net.PacketConn
+	serverAddr  net.Addr
+	serverIP    netip.Addr
+	ports       []uint16
+	interval    time.Duration
+	maxInterval time.Duration
+
+	mutex   sync.Mutex
+	hopAddr *net.UDPAddr
+	hopTime time.Time
+	nextHop time.Duration
+}
+
+// NewHopPacketConn hops every interval, or after a random duration in [interval, maxInterval]
+// when maxInterval is larger, so the hops don't happen on a fixed beat
+func NewHopPacketConn(orig net.PacketConn, serverAddr net.Addr, ports []uint16, interval, maxInterval time.Duration) *HopPacketConn {
+	if interval <= 0 {
+		interval = DefaultHopInterval
+	}
+	if maxInterval < interval {
+		maxInterval = interval
+	}
+
+	var serverIP netip.Addr
+	if udpAddr, ok := serverAddr.(*net.UDPAddr); ok {
+		serverIP, _ = netip.AddrFromSlice(udpAddr.IP)
+	}
+
+	return &HopPacketConn{
+		PacketConn:  orig,
+		serverAddr:  serverAddr,
+		serverIP:    serverIP.Unmap(),
+		ports:       ports,
+		interval:    interval,
+		maxInterval: maxInterval,
+	}
+}
+
+func (c *HopPacketConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	n, addr, err := c.PacketConn.ReadFrom(p)
+	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+		if ip, ok := netip.AddrFromSlice(udpAddr.IP); ok && ip.Unmap() == c.serverIP {
+			addr = c.serverAddr
+		}
+	}
+	return n, addr, err
+}
+
+func (c *HopPacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	if c.isServerAddr(addr) {
+		addr = c.currentAddr()
+	}
+	return c.PacketConn.WriteTo(p, addr)
+}
+
+// isServerAddr compares addr with the server address without formatting them, WriteTo runs for every packet
+func (c *HopPacketConn) isServerAddr(addr net.Addr) bool {
+	if addr == c.serverAddr {
+		return true
+	}
+	udpAddr, ok := addr.(*net.UDPAddr)
+	serverAddr, isUDP := c.serverAddr.(*net.UDPAddr)
+	if !ok || !isUDP {
+		return addr.String() == c.serverAddr.String()
+	}
+	return udpAddr.Port == serverAddr.Port && udpAddr.IP.Equal(serverAddr.IP) && udpAddr.Zone == serverAddr.Zone
+}
+
+func (c *HopPacketConn) currentAddr() *net.UDPAddr {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.hopAddr == nil || time.Since(c.hopTime) >= c.nextHop {
+		c.hopAddr = &net.UDPAddr{
+			IP:   c.serverIP.AsSlice(),
+			Port: int(c.ports[rand.Intn(len(c.ports))]),
+		}
+		c.hopTime = time.Now()
+		c.nextHop = c.interval
+		if c.maxInterval > c.interval {
+			c.nextHop += time.Duration(rand.Int63n(int64(c.maxInterval - c.interval + 1)))
+		}
+	}
+
+	return c.hopAddr
+}
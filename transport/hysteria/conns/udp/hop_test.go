@@ -0,0 +1,18 @@
+package udp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHopPacketConn_IsServerAddr(t *testing.T) {
+	server := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 443}
+	c := NewHopPacketConn(nil, server, []uint16{443, 8443}, 0, 0)
+
+	assert.True(t, c.isServerAddr(server))
+	assert.True(t, c.isServerAddr(&net.UDPAddr{IP: net.IP{1, 2, 3, 4}, Port: 443}))
+	assert.False(t, c.isServerAddr(&net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 8443}))
+	assert.False(t, c.isServerAddr(&net.UDPAddr{IP: net.IPv4(1, 2, 3, 5), Port: 443}))
+}
@@ -17,6 +17,7 @@ import (
 	"net"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -45,6 +46,10 @@ type Client struct {
 	udpSessionMutex sync.RWMutex
 	udpSessionMap   map[uint32]chan *udpMessage
 	udpDefragger    defragger
+
+	// streams is the number of open streams, sessions the number of QUIC sessions ever established
+	streams  atomic.Int64
+	sessions atomic.Int64
 }
 
 func NewClient(serverAddr string, protocol string, auth []byte, tlsConfig *tls.Config, quicConfig *quic.Config,
@@ -92,9 +97,20 @@ func (c *Client) connectToServer(dialer transport.PacketDialer) error {
 	c.udpSessionMap = make(map[uint32]chan *udpMessage)
 	go c.handleMessage(qs)
 	c.quicSession = qs
+	c.sessions.Add(1)
 	return nil
 }
 
+// Streams returns the number of streams currently multiplexed over the QUIC sessions
+func (c *Client) Streams() int64 {
+	return c.streams.Load()
+}
+
+// Sessions returns the number of QUIC sessions established, it only grows on reconnect
+func (c *Client) Sessions() int64 {
+	return c.sessions.Load()
+}
+
 func (c *Client) handleControlStream(qs quic.Connection, stream quic.Stream) (bool, string, error) {
 	// Send protocol version
 	_, err := stream.Write([]byte{protocolVersion})
@@ -216,10 +232,14 @@ func (c *Client) DialTCP(addr string, dialer transport.PacketDialer) (net.Conn,
 		_ = stream.Close()
 		return nil, fmt.Errorf("connection rejected: %s", sr.Message)
 	}
+	c.streams.Add(1)
 	return &quicConn{
 		Orig:             stream,
 		PseudoLocalAddr:  session.LocalAddr(),
 		PseudoRemoteAddr: session.RemoteAddr(),
+		CloseFunc: func() {
+			c.streams.Add(-1)
+		},
 	}, nil
 }
 
@@ -257,6 +277,7 @@ func (c *Client) DialUDP(dialer transport.PacketDialer) (UDPConn, error) {
 	sessionMap := c.udpSessionMap
 	sessionMap[sr.UDPSessionID] = nCh
 	c.udpSessionMutex.Unlock()
+	c.streams.Add(1)
 
 	pktConn := &quicPktConn{
 		Session: session,
@@ -266,6 +287,7 @@ func (c *Client) DialUDP(dialer transport.PacketDialer) (UDPConn, error) {
 			if ch, ok := sessionMap[sr.UDPSessionID]; ok {
 				close(ch)
 				delete(sessionMap, sr.UDPSessionID)
+				c.streams.Add(-1)
 			}
 			c.udpSessionMutex.Unlock()
 		},
@@ -288,6 +310,8 @@ type quicConn struct {
 	Orig             quic.Stream
 	PseudoLocalAddr  net.Addr
 	PseudoRemoteAddr net.Addr
+	CloseFunc        func()
+	closeOnce        sync.Once
 }
 
 func (w *quicConn) Read(b []byte) (n int, err error) {
@@ -299,6 +323,7 @@ func (w *quicConn) Write(b []byte) (n int, err error) {
 }
 
 func (w *quicConn) Close() error {
+	w.closeOnce.Do(w.CloseFunc)
 	return w.Orig.Close()
 }
 
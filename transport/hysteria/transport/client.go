@@ -10,15 +10,33 @@ import (
 	obfsPkg "github.com/Dreamacro/clash/transport/hysteria/obfs"
 	"github.com/lucas-clemente/quic-go"
 	"net"
+	"time"
 )
 
 type ClientTransport struct {
 	Dialer *net.Dialer
+
+	// HopPorts enables port hopping among the server ports, only for udp and wechat-video.
+	// Each hop waits a random interval between HopInterval and HopIntervalMax
+	HopPorts       []uint16
+	HopInterval    time.Duration
+	HopIntervalMax time.Duration
+}
+
+func (ct *ClientTransport) listenPacket(server net.Addr, dialer PacketDialer) (net.PacketConn, error) {
+	conn, err := dialer.ListenPacket()
+	if err != nil {
+		return nil, err
+	}
+	if len(ct.HopPorts) > 0 {
+		return udp.NewHopPacketConn(conn, server, ct.HopPorts, ct.HopInterval, ct.HopIntervalMax), nil
+	}
+	return conn, nil
 }
 
-func (ct *ClientTransport) quicPacketConn(proto string, server string, obfs obfsPkg.Obfuscator, dialer PacketDialer) (net.PacketConn, error) {
+func (ct *ClientTransport) quicPacketConn(proto string, server net.Addr, obfs obfsPkg.Obfuscator, dialer PacketDialer) (net.PacketConn, error) {
 	if len(proto) == 0 || proto == "udp" {
-		conn, err := dialer.ListenPacket()
+		conn, err := ct.listenPacket(server, dialer)
 		if err != nil {
 			return nil, err
 		}
@@ -29,7 +47,7 @@ func (ct *ClientTransport) quicPacketConn(proto string, server string, obfs obfs
 			return conn, nil
 		}
 	} else if proto == "wechat-video" {
-		conn, err := dialer.ListenPacket()
+		conn, err := ct.listenPacket(server, dialer)
 		if err != nil {
 			return nil, err
 		}
@@ -39,7 +57,7 @@ func (ct *ClientTransport) quicPacketConn(proto string, server string, obfs obfs
 		return wechat.NewObfsWeChatUDPConn(conn, obfs), nil
 	} else if proto == "faketcp" {
 		var conn *faketcp.TCPConn
-		conn, err := faketcp.Dial("tcp", server)
+		conn, err := faketcp.Dial("tcp", server.String())
 		if err != nil {
 			return nil, err
 		}
@@ -66,7 +84,7 @@ func (ct *ClientTransport) QUICDial(proto string, server string, tlsConfig *tls.
 		return nil, err
 	}
 
-	pktConn, err := ct.quicPacketConn(proto, serverUDPAddr.String(), obfs, dialer)
+	pktConn, err := ct.quicPacketConn(proto, serverUDPAddr, obfs, dialer)
 	if err != nil {
 		return nil, err
 	}
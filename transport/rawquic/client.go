@@ -0,0 +1,248 @@
+// Package rawquic speaks a minimal relay protocol over QUIC streams.
+//
+// Every bidirectional stream starts with sha256(secret), a command byte and the
+// socks5 address of the target. A TCP stream then carries the raw payload, a UDP
+// stream carries packets framed as socks5 address, 2 bytes length and payload.
+package rawquic
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Dreamacro/clash/common/pool"
+	"github.com/Dreamacro/clash/transport/socks5"
+
+	"github.com/lucas-clemente/quic-go"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	CommandTCP byte = 1
+	CommandUDP byte = 3
+
+	DefaultALPN = "quic-relay"
+
+	maxLength = 8192
+
+	// dialTimeout bounds dialing a new QUIC connection, the handshake included
+	dialTimeout = 10 * time.Second
+)
+
+// DialFunc returns the packet conn the QUIC connection runs on and the server address
+type DialFunc func(ctx context.Context) (net.PacketConn, net.Addr, error)
+
+type Client struct {
+	tlsConfig  *tls.Config
+	quicConfig *quic.Config
+	token      [sha256.Size]byte
+
+	connMux sync.Mutex
+	conn    quic.Connection
+	pc      net.PacketConn
+	group   singleflight.Group
+}
+
+// current returns the shared QUIC connection, nil once it is gone
+func (c *Client) current() quic.Connection {
+	c.connMux.Lock()
+	defer c.connMux.Unlock()
+	if c.conn != nil && c.conn.Context().Err() == nil {
+		return c.conn
+	}
+	return nil
+}
+
+// session returns the shared QUIC connection, dialing a new one with dial once the old is gone.
+// The concurrent callers share one handshake, each of them only waits for it until its ctx is done
+func (c *Client) session(ctx context.Context, dial DialFunc) (quic.Connection, error) {
+	if conn := c.current(); conn != nil {
+		return conn, nil
+	}
+
+	ch := c.group.DoChan("session", func() (any, error) {
+		return c.dialSession(dial)
+	})
+	select {
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(quic.Connection), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// dialSession dials a new QUIC connection replacing the closed one, it outlives the ctx of
+// the caller starting it since the others wait on it as well
+func (c *Client) dialSession(dial DialFunc) (quic.Connection, error) {
+	// a handshake finished after the caller looked
+	if conn := c.current(); conn != nil {
+		return conn, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+
+	pc, addr, err := dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := quic.DialContext(ctx, pc, addr, c.tlsConfig.ServerName, c.tlsConfig, c.quicConfig)
+	if err != nil {
+		_ = pc.Close()
+		return nil, err
+	}
+
+	c.connMux.Lock()
+	if c.pc != nil {
+		_ = c.pc.Close()
+	}
+	c.conn, c.pc = conn, pc
+	c.connMux.Unlock()
+	return conn, nil
+}
+
+func (c *Client) openStream(ctx context.Context, dial DialFunc, command byte, socks5Addr []byte) (*Conn, error) {
+	conn, err := c.session(ctx, dial)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := pool.GetBuffer()
+	defer pool.PutBuffer(buf)
+
+	buf.Write(c.token[:])
+	buf.WriteByte(command)
+	buf.Write(socks5Addr)
+	if _, err := stream.Write(buf.Bytes()); err != nil {
+		stream.CancelRead(0)
+		_ = stream.Close()
+		return nil, err
+	}
+
+	return &Conn{Stream: stream, local: conn.LocalAddr(), remote: conn.RemoteAddr()}, nil
+}
+
+// DialTCP opens a stream relaying to the target, dial is only used when there is no QUIC connection to share
+func (c *Client) DialTCP(ctx context.Context, dial DialFunc, socks5Addr []byte) (net.Conn, error) {
+	return c.openStream(ctx, dial, CommandTCP, socks5Addr)
+}
+
+// DialUDP opens a stream carrying the udp packets of one association, dial is used as in DialTCP
+func (c *Client) DialUDP(ctx context.Context, dial DialFunc, socks5Addr []byte) (net.PacketConn, error) {
+	conn, err := c.openStream(ctx, dial, CommandUDP, socks5Addr)
+	if err != nil {
+		return nil, err
+	}
+	return &PacketConn{Conn: conn}, nil
+}
+
+// NewClient returns a client sharing one QUIC connection among its streams
+func NewClient(secret string, tlsConfig *tls.Config, quicConfig *quic.Config) *Client {
+	return &Client{
+		tlsConfig:  tlsConfig,
+		quicConfig: quicConfig,
+		token:      sha256.Sum256([]byte(secret)),
+	}
+}
+
+// Conn is a QUIC stream with the addresses of its connection
+type Conn struct {
+	quic.Stream
+	local  net.Addr
+	remote net.Addr
+}
+
+func (c *Conn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+// Close aborts the read side as well, quic.Stream.Close only closes the write side
+func (c *Conn) Close() error {
+	c.Stream.CancelRead(0)
+	return c.Stream.Close()
+}
+
+type PacketConn struct {
+	*Conn
+	remain int
+	mux    sync.Mutex
+}
+
+func (pc *PacketConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	if len(b) > maxLength {
+		return 0, errors.New("packet too large")
+	}
+
+	buf := pool.GetBuffer()
+	defer pool.PutBuffer(buf)
+
+	buf.Write(socks5.ParseAddr(addr.String()))
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(b)))
+	buf.Write(b)
+	if _, err := pc.Conn.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (pc *PacketConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	pc.mux.Lock()
+	defer pc.mux.Unlock()
+
+	// drop what is left of a packet larger than b
+	if pc.remain != 0 {
+		if _, err := io.CopyN(io.Discard, pc.Conn, int64(pc.remain)); err != nil {
+			return 0, nil, err
+		}
+		pc.remain = 0
+	}
+
+	addr, err := socks5.ReadAddr(pc.Conn, b)
+	if err != nil {
+		return 0, nil, errors.New("read addr error")
+	}
+	uAddr := addr.UDPAddr()
+	if uAddr == nil {
+		return 0, nil, errors.New("parse addr error")
+	}
+
+	var lengthBuf [2]byte
+	if _, err = io.ReadFull(pc.Conn, lengthBuf[:]); err != nil {
+		return 0, nil, errors.New("read length error")
+	}
+
+	total := int(binary.BigEndian.Uint16(lengthBuf[:]))
+	if total > maxLength {
+		return 0, nil, errors.New("packet invalid")
+	}
+
+	length := len(b)
+	if total < length {
+		length = total
+	}
+	if _, err = io.ReadFull(pc.Conn, b[:length]); err != nil {
+		return 0, nil, errors.New("read packet error")
+	}
+
+	pc.remain = total - length
+	return length, uAddr, nil
+}
@@ -0,0 +1,253 @@
+package rawquic
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dreamacro/clash/transport/socks5"
+
+	"github.com/lucas-clemente/quic-go"
+)
+
+func testServerTLSConfig(t *testing.T) *tls.Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		NextProtos:   []string{DefaultALPN},
+	}
+}
+
+// startServer accepts QUIC connections and checks the header of every stream against secret
+func startServer(t *testing.T, secret string) (net.Addr, chan quic.Connection) {
+	l, err := quic.ListenAddr("127.0.0.1:0", testServerTLSConfig(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	token := sha256.Sum256([]byte(secret))
+	conns := make(chan quic.Connection, 16)
+	go func() {
+		for {
+			conn, err := l.Accept(context.Background())
+			if err != nil {
+				return
+			}
+			conns <- conn
+			go func() {
+				for {
+					stream, err := conn.AcceptStream(context.Background())
+					if err != nil {
+						return
+					}
+					go func() {
+						defer stream.Close()
+						header := make([]byte, sha256.Size+1)
+						if _, err := io.ReadFull(stream, header); err != nil {
+							return
+						}
+						if !bytes.Equal(header[:sha256.Size], token[:]) || header[sha256.Size] != CommandTCP {
+							return
+						}
+						if _, err := socks5.ReadAddr(stream, make([]byte, socks5.MaxAddrLen)); err != nil {
+							return
+						}
+						_, _ = io.Copy(stream, stream)
+					}()
+				}
+			}()
+		}
+	}()
+	return l.Addr(), conns
+}
+
+func newTestClient(secret string) *Client {
+	tlsConfig := &tls.Config{
+		ServerName:         "localhost",
+		InsecureSkipVerify: true,
+		NextProtos:         []string{DefaultALPN},
+	}
+	return NewClient(secret, tlsConfig, &quic.Config{})
+}
+
+// countingDial dials the server at addr and counts the QUIC connections dialed
+func countingDial(addr net.Addr, dials *int32, mux *sync.Mutex) DialFunc {
+	return func(ctx context.Context) (net.PacketConn, net.Addr, error) {
+		mux.Lock()
+		*dials++
+		mux.Unlock()
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		return pc, addr, err
+	}
+}
+
+func TestClient_SharesSession(t *testing.T) {
+	addr, conns := startServer(t, "secret")
+
+	var (
+		dials int32
+		mux   sync.Mutex
+	)
+	client := newTestClient("secret")
+	dial := countingDial(addr, &dials, &mux)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			conn, err := client.DialTCP(ctx, dial, socks5.ParseAddr("1.2.3.4:80"))
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer conn.Close()
+
+			if _, err := conn.Write([]byte("ping")); err != nil {
+				errs <- err
+				return
+			}
+			buf := make([]byte, 4)
+			if _, err := io.ReadFull(conn, buf); err != nil {
+				errs <- err
+				return
+			}
+			if string(buf) != "ping" {
+				errs <- io.ErrUnexpectedEOF
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	if dials != 1 {
+		t.Errorf("dialed %d sessions, want 1", dials)
+	}
+	if len(conns) != 1 {
+		t.Errorf("server accepted %d connections, want 1", len(conns))
+	}
+}
+
+func TestClient_RedialsClosedSession(t *testing.T) {
+	addr, conns := startServer(t, "secret")
+
+	var (
+		dials int32
+		mux   sync.Mutex
+	)
+	client := newTestClient("secret")
+	dial := countingDial(addr, &dials, &mux)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := client.DialTCP(ctx, dial, socks5.ParseAddr("1.2.3.4:80"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.Close()
+
+	server := <-conns
+	_ = server.CloseWithError(0, "")
+	session, _ := client.session(ctx, dial)
+	if session != nil {
+		<-session.Context().Done()
+	}
+
+	conn, err = client.DialTCP(ctx, dial, socks5.ParseAddr("1.2.3.4:80"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.Close()
+
+	if dials != 2 {
+		t.Errorf("dialed %d sessions, want 2", dials)
+	}
+}
+
+func TestClient_CallerContextDoesNotAbortSharedDial(t *testing.T) {
+	addr, _ := startServer(t, "secret")
+
+	var (
+		dials int32
+		mux   sync.Mutex
+	)
+	release := make(chan struct{})
+	tlsConfig := &tls.Config{
+		ServerName:         "localhost",
+		InsecureSkipVerify: true,
+		NextProtos:         []string{DefaultALPN},
+	}
+	client := NewClient("secret", tlsConfig, &quic.Config{})
+	dial := func(ctx context.Context) (net.PacketConn, net.Addr, error) {
+		mux.Lock()
+		dials++
+		mux.Unlock()
+		<-release
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		return pc, addr, err
+	}
+
+	// the first caller gives up while the dial it started is still running
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.DialTCP(ctx, dial, socks5.ParseAddr("1.2.3.4:80"))
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel2()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+	conn, err := client.DialTCP(ctx2, dial, socks5.ParseAddr("1.2.3.4:80"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.Close()
+
+	if dials != 1 {
+		t.Errorf("dialed %d sessions, want 1", dials)
+	}
+}
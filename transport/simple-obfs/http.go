@@ -3,8 +3,8 @@ package obfs
 import (
 	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
-	"io"
 	"math/rand"
 	"net"
 	"net/http"
@@ -35,16 +35,21 @@ func (ho *HTTPObfs) Read(b []byte) (int, error) {
 	}
 
 	if ho.firstResponse {
+		// the response header may come in several reads, keep reading until it ends
 		buf := pool.Get(pool.RelayBufferSize)
-		n, err := ho.Conn.Read(buf)
-		if err != nil {
-			pool.Put(buf)
-			return 0, err
-		}
-		idx := bytes.Index(buf[:n], []byte("\r\n\r\n"))
-		if idx == -1 {
-			pool.Put(buf)
-			return 0, io.EOF
+		n, idx := 0, -1
+		for idx == -1 {
+			if n == len(buf) {
+				pool.Put(buf)
+				return 0, errors.New("http obfs response header too large")
+			}
+			nr, err := ho.Conn.Read(buf[n:])
+			if err != nil {
+				pool.Put(buf)
+				return 0, err
+			}
+			n += nr
+			idx = bytes.Index(buf[:n], []byte("\r\n\r\n"))
 		}
 		ho.firstResponse = false
 		length := n - (idx + 4)
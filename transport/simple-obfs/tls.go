@@ -3,6 +3,7 @@ package obfs
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 	"io"
 	"math/rand"
 	"net"
@@ -17,6 +18,9 @@ func init() {
 
 const (
 	chunkSize = 1 << 14 // 2 ** 14 == 16 * 1024
+
+	recordChangeCipherSpec = 0x14
+	recordHandshake        = 0x16
 )
 
 // TLSObfs is shadowsocks tls simple-obfs implementation
@@ -31,15 +35,15 @@ type TLSObfs struct {
 func (to *TLSObfs) read(b []byte, discardN int) (int, error) {
 	buf := pool.Get(discardN)
 	_, err := io.ReadFull(to.Conn, buf)
+	pool.Put(buf)
 	if err != nil {
 		return 0, err
 	}
-	pool.Put(buf)
 
 	sizeBuf := make([]byte, 2)
 	_, err = io.ReadFull(to.Conn, sizeBuf)
 	if err != nil {
-		return 0, nil
+		return 0, err
 	}
 
 	length := int(binary.BigEndian.Uint16(sizeBuf))
@@ -68,17 +72,39 @@ func (to *TLSObfs) Read(b []byte) (int, error) {
 	}
 
 	if to.firstResponse {
-		// type + ver + lensize + 91 = 96
-		// type + ver + lensize + 1 = 6
-		// type + ver = 3
 		to.firstResponse = false
-		return to.read(b, 105)
+		if err := to.skipServerHello(); err != nil {
+			return 0, err
+		}
 	}
 
 	// type + ver = 3
 	return to.read(b, 3)
 }
 
+// skipServerHello discards the records before the first one carrying data. They
+// are a ServerHello and a ChangeCipherSpec, simple-obfs always sends a 91 bytes
+// ServerHello but other servers like Surge's don't, so the lengths are read
+// from the record headers
+func (to *TLSObfs) skipServerHello() error {
+	header := make([]byte, 5)
+	for {
+		if _, err := io.ReadFull(to.Conn, header); err != nil {
+			return err
+		}
+		typ, length := header[0], int64(binary.BigEndian.Uint16(header[3:]))
+		if typ != recordHandshake && typ != recordChangeCipherSpec {
+			return fmt.Errorf("unexpected tls record type %d in server hello", typ)
+		}
+		if _, err := io.CopyN(io.Discard, to.Conn, length); err != nil {
+			return err
+		}
+		if typ == recordChangeCipherSpec {
+			return nil
+		}
+	}
+}
+
 func (to *TLSObfs) Write(b []byte) (int, error) {
 	length := len(b)
 	for i := 0; i < length; i += chunkSize {
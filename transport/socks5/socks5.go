@@ -105,7 +105,13 @@ type User struct {
 }
 
 // ServerHandshake fast-tracks SOCKS initialization to get target address to connect on server side.
-func ServerHandshake(rw net.Conn, authenticator auth.Authenticator) (addr Addr, command Command, err error) {
+// ServerHandshake also returns the authenticated username, empty without authenticator
+func ServerHandshake(rw net.Conn, authenticator auth.Authenticator) (addr Addr, command Command, user string, err error) {
+	return ServerHandshakeWithUDP(rw, authenticator, true)
+}
+
+// ServerHandshakeWithUDP is ServerHandshake answering UDP ASSOCIATE with "command not supported" unless udp is true
+func ServerHandshakeWithUDP(rw net.Conn, authenticator auth.Authenticator, udp bool) (addr Addr, command Command, user string, err error) {
 	// Read RFC 1928 for request and reply structure and sizes.
 	buf := make([]byte, MaxAddrLen)
 	// read VER, NMETHODS, METHODS
@@ -140,7 +146,7 @@ func ServerHandshake(rw net.Conn, authenticator auth.Authenticator) (addr Addr,
 		if _, err = io.ReadFull(rw, authBuf[:userLen]); err != nil {
 			return
 		}
-		user := string(authBuf[:userLen])
+		user = string(authBuf[:userLen])
 
 		// Get password
 		if _, err = rw.Read(header[:1]); err != nil {
@@ -186,7 +192,15 @@ func ServerHandshake(rw net.Conn, authenticator auth.Authenticator) (addr Addr,
 	}
 
 	switch command {
-	case CmdConnect, CmdUDPAssociate:
+	case CmdUDPAssociate:
+		if !udp {
+			// write VER REP RSV ATYP BND.ADDR BND.PORT
+			_, _ = rw.Write([]byte{5, byte(ErrCommandNotSupported), 0, AtypIPv4, 0, 0, 0, 0, 0, 0})
+			err = ErrCommandNotSupported
+			return
+		}
+		fallthrough
+	case CmdConnect:
 		// Acquire server listened address info
 		localAddr := ParseAddr(rw.LocalAddr().String())
 		if localAddr == nil {
@@ -54,6 +54,15 @@ type Option struct {
 	Fingerprint    string
 	Flow           string
 	FlowShow       bool
+	MinVersion     uint16
+	MaxVersion     uint16
+}
+
+func (o *Option) minVersion() uint16 {
+	if o.MinVersion != 0 {
+		return o.MinVersion
+	}
+	return tls.VersionTLS12
 }
 
 type WebsocketOption struct {
@@ -102,7 +111,8 @@ func (t *Trojan) StreamConn(conn net.Conn) (net.Conn, error) {
 	default:
 		tlsConfig := &tls.Config{
 			NextProtos:         alpn,
-			MinVersion:         tls.VersionTLS12,
+			MinVersion:         t.option.minVersion(),
+			MaxVersion:         t.option.MaxVersion,
 			InsecureSkipVerify: t.option.SkipCertVerify,
 			ServerName:         t.option.ServerName,
 		}
@@ -139,7 +149,8 @@ func (t *Trojan) StreamWebsocketConn(conn net.Conn, wsOptions *WebsocketOption)
 
 	tlsConfig := &tls.Config{
 		NextProtos:         alpn,
-		MinVersion:         tls.VersionTLS12,
+		MinVersion:         t.option.minVersion(),
+		MaxVersion:         t.option.MaxVersion,
 		InsecureSkipVerify: t.option.SkipCertVerify,
 		ServerName:         t.option.ServerName,
 	}
@@ -0,0 +1,68 @@
+package vmess
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	N "github.com/Dreamacro/clash/common/net"
+)
+
+type HTTPUpgradeConfig struct {
+	Host    string
+	Path    string
+	Headers http.Header
+}
+
+// StreamHTTPUpgradeConn sends a HTTP/1.1 Upgrade request and hands back the raw
+// stream after the 101 response, unlike websocket the payload isn't framed
+func StreamHTTPUpgradeConn(conn net.Conn, c *HTTPUpgradeConfig) (net.Conn, error) {
+	path := c.Path
+	if path == "" {
+		path = "/"
+	}
+	uri, err := url.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("httpupgrade path %s error: %w", c.Path, err)
+	}
+	uri.Scheme = "http"
+	uri.Host = c.Host
+
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        uri,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Host:       c.Host,
+	}
+	for key, values := range c.Headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+		req.Header.Del("Host")
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	bufConn := N.NewBufferedConn(conn)
+	resp, err := http.ReadResponse(bufConn.Reader(), req)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, fmt.Errorf("httpupgrade %s error: %s", c.Host, resp.Status)
+	}
+
+	return bufConn, nil
+}
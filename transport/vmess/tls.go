@@ -14,6 +14,8 @@ type TLSConfig struct {
 	SkipCertVerify bool
 	FingerPrint    string
 	NextProtos     []string
+	MinVersion     uint16
+	MaxVersion     uint16
 }
 
 func StreamTLSConn(conn net.Conn, cfg *TLSConfig) (net.Conn, error) {
@@ -21,6 +23,8 @@ func StreamTLSConn(conn net.Conn, cfg *TLSConfig) (net.Conn, error) {
 		ServerName:         cfg.Host,
 		InsecureSkipVerify: cfg.SkipCertVerify,
 		NextProtos:         cfg.NextProtos,
+		MinVersion:         cfg.MinVersion,
+		MaxVersion:         cfg.MaxVersion,
 	}
 
 	if len(cfg.FingerPrint) == 0 {
@@ -1,9 +1,12 @@
 package tunnel
 
 import (
+	"context"
 	"errors"
+	"io"
 	"net"
 	"net/netip"
+	"os"
 	"time"
 
 	N "github.com/Dreamacro/clash/common/net"
@@ -20,6 +23,11 @@ func handleUDPToRemote(packet C.UDPPacket, pc C.PacketConn, metadata *C.Metadata
 	}
 
 	if _, err := pc.WriteTo(packet.Data(), addr); err != nil {
+		if errors.Is(err, C.ErrPortUnreachable) {
+			if u, ok := packet.(C.UDPPacketUnreachable); ok {
+				_ = u.Unreachable()
+			}
+		}
 		return err
 	}
 	// reset timeout
@@ -29,7 +37,7 @@ func handleUDPToRemote(packet C.UDPPacket, pc C.PacketConn, metadata *C.Metadata
 }
 
 func handleUDPToLocal(packet C.UDPPacket, pc net.PacketConn, key string, oAddr, fAddr netip.Addr) {
-	buf := pool.Get(pool.UDPBufferSize)
+	buf := pool.Get(pool.MaxUDPBufferSize)
 	defer func() {
 		_ = pc.Close()
 		natTable.Delete(key)
@@ -62,3 +70,30 @@ func handleUDPToLocal(packet C.UDPPacket, pc net.PacketConn, key string, oAddr,
 func handleSocket(ctx C.ConnContext, outbound net.Conn) {
 	N.Relay(ctx.Conn(), outbound)
 }
+
+// watchClient cancels the dial of conn as soon as its client hangs up, so a
+// dial nobody waits for doesn't keep running. stop must be called before conn
+// is read again
+func watchClient(conn net.Conn, cancel context.CancelFunc) (stop func()) {
+	bufConn, ok := conn.(*N.BufferedConn)
+	// the watcher relies on the read deadline to be stopped
+	if !ok || bufConn.Buffered() > 0 || bufConn.SetReadDeadline(time.Time{}) != nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// a client sending data is still there, EOF may be a half-close of a client still waiting
+		// for the response, only a reset or the inbound closing the conn means it left
+		if _, err := bufConn.Peek(1); err != nil && !errors.Is(err, os.ErrDeadlineExceeded) && !errors.Is(err, io.EOF) {
+			cancel()
+		}
+	}()
+
+	return func() {
+		_ = bufConn.SetReadDeadline(time.Now())
+		<-done
+		_ = bufConn.SetReadDeadline(time.Time{})
+	}
+}
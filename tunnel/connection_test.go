@@ -0,0 +1,92 @@
+package tunnel
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	N "github.com/Dreamacro/clash/common/net"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tcpPair returns both ends of a loopback tcp connection, the server end buffered like the inbounds do
+func tcpPair(t *testing.T) (client *net.TCPConn, server *N.BufferedConn) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	c, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = c.Close()
+		_ = s.Close()
+	})
+	return c.(*net.TCPConn), N.NewBufferedConn(s)
+}
+
+func watch(t *testing.T, hangUp func(client *net.TCPConn, server *N.BufferedConn)) (canceled bool) {
+	client, server := tcpPair(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stop := watchClient(server, cancel)
+	hangUp(client, server)
+
+	select {
+	case <-ctx.Done():
+		canceled = true
+	case <-time.After(200 * time.Millisecond):
+	}
+	stop()
+	return canceled
+}
+
+func TestWatchClient(t *testing.T) {
+	tests := []struct {
+		name   string
+		hangUp func(client *net.TCPConn, server *N.BufferedConn)
+		cancel bool
+	}{
+		{name: "idle", hangUp: func(*net.TCPConn, *N.BufferedConn) {}, cancel: false},
+		{name: "half-close", hangUp: func(c *net.TCPConn, _ *N.BufferedConn) { _ = c.CloseWrite() }, cancel: false},
+		{name: "reset", hangUp: func(c *net.TCPConn, _ *N.BufferedConn) {
+			_ = c.SetLinger(0)
+			_ = c.Close()
+		}, cancel: true},
+		{name: "inbound closed", hangUp: func(_ *net.TCPConn, s *N.BufferedConn) { _ = s.Close() }, cancel: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.cancel, watch(t, tt.hangUp))
+		})
+	}
+}
+
+func TestWatchClient_KeepsData(t *testing.T) {
+	client, server := tcpPair(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stop := watchClient(server, cancel)
+	_, _ = client.Write([]byte("hello"))
+	_ = client.CloseWrite()
+	time.Sleep(50 * time.Millisecond)
+	stop()
+
+	assert.NoError(t, ctx.Err())
+	buf := make([]byte, 5)
+	n, err := server.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(buf[:n]))
+}
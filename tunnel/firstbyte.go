@@ -0,0 +1,111 @@
+package tunnel
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Dreamacro/clash/adapter"
+	C "github.com/Dreamacro/clash/constant"
+	"github.com/Dreamacro/clash/log"
+
+	"go.uber.org/atomic"
+)
+
+// FirstByteTimeout closes a proxied TCP connection when the remote sends
+// nothing back within this duration after the first write, zero disables it
+var FirstByteTimeout = atomic.NewDuration(0)
+
+var errFirstByteTimeout = errors.New("first byte timeout")
+
+// failureReporter is implemented by proxy groups that count failures of
+// connections which were dialed successfully but turned out to be dead
+type failureReporter interface {
+	ReportFailure(err error)
+}
+
+type firstByteConn struct {
+	C.Conn
+	timeout   time.Duration
+	onTimeout func()
+
+	once  sync.Once
+	timer *time.Timer
+	mux   sync.Mutex
+	done  bool
+}
+
+func (c *firstByteConn) Write(b []byte) (int, error) {
+	c.once.Do(func() {
+		c.mux.Lock()
+		defer c.mux.Unlock()
+		if !c.done {
+			c.timer = time.AfterFunc(c.timeout, c.expire)
+		}
+	})
+	return c.Conn.Write(b)
+}
+
+func (c *firstByteConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.stop()
+	}
+	return n, err
+}
+
+func (c *firstByteConn) stop() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.done {
+		return
+	}
+	c.done = true
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+}
+
+func (c *firstByteConn) expire() {
+	c.mux.Lock()
+	if c.done {
+		c.mux.Unlock()
+		return
+	}
+	c.done = true
+	c.mux.Unlock()
+
+	c.onTimeout()
+	_ = c.Conn.Close()
+}
+
+func (c *firstByteConn) Close() error {
+	c.stop()
+	return c.Conn.Close()
+}
+
+func newFirstByteConn(conn C.Conn, timeout time.Duration, metadata *C.Metadata) C.Conn {
+	c := &firstByteConn{Conn: conn, timeout: timeout}
+	c.onTimeout = func() {
+		log.Warnln("[TCP] %s to %s no response in %s", conn.Chains().String(), metadata.RemoteAddress(), timeout)
+		reportFailure(conn.Chains(), errFirstByteTimeout)
+	}
+	return c
+}
+
+// reportFailure notifies every group in the chain so that fallback style
+// groups run a health check and switch away from the dead proxy
+func reportFailure(chains C.Chain, err error) {
+	configMux.RLock()
+	defer configMux.RUnlock()
+
+	for _, name := range chains {
+		p, ok := proxies[name].(*adapter.Proxy)
+		if !ok {
+			continue
+		}
+		if reporter, ok := p.ProxyAdapter.(failureReporter); ok {
+			reporter.ReportFailure(err)
+		}
+	}
+}
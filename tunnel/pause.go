@@ -0,0 +1,75 @@
+package tunnel
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Dreamacro/clash/log"
+)
+
+// PauseState describes a temporary override that sends every new
+// connection to DIRECT or REJECT, ignoring mode and rules
+type PauseState struct {
+	Proxy string     `json:"proxy"`
+	Until *time.Time `json:"until,omitempty"`
+}
+
+var (
+	pauseMux   sync.Mutex
+	pauseState *PauseState
+	pauseTimer *time.Timer
+)
+
+// Pause overrides routing with proxy, zero duration lasts until Resume
+func Pause(proxy string, duration time.Duration) {
+	pauseMux.Lock()
+	defer pauseMux.Unlock()
+
+	if pauseTimer != nil {
+		pauseTimer.Stop()
+		pauseTimer = nil
+	}
+
+	state := &PauseState{Proxy: proxy}
+	if duration > 0 {
+		until := time.Now().Add(duration)
+		state.Until = &until
+		pauseTimer = time.AfterFunc(duration, func() { expire(state) })
+	}
+	pauseState = state
+	log.Warnln("[Tunnel] paused, all connections use %s", proxy)
+}
+
+// Resume removes the override set by Pause
+func Resume() {
+	pauseMux.Lock()
+	defer pauseMux.Unlock()
+
+	if pauseTimer != nil {
+		pauseTimer.Stop()
+		pauseTimer = nil
+	}
+	if pauseState != nil {
+		pauseState = nil
+		log.Warnln("[Tunnel] resumed")
+	}
+}
+
+// expire ends the pause state was set by, a timer firing after a later Pause or Resume leaves it alone
+func expire(state *PauseState) {
+	pauseMux.Lock()
+	defer pauseMux.Unlock()
+
+	if pauseState == state {
+		pauseState = nil
+		pauseTimer = nil
+		log.Warnln("[Tunnel] resumed")
+	}
+}
+
+// Paused returns the current override, nil if not paused
+func Paused() *PauseState {
+	pauseMux.Lock()
+	defer pauseMux.Unlock()
+	return pauseState
+}
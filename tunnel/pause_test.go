@@ -0,0 +1,57 @@
+package tunnel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPause_UntilResume(t *testing.T) {
+	defer Resume()
+
+	Pause("DIRECT", 0)
+	state := Paused()
+	if assert.NotNil(t, state) {
+		assert.Equal(t, "DIRECT", state.Proxy)
+		assert.Nil(t, state.Until)
+	}
+
+	Resume()
+	assert.Nil(t, Paused())
+}
+
+func TestPause_Expires(t *testing.T) {
+	defer Resume()
+
+	Pause("REJECT", 20*time.Millisecond)
+	state := Paused()
+	if assert.NotNil(t, state) {
+		assert.Equal(t, "REJECT", state.Proxy)
+		assert.NotNil(t, state.Until)
+	}
+
+	assert.Eventually(t, func() bool { return Paused() == nil }, time.Second, 5*time.Millisecond)
+}
+
+func TestPause_StaleTimerKeepsNewPause(t *testing.T) {
+	defer Resume()
+
+	Pause("REJECT", 10*time.Millisecond)
+	stale := Paused()
+	Pause("DIRECT", 0)
+
+	// the first pause's timer may already be running when it is replaced
+	expire(stale)
+	time.Sleep(30 * time.Millisecond)
+
+	state := Paused()
+	if assert.NotNil(t, state) {
+		assert.Equal(t, "DIRECT", state.Proxy)
+	}
+}
+
+func TestResume_NotPaused(t *testing.T) {
+	Resume()
+	assert.Nil(t, Paused())
+}
@@ -0,0 +1,139 @@
+package tunnel
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/Dreamacro/clash/adapter"
+	"github.com/Dreamacro/clash/component/dialer"
+	C "github.com/Dreamacro/clash/constant"
+	"github.com/Dreamacro/clash/log"
+
+	"go.uber.org/atomic"
+)
+
+const (
+	RetryNext    = "next"
+	RetryRandom  = "random"
+	RetryFastest = "fastest"
+)
+
+var (
+	// DialRetry is how many other members of the matched group a failed TCP dial is retried with, zero disables it
+	DialRetry = atomic.NewInt32(0)
+	// RetryStrategy picks the member of the next attempt, see ParseRetryStrategy
+	RetryStrategy = atomic.NewString(RetryNext)
+)
+
+// ParseRetryStrategy checks a dial-retry-strategy, empty means next
+func ParseRetryStrategy(strategy string) (string, error) {
+	switch strategy {
+	case "":
+		return RetryNext, nil
+	case RetryNext, RetryRandom, RetryFastest:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unsupported dial retry strategy: %s", strategy)
+	}
+}
+
+// retryDial dials metadata through the siblings of the member of proxy that just failed,
+// it returns err as is when proxy isn't a select, fallback, url-test or load-balance group or retry is disabled
+func retryDial(parent context.Context, proxy C.Proxy, metadata *C.Metadata, err error, opts ...dialer.Option) (C.Conn, error) {
+	retry := int(DialRetry.Load())
+	if retry <= 0 {
+		return nil, err
+	}
+	p, ok := proxy.(*adapter.Proxy)
+	if !ok {
+		return nil, err
+	}
+	group, ok := p.ProxyAdapter.(C.Group)
+	if !ok {
+		return nil, err
+	}
+	// the members of a relay are hops of one chain, dialing one of them alone would skip the rest
+	switch p.Type() {
+	case C.Selector, C.Fallback, C.URLTest, C.LoadBalance:
+	default:
+		return nil, err
+	}
+
+	members := group.GetProxies(false)
+	tried := map[string]bool{}
+	last := proxy.Unwrap(metadata, false)
+	if last != nil {
+		tried[last.Name()] = true
+	}
+
+	strategy := RetryStrategy.Load()
+	for i := 0; i < retry; i++ {
+		member := pickMember(members, tried, last, strategy)
+		if member == nil {
+			break
+		}
+		tried[member.Name()] = true
+		last = member
+
+		log.Debugln("[TCP] retry %s to %s with %s", proxy.Name(), metadata.RemoteAddress(), member.Name())
+		ctx, cancel := context.WithTimeout(parent, C.DefaultTCPTimeout)
+		c, dialErr := member.DialContext(ctx, metadata, opts...)
+		cancel()
+		if dialErr == nil {
+			c.AppendToChains(proxy)
+			return c, nil
+		}
+		err = dialErr
+		if parent.Err() != nil {
+			break
+		}
+	}
+	return nil, err
+}
+
+// pickMember returns the next member to try, preferring alive ones, or nil when every member was tried
+func pickMember(members []C.Proxy, tried map[string]bool, last C.Proxy, strategy string) C.Proxy {
+	var alive, rest []C.Proxy
+	start := 0
+	for i, m := range members {
+		if last != nil && m.Name() == last.Name() {
+			start = i + 1
+		}
+	}
+	// keep the group order starting after the last attempt for next
+	for i := range members {
+		m := members[(start+i)%len(members)]
+		if tried[m.Name()] {
+			continue
+		}
+		if m.Alive() {
+			alive = append(alive, m)
+		} else {
+			rest = append(rest, m)
+		}
+	}
+
+	candidates := alive
+	if len(candidates) == 0 {
+		candidates = rest
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	switch strategy {
+	case RetryRandom:
+		return candidates[rand.Intn(len(candidates))]
+	case RetryFastest:
+		fastest := candidates[0]
+		for _, m := range candidates[1:] {
+			if m.LastDelay() < fastest.LastDelay() {
+				fastest = m
+			}
+		}
+		return fastest
+	default:
+		return candidates[0]
+	}
+}
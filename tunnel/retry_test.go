@@ -0,0 +1,78 @@
+package tunnel
+
+import (
+	"testing"
+
+	C "github.com/Dreamacro/clash/constant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testMember struct {
+	C.Proxy
+	name  string
+	alive bool
+	delay uint16
+}
+
+func (m *testMember) Name() string      { return m.name }
+func (m *testMember) Alive() bool       { return m.alive }
+func (m *testMember) LastDelay() uint16 { return m.delay }
+
+func TestPickMember(t *testing.T) {
+	a := &testMember{name: "a", alive: true, delay: 300}
+	b := &testMember{name: "b", alive: false, delay: 0xffff}
+	c := &testMember{name: "c", alive: true, delay: 100}
+	d := &testMember{name: "d", alive: true, delay: 200}
+	members := []C.Proxy{a, b, c, d}
+
+	tests := []struct {
+		name     string
+		tried    []string
+		last     C.Proxy
+		strategy string
+		want     string
+	}{
+		{name: "next from the start", strategy: RetryNext, want: "a"},
+		{name: "next after last", tried: []string{"a"}, last: a, strategy: RetryNext, want: "c"},
+		{name: "next wraps around", tried: []string{"d"}, last: d, strategy: RetryNext, want: "a"},
+		{name: "next skips tried", tried: []string{"a", "c"}, last: c, strategy: RetryNext, want: "d"},
+		{name: "dead only when nothing alive is left", tried: []string{"a", "c", "d"}, last: d, strategy: RetryNext, want: "b"},
+		{name: "all tried", tried: []string{"a", "b", "c", "d"}, last: d, strategy: RetryNext, want: ""},
+		{name: "fastest", strategy: RetryFastest, want: "c"},
+		{name: "fastest untried", tried: []string{"c"}, last: c, strategy: RetryFastest, want: "d"},
+		{name: "unknown strategy is next", strategy: "", want: "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tried := map[string]bool{}
+			for _, name := range tt.tried {
+				tried[name] = true
+			}
+
+			got := pickMember(members, tried, tt.last, tt.strategy)
+			if tt.want == "" {
+				assert.Nil(t, got)
+				return
+			}
+			if assert.NotNil(t, got) {
+				assert.Equal(t, tt.want, got.Name())
+			}
+		})
+	}
+}
+
+func TestPickMember_Random(t *testing.T) {
+	a := &testMember{name: "a", alive: true}
+	b := &testMember{name: "b", alive: false}
+	c := &testMember{name: "c", alive: true}
+	members := []C.Proxy{a, b, c}
+
+	for i := 0; i < 100; i++ {
+		got := pickMember(members, map[string]bool{"a": true}, a, RetryRandom)
+		// c is the only alive member left
+		assert.Equal(t, "c", got.Name())
+	}
+	assert.Nil(t, pickMember(nil, map[string]bool{}, nil, RetryRandom))
+}
@@ -1,9 +1,13 @@
 package statistic
 
 import (
+	"net/netip"
+	"sort"
 	"sync"
 	"time"
 
+	C "github.com/Dreamacro/clash/constant"
+
 	"go.uber.org/atomic"
 )
 
@@ -17,6 +21,8 @@ func init() {
 		downloadBlip:  atomic.NewInt64(0),
 		uploadTotal:   atomic.NewInt64(0),
 		downloadTotal: atomic.NewInt64(0),
+		sources:       map[netip.Addr]*SourceStatistic{},
+		destinations:  map[string]int64{},
 	}
 
 	go DefaultManager.handle()
@@ -24,6 +30,10 @@ func init() {
 
 type Manager struct {
 	connections   sync.Map
+	sources       map[netip.Addr]*SourceStatistic
+	sourceMux     sync.Mutex
+	destinations  map[string]int64
+	destMux       sync.Mutex
 	uploadTemp    *atomic.Int64
 	downloadTemp  *atomic.Int64
 	uploadBlip    *atomic.Int64
@@ -36,8 +46,10 @@ func (m *Manager) Join(c tracker) {
 	m.connections.Store(c.ID(), c)
 }
 
-func (m *Manager) Leave(c tracker) {
-	m.connections.Delete(c.ID())
+// Leave reports whether c was still tracked, so closing twice is only counted once
+func (m *Manager) Leave(c tracker) bool {
+	_, loaded := m.connections.LoadAndDelete(c.ID())
+	return loaded
 }
 
 func (m *Manager) PushUploaded(size int64) {
@@ -68,7 +80,87 @@ func (m *Manager) Snapshot() *Snapshot {
 	}
 }
 
+// joinSource counts a new connection of a source ip, creating its traffic counters on first use
+func (m *Manager) joinSource(ip netip.Addr) *SourceStatistic {
+	ip = ip.Unmap()
+
+	m.sourceMux.Lock()
+	defer m.sourceMux.Unlock()
+	s, ok := m.sources[ip]
+	if !ok {
+		s = &SourceStatistic{
+			IP:            ip,
+			UploadTotal:   atomic.NewInt64(0),
+			DownloadTotal: atomic.NewInt64(0),
+			Connections:   atomic.NewInt64(0),
+		}
+		m.sources[ip] = s
+	}
+	s.Connections.Inc()
+	return s
+}
+
+// leaveSource drops the counters of a source ip with its last connection, so the map only holds active sources
+func (m *Manager) leaveSource(s *SourceStatistic) {
+	m.sourceMux.Lock()
+	defer m.sourceMux.Unlock()
+	if s.Connections.Dec() <= 0 && m.sources[s.IP] == s {
+		delete(m.sources, s.IP)
+	}
+}
+
+// destinationKey is the host of the connection, or its ip when there is no host
+func destinationKey(metadata *C.Metadata) string {
+	if metadata.Host != "" {
+		return metadata.Host
+	}
+	return metadata.DstIP.Unmap().String()
+}
+
+// DestinationConnections returns the number of active connections to the destination of metadata
+func (m *Manager) DestinationConnections(metadata *C.Metadata) int64 {
+	m.destMux.Lock()
+	defer m.destMux.Unlock()
+	return m.destinations[destinationKey(metadata)]
+}
+
+func (m *Manager) joinDestination(dst string) {
+	m.destMux.Lock()
+	m.destinations[dst]++
+	m.destMux.Unlock()
+}
+
+func (m *Manager) leaveDestination(dst string) {
+	m.destMux.Lock()
+	if m.destinations[dst]--; m.destinations[dst] <= 0 {
+		delete(m.destinations, dst)
+	}
+	m.destMux.Unlock()
+}
+
+// Sources lists the traffic of every source ip with active connections, the busiest first
+func (m *Manager) Sources() []*SourceStatistic {
+	m.sourceMux.Lock()
+	sources := make([]*SourceStatistic, 0, len(m.sources))
+	for _, s := range m.sources {
+		sources = append(sources, s)
+	}
+	m.sourceMux.Unlock()
+
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].total() > sources[j].total()
+	})
+	return sources
+}
+
 func (m *Manager) ResetStatistic() {
+	m.sourceMux.Lock()
+	for _, s := range m.sources {
+		s.UploadTotal.Store(0)
+		s.DownloadTotal.Store(0)
+	}
+	m.sourceMux.Unlock()
+
 	m.uploadTemp.Store(0)
 	m.uploadBlip.Store(0)
 	m.uploadTotal.Store(0)
@@ -88,6 +180,18 @@ func (m *Manager) handle() {
 	}
 }
 
+// SourceStatistic is the traffic of one source ip, Connections counts the active ones
+type SourceStatistic struct {
+	IP            netip.Addr    `json:"sourceIP"`
+	UploadTotal   *atomic.Int64 `json:"upload"`
+	DownloadTotal *atomic.Int64 `json:"download"`
+	Connections   *atomic.Int64 `json:"connections"`
+}
+
+func (s *SourceStatistic) total() int64 {
+	return s.UploadTotal.Load() + s.DownloadTotal.Load()
+}
+
 type Snapshot struct {
 	DownloadTotal int64     `json:"downloadTotal"`
 	UploadTotal   int64     `json:"uploadTotal"`
@@ -2,8 +2,11 @@ package statistic
 
 import (
 	"net"
+	"strings"
 	"time"
 
+	"github.com/Dreamacro/clash/component/mmdb"
+	"github.com/Dreamacro/clash/component/resolver"
 	C "github.com/Dreamacro/clash/constant"
 
 	"github.com/gofrs/uuid"
@@ -24,6 +27,12 @@ type trackerInfo struct {
 	Chain         C.Chain       `json:"chains"`
 	Rule          string        `json:"rule"`
 	RulePayload   string        `json:"rulePayload"`
+	RuleProvider  string        `json:"ruleProvider,omitempty"`
+	// DestinationCountry is the ISO country code of DstIP in the MaxMind database, empty when unknown
+	DestinationCountry string `json:"destinationCountry,omitempty"`
+
+	source      *SourceStatistic
+	destination string
 }
 
 type tcpTracker struct {
@@ -41,6 +50,7 @@ func (tt *tcpTracker) Read(b []byte) (int, error) {
 	download := int64(n)
 	tt.manager.PushDownloaded(download)
 	tt.DownloadTotal.Add(download)
+	tt.source.DownloadTotal.Add(download)
 	return n, err
 }
 
@@ -49,11 +59,15 @@ func (tt *tcpTracker) Write(b []byte) (int, error) {
 	upload := int64(n)
 	tt.manager.PushUploaded(upload)
 	tt.UploadTotal.Add(upload)
+	tt.source.UploadTotal.Add(upload)
 	return n, err
 }
 
 func (tt *tcpTracker) Close() error {
-	tt.manager.Leave(tt)
+	if tt.manager.Leave(tt) {
+		tt.manager.leaveSource(tt.source)
+		tt.manager.leaveDestination(tt.destination)
+	}
 	return tt.Conn.Close()
 }
 
@@ -78,14 +92,19 @@ func NewTCPTracker(conn C.Conn, manager *Manager, metadata *C.Metadata, rule C.R
 			Rule:          "",
 			UploadTotal:   atomic.NewInt64(0),
 			DownloadTotal: atomic.NewInt64(0),
+			source:        manager.joinSource(metadata.SrcIP),
+			destination:   destinationKey(metadata),
 		},
 	}
 
 	if rule != nil {
 		t.trackerInfo.Rule = rule.RuleType().String()
 		t.trackerInfo.RulePayload = rule.Payload()
+		t.trackerInfo.RuleProvider = metadata.RuleProvider
 	}
+	t.DestinationCountry = destinationCountry(metadata)
 
+	manager.joinDestination(t.destination)
 	manager.Join(t)
 	return t
 }
@@ -105,6 +124,7 @@ func (ut *udpTracker) ReadFrom(b []byte) (int, net.Addr, error) {
 	download := int64(n)
 	ut.manager.PushDownloaded(download)
 	ut.DownloadTotal.Add(download)
+	ut.source.DownloadTotal.Add(download)
 	return n, addr, err
 }
 
@@ -113,11 +133,15 @@ func (ut *udpTracker) WriteTo(b []byte, addr net.Addr) (int, error) {
 	upload := int64(n)
 	ut.manager.PushUploaded(upload)
 	ut.UploadTotal.Add(upload)
+	ut.source.UploadTotal.Add(upload)
 	return n, err
 }
 
 func (ut *udpTracker) Close() error {
-	ut.manager.Leave(ut)
+	if ut.manager.Leave(ut) {
+		ut.manager.leaveSource(ut.source)
+		ut.manager.leaveDestination(ut.destination)
+	}
 	return ut.PacketConn.Close()
 }
 
@@ -136,14 +160,31 @@ func NewUDPTracker(conn C.PacketConn, manager *Manager, metadata *C.Metadata, ru
 			Rule:          "",
 			UploadTotal:   atomic.NewInt64(0),
 			DownloadTotal: atomic.NewInt64(0),
+			source:        manager.joinSource(metadata.SrcIP),
+			destination:   destinationKey(metadata),
 		},
 	}
 
 	if rule != nil {
 		ut.trackerInfo.Rule = rule.RuleType().String()
 		ut.trackerInfo.RulePayload = rule.Payload()
+		ut.trackerInfo.RuleProvider = metadata.RuleProvider
 	}
+	ut.DestinationCountry = destinationCountry(metadata)
 
+	manager.joinDestination(ut.destination)
 	manager.Join(ut)
 	return ut
 }
+
+// destinationCountry looks DstIP up in the MaxMind database like the GEOIP rule does, skipping local addresses
+func destinationCountry(metadata *C.Metadata) string {
+	ip := metadata.DstIP
+	if !ip.IsValid() || C.GeodataMode || ip.IsPrivate() || ip.IsLoopback() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsMulticast() || resolver.IsFakeIP(ip) {
+		return ""
+	}
+
+	record, _ := mmdb.Instance().Country(ip.AsSlice())
+	return strings.ToUpper(record.Country.IsoCode)
+}
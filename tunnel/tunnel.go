@@ -2,8 +2,10 @@ package tunnel
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	P "github.com/Dreamacro/clash/component/process"
+	"math/rand"
 	"net"
 	"net/netip"
 	"path/filepath"
@@ -13,6 +15,9 @@ import (
 	"time"
 
 	"github.com/Dreamacro/clash/adapter/inbound"
+	"github.com/Dreamacro/clash/common/observable"
+	"github.com/Dreamacro/clash/component/dialer"
+	"github.com/Dreamacro/clash/component/loopback"
 	"github.com/Dreamacro/clash/component/nat"
 	"github.com/Dreamacro/clash/component/resolver"
 	"github.com/Dreamacro/clash/component/sniffer"
@@ -20,6 +25,7 @@ import (
 	"github.com/Dreamacro/clash/constant/provider"
 	icontext "github.com/Dreamacro/clash/context"
 	"github.com/Dreamacro/clash/log"
+	RC "github.com/Dreamacro/clash/rules/common"
 	"github.com/Dreamacro/clash/tunnel/statistic"
 )
 
@@ -29,13 +35,16 @@ var (
 	natTable       = nat.New()
 	rules          []C.Rule
 	proxies        = make(map[string]C.Proxy)
+	chains         = make(map[string]C.Proxy)
 	providers      map[string]provider.ProxyProvider
 	ruleProviders  map[string]provider.RuleProvider
 	sniffingEnable bool
 	configMux      sync.RWMutex
 
 	// Outbound Rule
-	mode = Rule
+	mode       = Rule
+	modeCh     = make(chan TunnelMode, 1)
+	modeSource = observable.NewObservable[TunnelMode](modeCh)
 
 	// default timeout for UDP session
 	udpTimeout = 60 * time.Second
@@ -56,6 +65,7 @@ func IsSniffing() bool {
 }
 
 func init() {
+	RC.SetConnectionCounter(statistic.DefaultManager)
 	go process()
 }
 
@@ -97,10 +107,11 @@ func RuleProviders() map[string]provider.RuleProvider {
 	return ruleProviders
 }
 
-// UpdateProxies handle update proxies
-func UpdateProxies(newProxies map[string]C.Proxy, newProviders map[string]provider.ProxyProvider) {
+// UpdateProxies handle update proxies, chains are the inline relays only rules can target
+func UpdateProxies(newProxies map[string]C.Proxy, newChains map[string]C.Proxy, newProviders map[string]provider.ProxyProvider) {
 	configMux.Lock()
 	proxies = newProxies
+	chains = newChains
 	providers = newProviders
 	configMux.Unlock()
 }
@@ -117,9 +128,39 @@ func Mode() TunnelMode {
 	return mode
 }
 
-// SetMode change the mode of tunnel
+// SetMode change the mode of tunnel, subscribers of SubscribeMode are told when it differs
 func SetMode(m TunnelMode) {
+	changed := mode != m
 	mode = m
+	if changed {
+		emitMode(m)
+	}
+}
+
+// emitMode hands m to the subscribers without waiting on them, a change they haven't
+// taken yet is replaced by m since only the latest mode matters
+func emitMode(m TunnelMode) {
+	for {
+		select {
+		case modeCh <- m:
+			return
+		default:
+		}
+		select {
+		case <-modeCh:
+		default:
+		}
+	}
+}
+
+// SubscribeMode receives every change of the mode
+func SubscribeMode() observable.Subscription[TunnelMode] {
+	sub, _ := modeSource.Subscribe()
+	return sub
+}
+
+func UnSubscribeMode(sub observable.Subscription[TunnelMode]) {
+	modeSource.UnSubscribe(sub)
 }
 
 // SetAlwaysFindProcess set always find process info, may be increase many memory
@@ -154,7 +195,22 @@ func needLookupIP(metadata *C.Metadata) bool {
 	return resolver.MappingEnabled() && metadata.Host == "" && metadata.DstIP.IsValid()
 }
 
+// checkLoopback refuses a connection that clash dialed itself and that came
+// back to one of its listeners
+func checkLoopback(metadata *C.Metadata) error {
+	port, err := strconv.ParseUint(metadata.SrcPort, 10, 16)
+	if err != nil {
+		return nil
+	}
+	return loopback.Check(metadata.NetWork.String(), netip.AddrPortFrom(metadata.SrcIP, uint16(port)))
+}
+
 func preHandleMetadata(metadata *C.Metadata) error {
+	// follow hosts aliases, so rules and the dial see the target domain
+	if metadata.Host != "" {
+		metadata.Host = resolver.HostAlias(metadata.Host)
+	}
+
 	// handle IP string on host
 	if ip, err := netip.ParseAddr(metadata.Host); err == nil {
 		metadata.DstIP = ip
@@ -189,6 +245,20 @@ func preHandleMetadata(metadata *C.Metadata) error {
 }
 
 func resolveMetadata(_ C.PlainContext, metadata *C.Metadata) (proxy C.Proxy, rule C.Rule, err error) {
+	if state := Paused(); state != nil {
+		proxy = proxies[state.Proxy]
+		return
+	}
+
+	if metadata.SpecialProxy != "" {
+		var exist bool
+		proxy, exist = proxies[metadata.SpecialProxy]
+		if !exist {
+			err = fmt.Errorf("proxy %s not found", metadata.SpecialProxy)
+		}
+		return
+	}
+
 	switch mode {
 	case Direct:
 		proxy = proxies["DIRECT"]
@@ -208,6 +278,11 @@ func handleUDPConn(packet *inbound.PacketAdapter) {
 		return
 	}
 
+	if err := checkLoopback(metadata); err != nil {
+		log.Warnln("[UDP] %s --> %s: %s", metadata.SourceAddress(), metadata.RemoteAddress(), err)
+		return
+	}
+
 	// make a fAddr if request ip is fakeip
 	var fAddr netip.Addr
 	if resolver.IsExistFakeIP(metadata.DstIP) {
@@ -269,7 +344,7 @@ func handleUDPConn(packet *inbound.PacketAdapter) {
 
 		ctx, cancel := context.WithTimeout(context.Background(), C.DefaultUDPTimeout)
 		defer cancel()
-		rawPc, err := proxy.ListenPacketContext(ctx, metadata.Pure())
+		rawPc, err := proxy.ListenPacketContext(ctx, metadata.Pure(), dscpOptions(rule, metadata)...)
 		if err != nil {
 			if rule == nil {
 				log.Warnln("[UDP] dial %s to %s error: %s", proxy.Name(), metadata.RemoteAddress(), err.Error())
@@ -289,6 +364,8 @@ func handleUDPConn(packet *inbound.PacketAdapter) {
 			} else {
 				log.Infoln("[UDP] %s --> %s match %s using %s", metadata.SourceDetail(), metadata.RemoteAddress(), rule.Payload(), rawPc.Chains().String())
 			}
+		case Paused() != nil:
+			log.Infoln("[UDP] %s --> %s using %s (paused)", metadata.SourceDetail(), metadata.RemoteAddress(), rawPc.Chains().String())
 		case mode == Global:
 			log.Infoln("[UDP] %s --> %s using GLOBAL", metadata.SourceDetail(), metadata.RemoteAddress())
 		case mode == Direct:
@@ -316,6 +393,11 @@ func handleTCPConn(connCtx C.ConnContext) {
 		return
 	}
 
+	if err := checkLoopback(metadata); err != nil {
+		log.Warnln("[TCP] %s --> %s: %s", metadata.SourceAddress(), metadata.RemoteAddress(), err)
+		return
+	}
+
 	if err := preHandleMetadata(metadata); err != nil {
 		log.Debugln("[Metadata PreHandle] error: %s", err)
 		return
@@ -340,9 +422,27 @@ func handleTCPConn(connCtx C.ConnContext) {
 		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), C.DefaultTCPTimeout)
+	clientCtx, cancelClient := context.WithCancel(context.Background())
+	defer cancelClient()
+	stopWatch := watchClient(connCtx.Conn(), cancelClient)
+
+	ctx, cancel := context.WithTimeout(clientCtx, C.DefaultTCPTimeout)
 	defer cancel()
-	remoteConn, err := proxy.DialContext(ctx, dialMetadata)
+	opts := dscpOptions(rule, metadata)
+	remoteConn, err := proxy.DialContext(ctx, dialMetadata, opts...)
+	if err != nil && clientCtx.Err() == nil {
+		remoteConn, err = retryDial(clientCtx, proxy, dialMetadata, err, opts...)
+	}
+	stopWatch()
+	if err == nil && clientCtx.Err() != nil {
+		// the client left while the dial was finishing
+		_ = remoteConn.Close()
+		err = clientCtx.Err()
+	}
+	if errors.Is(err, context.Canceled) && clientCtx.Err() != nil {
+		log.Debugln("[TCP] %s --> %s dial abandoned, the client closed the connection", metadata.SourceDetail(), metadata.RemoteAddress())
+		return
+	}
 	if err != nil {
 		if rule == nil {
 			log.Warnln("[TCP] dial %s to %s error: %s", proxy.Name(), metadata.RemoteAddress(), err.Error())
@@ -352,6 +452,10 @@ func handleTCPConn(connCtx C.ConnContext) {
 		return
 	}
 
+	if timeout := FirstByteTimeout.Load(); timeout > 0 {
+		remoteConn = newFirstByteConn(remoteConn, timeout, metadata)
+	}
+
 	remoteConn = statistic.NewTCPTracker(remoteConn, statistic.DefaultManager, metadata, rule)
 	defer func(remoteConn C.Conn) {
 		_ = remoteConn.Close()
@@ -364,6 +468,10 @@ func handleTCPConn(connCtx C.ConnContext) {
 		} else {
 			log.Infoln("[TCP] %s --> %s match %s using %s", metadata.SourceDetail(), metadata.RemoteAddress(), rule.RuleType().String(), remoteConn.Chains().String())
 		}
+	case Paused() != nil:
+		log.Infoln("[TCP] %s --> %s using %s (paused)", metadata.SourceDetail(), metadata.RemoteAddress(), remoteConn.Chains().String())
+	case metadata.SpecialProxy != "":
+		log.Infoln("[TCP] %s --> %s using %s", metadata.SourceDetail(), metadata.RemoteAddress(), remoteConn.Chains().String())
 	case mode == Global:
 		log.Infoln("[TCP] %s --> %s using GLOBAL", metadata.SourceDetail(), metadata.RemoteAddress())
 	case mode == Direct:
@@ -375,10 +483,35 @@ func handleTCPConn(connCtx C.ConnContext) {
 	handleSocket(connCtx, remoteConn)
 }
 
+// dscpOptions marks the egress with the dscp param of the matched rule
+func dscpOptions(rule C.Rule, metadata *C.Metadata) []dialer.Option {
+	marker, ok := rule.(C.DSCPMarker)
+	if !ok {
+		return nil
+	}
+	dscp, ok := marker.EgressDSCP(metadata)
+	if !ok {
+		return nil
+	}
+	return []dialer.Option{dialer.WithDSCP(int(dscp))}
+}
+
 func shouldResolveIP(rule C.Rule, metadata *C.Metadata) bool {
 	return rule.ShouldResolveIP() && metadata.Host != "" && !metadata.DstIP.IsValid()
 }
 
+// resolveAllIP resolves host like resolver.ResolveIP does, the IPv4 addresses or else the IPv6 ones
+func resolveAllIP(host string) ([]netip.Addr, error) {
+	ips, err := resolver.ResolveAllIPv4(host)
+	if err != nil || len(ips) == 0 {
+		ips, err = resolver.ResolveAllIPv6(host)
+	}
+	if err == nil && len(ips) == 0 {
+		err = resolver.ErrIPNotFound
+	}
+	return ips, err
+}
+
 func match(metadata *C.Metadata) (C.Proxy, C.Rule, error) {
 	configMux.RLock()
 	defer configMux.RUnlock()
@@ -394,12 +527,13 @@ func match(metadata *C.Metadata) (C.Proxy, C.Rule, error) {
 
 	for _, rule := range rules {
 		if !resolved && shouldResolveIP(rule, metadata) {
-			ip, err := resolver.ResolveIP(metadata.Host)
+			ips, err := resolveAllIP(metadata.Host)
 			if err != nil {
 				log.Debugln("[DNS] resolve %s error: %s", metadata.Host, err.Error())
 			} else {
-				log.Debugln("[DNS] %s --> %s", metadata.Host, ip.String())
-				metadata.DstIP = ip
+				log.Debugln("[DNS] %s --> %v", metadata.Host, ips)
+				metadata.ResolvedIPs = ips
+				metadata.DstIP = ips[rand.Intn(len(ips))]
 			}
 			resolved = true
 		}
@@ -419,10 +553,14 @@ func match(metadata *C.Metadata) (C.Proxy, C.Rule, error) {
 			}
 		}
 
+		// a RULE-SET nested in a logic rule may match while the rule itself doesn't
+		metadata.RuleProvider = ""
 		if matched, ada := rule.Match(metadata); matched {
 			adapter, ok := proxies[ada]
 			if !ok {
-				continue
+				if adapter, ok = chains[ada]; !ok {
+					continue
+				}
 			}
 
 			// parse multi-layer nesting
@@ -0,0 +1,70 @@
+package tunnel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetMode_Subscribers(t *testing.T) {
+	SetMode(Rule)
+	defer SetMode(Rule)
+
+	sub := SubscribeMode()
+	defer UnSubscribeMode(sub)
+
+	SetMode(Global)
+	select {
+	case m := <-sub:
+		assert.Equal(t, Global, m)
+	case <-time.After(time.Second):
+		t.Fatal("the change wasn't emitted")
+	}
+
+	// setting the same mode again is not a change
+	SetMode(Global)
+	select {
+	case m := <-sub:
+		t.Fatalf("unexpected change to %s", m)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSetMode_SlowSubscriber(t *testing.T) {
+	SetMode(Rule)
+	defer SetMode(Rule)
+
+	// a subscriber not reading fills its buffer and stalls the observable
+	sub := SubscribeMode()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			if i%2 == 0 {
+				SetMode(Global)
+			} else {
+				SetMode(Direct)
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SetMode blocked on a slow subscriber")
+	}
+	assert.Equal(t, Direct, Mode())
+
+	// the latest mode still reaches the subscriber once it reads again
+	var last TunnelMode
+	for drained := false; !drained; {
+		select {
+		case last = <-sub:
+		case <-time.After(200 * time.Millisecond):
+			drained = true
+		}
+	}
+	assert.Equal(t, Direct, last)
+	UnSubscribeMode(sub)
+}